@@ -2,12 +2,14 @@ package main
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/claudio/todo-api/internal/logger"
+	"github.com/claudio/todo-api/internal/middleware"
 	"github.com/claudio/todo-api/internal/router"
 	"github.com/gorilla/mux"
 )
@@ -23,7 +25,7 @@ func main() {
 	logger.InfoLogger.Println("Utilizando almacenamiento en memoria para desarrollo...")
 	
 	// Inicializar el router sin base de datos (usará almacenamiento en memoria)
-	r := router.NewRouter(nil)
+	r, grpcServer := router.NewServers(nil)
 	logger.InfoLogger.Println("Router inicializado correctamente")
 	
 	// Agregar registro de rutas para depuración
@@ -50,13 +52,17 @@ func main() {
 			logger.InfoLogger.Printf("Headers: %v", r.Header)
 			
 			// Configurar encabezados CORS para todas las respuestas
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+			if origin := middleware.AllowOrigin(r); origin != "" {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			w.Header().Add("Vary", "Origin")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PROPFIND, REPORT")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, Idempotency-Key, Last-Event-ID")
 			w.Header().Set("Access-Control-Max-Age", "3600")
 			
-			// Manejar solicitudes preflight OPTIONS
-			if r.Method == "OPTIONS" {
+			// Manejar solicitudes preflight; las demás OPTIONS, como el
+			// descubrimiento de CalDAV, llegan al router
+			if r.Method == "OPTIONS" && r.Header.Get("Origin") != "" && r.Header.Get("Access-Control-Request-Method") != "" {
 				w.WriteHeader(http.StatusOK)
 				logger.InfoLogger.Printf("Respondiendo a solicitud OPTIONS con 200 OK")
 				return
@@ -77,6 +83,23 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// El servicio gRPC escucha en un puerto aparte y comparte los almacenes
+	// con la API REST
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "3001"
+	}
+	listener, err := net.Listen("tcp", ":"+grpcPort)
+	if err != nil {
+		logger.ErrorLogger.Fatalf("Error al abrir el puerto gRPC: %v", err)
+	}
+	go func() {
+		logger.InfoLogger.Printf("Servidor gRPC iniciado en el puerto %s", grpcPort)
+		if err := grpcServer.Serve(listener); err != nil {
+			logger.ErrorLogger.Fatalf("Error al iniciar el servidor gRPC: %v", err)
+		}
+	}()
+
 	// Iniciar el servidor
 	logger.InfoLogger.Printf("Servidor iniciado en el puerto %s", port)
 	if err := server.ListenAndServe(); err != nil {
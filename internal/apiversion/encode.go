@@ -0,0 +1,84 @@
+package apiversion
+
+import (
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// Las respuestas que contienen tareas dentro de otros modelos se envuelven
+// en un struct que incrusta el modelo y redefine su campo task: encoding/json
+// usa el campo menos anidado, así que el resto de los campos no cambia y la
+// tarea sale en la representación de la versión.
+
+// EncodeTaskPtr convierte una tarea opcional; nil se mantiene como nil
+func EncodeTaskPtr(codec TaskCodec, task *models.Task) interface{} {
+	if task == nil {
+		return nil
+	}
+	return codec.EncodeTask(*task)
+}
+
+// EncodeEvent convierte la tarea de un evento
+func EncodeEvent(codec TaskCodec, event models.TaskEvent) interface{} {
+	return struct {
+		models.TaskEvent
+		Task interface{} `json:"task,omitempty"`
+	}{event, EncodeTaskPtr(codec, event.Task)}
+}
+
+// EncodeRevisions convierte la tarea de cada revisión del historial
+func EncodeRevisions(codec TaskCodec, revisions []models.TaskRevision) []interface{} {
+	encoded := make([]interface{}, len(revisions))
+	for i, revision := range revisions {
+		encoded[i] = struct {
+			models.TaskRevision
+			Task interface{} `json:"task"`
+		}{revision, codec.EncodeTask(revision.Task)}
+	}
+	return encoded
+}
+
+// EncodeSearchHits convierte la tarea de cada resultado de búsqueda
+func EncodeSearchHits(codec TaskCodec, hits []models.SearchHit) []interface{} {
+	encoded := make([]interface{}, len(hits))
+	for i, hit := range hits {
+		encoded[i] = struct {
+			models.SearchHit
+			Task interface{} `json:"task"`
+		}{hit, codec.EncodeTask(hit.Task)}
+	}
+	return encoded
+}
+
+// EncodeGroups convierte las tareas de cada grupo de una vista
+func EncodeGroups(codec TaskCodec, groups []models.TaskGroup) []interface{} {
+	encoded := make([]interface{}, len(groups))
+	for i, group := range groups {
+		encoded[i] = struct {
+			models.TaskGroup
+			Tasks []interface{} `json:"tasks"`
+		}{group, EncodeTasks(codec, group.Tasks)}
+	}
+	return encoded
+}
+
+// EncodeSyncChanges convierte las tareas creadas y actualizadas de una
+// respuesta de sincronización
+func EncodeSyncChanges(codec TaskCodec, changes models.SyncChanges) interface{} {
+	return struct {
+		models.SyncChanges
+		Created []interface{} `json:"created"`
+		Updated []interface{} `json:"updated"`
+	}{changes, EncodeTasks(codec, changes.Created), EncodeTasks(codec, changes.Updated)}
+}
+
+// EncodeSyncResults convierte la tarea de cada resultado de una sincronización
+func EncodeSyncResults(codec TaskCodec, results []models.SyncResult) []interface{} {
+	encoded := make([]interface{}, len(results))
+	for i, result := range results {
+		encoded[i] = struct {
+			models.SyncResult
+			Task interface{} `json:"task,omitempty"`
+		}{result, EncodeTaskPtr(codec, result.Task)}
+	}
+	return encoded
+}
@@ -0,0 +1,38 @@
+package apiversion
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// renameCodec expone el título como "name", como lo haría una versión nueva
+type renameCodec struct{ v1Codec }
+
+func (renameCodec) EncodeTask(task models.Task) interface{} {
+	return map[string]interface{}{"id": task.ID, "name": task.Title}
+}
+
+func TestEncodeEventUsesCodec(t *testing.T) {
+	event := models.TaskEvent{ID: 4, Type: models.EventTaskCreated, TaskID: 1, Task: &models.Task{ID: 1, Title: "Regar"}}
+	data, _ := json.Marshal(EncodeEvent(renameCodec{}, event))
+
+	got := decode(data)
+	task, _ := got["task"].(map[string]interface{})
+	if got["id"] != float64(4) || got["type"] != models.EventTaskCreated || task["name"] != "Regar" || task["title"] != nil {
+		t.Errorf("Evento codificado incorrecto: %s", data)
+	}
+
+	// Sin tarea el campo se omite
+	data, _ = json.Marshal(EncodeEvent(renameCodec{}, models.TaskEvent{ID: 5}))
+	if _, ok := decode(data)["task"]; ok {
+		t.Errorf("Se esperaba omitir la tarea: %s", data)
+	}
+}
+
+func decode(data []byte) map[string]interface{} {
+	var value map[string]interface{}
+	json.Unmarshal(data, &value)
+	return value
+}
@@ -0,0 +1,107 @@
+// Package apiversion identifica la versión de la API de cada solicitud y
+// convierte las tareas guardadas a la representación de esa versión. Una
+// versión nueva registra su TaskCodec sin cambiar models.Task ni los
+// clientes de las versiones anteriores.
+//
+// Todas las respuestas y cuerpos REST con tareas pasan por el TaskCodec de
+// la solicitud, también los de búsqueda, sincronización, lotes, papelera,
+// historial, vistas, eventos SSE, WebSocket y la exportación JSON Lines.
+// Quedan fuera las superficies con esquema propio, que evolucionan por su
+// cuenta: GraphQL, gRPC, iCalendar y CalDAV, y las columnas de CSV y
+// todo.txt. Los webhooks y el outbox no pertenecen a una solicitud y envían
+// siempre la representación de models.Task.
+package apiversion
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// Version es una versión publicada de la API
+type Version int
+
+const (
+	// V1 es la primera versión publicada; su representación de las tareas
+	// es la de models.Task
+	V1 Version = 1
+	// Latest es la versión más reciente
+	Latest = V1
+)
+
+// String devuelve el nombre de la versión usado en las rutas: v1
+func (v Version) String() string {
+	return "v" + strconv.Itoa(int(v))
+}
+
+// TaskCodec convierte entre models.Task y el JSON de una versión
+type TaskCodec interface {
+	// EncodeTask devuelve el valor que se serializa en las respuestas
+	EncodeTask(task models.Task) interface{}
+	// DecodeTask interpreta el cuerpo de una solicitud
+	DecodeTask(data []byte) (models.Task, error)
+}
+
+// codecs son las representaciones de cada versión publicada
+var codecs = map[Version]TaskCodec{
+	V1: v1Codec{},
+}
+
+// Codec devuelve la representación de la versión, o la de V1 si no existe
+func Codec(v Version) TaskCodec {
+	if codec, ok := codecs[v]; ok {
+		return codec
+	}
+	return codecs[V1]
+}
+
+// v1Codec usa las etiquetas json de models.Task
+type v1Codec struct{}
+
+func (v1Codec) EncodeTask(task models.Task) interface{} {
+	return task
+}
+
+func (v1Codec) DecodeTask(data []byte) (models.Task, error) {
+	var task models.Task
+	err := json.Unmarshal(data, &task)
+	return task, err
+}
+
+type versionKey struct{}
+
+// WithVersion devuelve un contexto con la versión de la solicitud
+func WithVersion(ctx context.Context, v Version) context.Context {
+	return context.WithValue(ctx, versionKey{}, v)
+}
+
+// FromContext devuelve la versión de la solicitud; V1 si no se indicó
+func FromContext(ctx context.Context) Version {
+	if v, ok := ctx.Value(versionKey{}).(Version); ok {
+		return v
+	}
+	return V1
+}
+
+// Middleware asigna la versión a las solicitudes de un grupo de rutas y la
+// informa en el encabezado API-Version
+func Middleware(v Version) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("API-Version", v.String())
+			next.ServeHTTP(w, r.WithContext(WithVersion(r.Context(), v)))
+		})
+	}
+}
+
+// EncodeTasks convierte una lista de tareas a la representación de la versión
+func EncodeTasks(codec TaskCodec, tasks []models.Task) []interface{} {
+	encoded := make([]interface{}, len(tasks))
+	for i, task := range tasks {
+		encoded[i] = codec.EncodeTask(task)
+	}
+	return encoded
+}
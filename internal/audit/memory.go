@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/store"
+)
+
+// MemoryStore guarda los registros de auditoría en memoria
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records []models.AuditRecord
+	nextID  int64
+}
+
+// NewMemoryStore crea un almacén de auditoría en memoria
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: []models.AuditRecord{},
+		nextID:  1,
+	}
+}
+
+// Append agrega un registro y le asigna ID y fecha. Las transacciones en
+// memoria no pueden fallar después de fn, así que el registro se agrega
+// directamente.
+func (s *MemoryStore) Append(tx store.Tx, record *models.AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record.ID = s.nextID
+	record.CreatedAt = time.Now()
+	s.nextID++
+
+	s.records = append(s.records, *record)
+	return nil
+}
+
+// List devuelve los registros que cumplen el filtro en orden cronológico
+func (s *MemoryStore) List(ctx context.Context, filter Filter) ([]models.AuditRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := []models.AuditRecord{}
+	for _, record := range s.records {
+		if !filter.matches(record) {
+			continue
+		}
+		records = append(records, record)
+		if filter.Limit > 0 && len(records) == filter.Limit {
+			break
+		}
+	}
+	return records, nil
+}
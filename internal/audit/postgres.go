@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/store"
+)
+
+// PostgresStore guarda los registros de auditoría en la tabla audit_log
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore crea un almacén de auditoría respaldado por PostgreSQL
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// errNotSQLTx indica que la transacción no es de PostgresTaskStore
+var errNotSQLTx = errors.New("la auditoría en PostgreSQL requiere una transacción de PostgreSQL")
+
+// Append inserta un registro en audit_log dentro de la transacción de la tarea
+func (s *PostgresStore) Append(tx store.Tx, record *models.AuditRecord) error {
+	sqlTx, ok := tx.(store.SQLTx)
+	if !ok {
+		return errNotSQLTx
+	}
+	ctx, q := sqlTx.SQL()
+
+	before, err := json.Marshal(record.Before)
+	if err != nil {
+		return err
+	}
+	after, err := json.Marshal(record.After)
+	if err != nil {
+		return err
+	}
+	changes, err := json.Marshal(record.Changes)
+	if err != nil {
+		return err
+	}
+
+	return q.QueryRowContext(ctx,
+		`INSERT INTO audit_log (actor, action, task_id, before, after, changes, request_id, ip, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		 RETURNING id, created_at`,
+		record.Actor, record.Action, record.TaskID, before, after, changes, record.RequestID, record.IP,
+	).Scan(&record.ID, &record.CreatedAt)
+}
+
+// List consulta audit_log aplicando el filtro en orden cronológico
+func (s *PostgresStore) List(ctx context.Context, filter Filter) ([]models.AuditRecord, error) {
+	conditions := []string{"id > $1"}
+	args := []interface{}{filter.AfterID}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.Actor != "" {
+		add("actor = $%d", filter.Actor)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.TaskID != 0 {
+		add("task_id = $%d", filter.TaskID)
+	}
+	if !filter.From.IsZero() {
+		add("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("created_at < $%d", filter.To)
+	}
+
+	query := `SELECT id, actor, action, task_id, before, after, changes, request_id, ip, created_at
+		FROM audit_log WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY id`
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []models.AuditRecord{}
+	for rows.Next() {
+		var record models.AuditRecord
+		var before, after, changes []byte
+		if err := rows.Scan(&record.ID, &record.Actor, &record.Action, &record.TaskID,
+			&before, &after, &changes, &record.RequestID, &record.IP, &record.CreatedAt); err != nil {
+			return nil, err
+		}
+		json.Unmarshal(before, &record.Before)
+		json.Unmarshal(after, &record.After)
+		json.Unmarshal(changes, &record.Changes)
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/store"
+)
+
+// Filter restringe los registros devueltos por List. Los campos vacíos no filtran.
+type Filter struct {
+	Actor   string
+	Action  string
+	TaskID  int
+	From    time.Time
+	To      time.Time
+	AfterID int64
+	Limit   int
+}
+
+// Store guarda los registros de auditoría. Los registros solo pueden
+// agregarse; nunca se modifican ni se eliminan.
+type Store interface {
+	// Append agrega el registro dentro de la transacción de la modificación,
+	// para que ambos se confirmen o se descarten juntos
+	Append(tx store.Tx, record *models.AuditRecord) error
+	List(ctx context.Context, filter Filter) ([]models.AuditRecord, error)
+}
+
+// matches indica si un registro cumple el filtro
+func (f Filter) matches(record models.AuditRecord) bool {
+	if f.Actor != "" && record.Actor != f.Actor {
+		return false
+	}
+	if f.Action != "" && record.Action != f.Action {
+		return false
+	}
+	if f.TaskID != 0 && record.TaskID != f.TaskID {
+		return false
+	}
+	if !f.From.IsZero() && record.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !record.CreatedAt.Before(f.To) {
+		return false
+	}
+	return record.ID > f.AfterID
+}
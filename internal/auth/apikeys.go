@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"os"
+	"strings"
+)
+
+// APIKeyStore valida las claves de API de los clientes automatizados. Las
+// claves se definen en la variable API_KEYS con el formato
+// "cliente:clave,cliente2:clave2" y se guardan como hash SHA-256.
+type APIKeyStore struct {
+	clients map[[32]byte]string
+}
+
+// NewAPIKeyStore carga las claves de API desde el entorno
+func NewAPIKeyStore() *APIKeyStore {
+	return NewAPIKeyStoreFrom(os.Getenv("API_KEYS"))
+}
+
+// NewAPIKeyStoreFrom carga las claves de API con el formato de API_KEYS
+func NewAPIKeyStoreFrom(keys string) *APIKeyStore {
+	store := &APIKeyStore{clients: make(map[[32]byte]string)}
+	for _, entry := range strings.Split(keys, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			continue
+		}
+		store.clients[sha256.Sum256([]byte(parts[1]))] = parts[0]
+	}
+	return store
+}
+
+// Lookup devuelve el cliente al que pertenece la clave. Se busca por el
+// hash, de modo que el tiempo de la búsqueda no revela la clave.
+func (s *APIKeyStore) Lookup(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+	client, ok := s.clients[sha256.Sum256([]byte(key))]
+	return client, ok
+}
@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"context"
+)
+
+// Identity identifica al usuario autenticado y la sesión utilizada
+type Identity struct {
+	UserID    string
+	SessionID string
+}
+
+type contextKey struct{}
+
+// WithIdentity devuelve un contexto que transporta la identidad autenticada
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext obtiene la identidad autenticada del contexto, si existe
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(contextKey{}).(Identity)
+	return id, ok
+}
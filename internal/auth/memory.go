@@ -0,0 +1,247 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/claudio/todo-api/internal/logger"
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// accessToken guarda los datos de un token de acceso emitido
+type accessToken struct {
+	sessionID string
+	userID    string
+	expiresAt time.Time
+}
+
+// refreshToken guarda los datos de un token de refresco emitido
+type refreshToken struct {
+	sessionID string
+	used      bool
+	expiresAt time.Time
+}
+
+// MemorySessionStore guarda en memoria las sesiones y los tokens emitidos.
+// Los tokens se almacenan como hash SHA-256, nunca en texto plano.
+type MemorySessionStore struct {
+	mu            sync.Mutex
+	sessions      map[string]*models.Session
+	accessTokens  map[string]accessToken
+	refreshTokens map[string]refreshToken
+	accessTTL     time.Duration
+	refreshTTL    time.Duration
+	now           func() time.Time
+}
+
+// NewMemorySessionStore crea un almacén de sesiones en memoria usando las
+// duraciones de ACCESS_TOKEN_TTL y REFRESH_TOKEN_TTL si están definidas
+func NewMemorySessionStore() *MemorySessionStore {
+	return NewMemorySessionStoreWithTTL(accessTTL(), refreshTTL())
+}
+
+// NewMemorySessionStoreWithTTL crea un almacén de sesiones en memoria con
+// duraciones explícitas
+func NewMemorySessionStoreWithTTL(accessTTL, refreshTTL time.Duration) *MemorySessionStore {
+	return &MemorySessionStore{
+		sessions:      make(map[string]*models.Session),
+		accessTokens:  make(map[string]accessToken),
+		refreshTokens: make(map[string]refreshToken),
+		accessTTL:     accessTTL,
+		refreshTTL:    refreshTTL,
+		now:           time.Now,
+	}
+}
+
+// CreateSession inicia una nueva sesión para el usuario y emite el primer par de tokens
+func (s *MemorySessionStore) CreateSession(ctx context.Context, userID, userAgent, ip string) (models.TokenPair, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := randomToken(16)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	now := s.now()
+	s.sessions[id] = &models.Session{
+		ID:         id,
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IP:         ip,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.refreshTTL),
+	}
+
+	return s.issue(id, userID)
+}
+
+// Refresh rota el token de refresco. Si el token ya fue utilizado se asume
+// que fue robado y se revoca la familia completa (la sesión).
+func (s *MemorySessionStore) Refresh(ctx context.Context, token string) (models.TokenPair, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := hashToken(token)
+	rt, ok := s.refreshTokens[key]
+	if !ok {
+		return models.TokenPair{}, ErrInvalidToken
+	}
+
+	session, ok := s.sessions[rt.sessionID]
+	if !ok || session.RevokedAt != nil {
+		return models.TokenPair{}, ErrInvalidToken
+	}
+
+	if rt.used {
+		logger.InfoLogger.Printf("Reutilización de token de refresco detectada en la sesión %s", session.ID)
+		s.revoke(session)
+		return models.TokenPair{}, ErrTokenReused
+	}
+
+	now := s.now()
+	if now.After(rt.expiresAt) {
+		return models.TokenPair{}, ErrInvalidToken
+	}
+
+	// Marcar el token como usado; se conserva para detectar reutilizaciones
+	rt.used = true
+	s.refreshTokens[key] = rt
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(s.refreshTTL)
+
+	return s.issue(session.ID, session.UserID)
+}
+
+// Authenticate valida un token de acceso y devuelve la identidad asociada
+func (s *MemorySessionStore) Authenticate(ctx context.Context, token string) (Identity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	at, ok := s.accessTokens[hashToken(token)]
+	if !ok {
+		return Identity{}, ErrInvalidToken
+	}
+
+	now := s.now()
+	if now.After(at.expiresAt) {
+		delete(s.accessTokens, hashToken(token))
+		return Identity{}, ErrInvalidToken
+	}
+
+	session, ok := s.sessions[at.sessionID]
+	if !ok || session.RevokedAt != nil {
+		return Identity{}, ErrInvalidToken
+	}
+	session.LastUsedAt = now
+
+	return Identity{UserID: at.userID, SessionID: at.sessionID}, nil
+}
+
+// ListSessions devuelve las sesiones activas de un usuario, la más reciente primero
+func (s *MemorySessionStore) ListSessions(ctx context.Context, userID string) ([]models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	sessions := []models.Session{}
+	for _, session := range s.sessions {
+		if session.UserID != userID || session.RevokedAt != nil || now.After(session.ExpiresAt) {
+			continue
+		}
+		sessions = append(sessions, *session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+// RevokeSession revoca una sesión del usuario y todos sus tokens
+func (s *MemorySessionStore) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[sessionID]
+	if !ok || session.UserID != userID || session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+
+	s.revoke(session)
+	return nil
+}
+
+// Sweep elimina los tokens expirados y las sesiones expiradas. Las sesiones
+// revocadas se conservan hasta que expiran para seguir detectando la
+// reutilización de sus tokens de refresco.
+func (s *MemorySessionStore) Sweep(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	swept := 0
+	for id, session := range s.sessions {
+		if now.After(session.ExpiresAt) {
+			delete(s.sessions, id)
+			swept++
+		}
+	}
+	for key, at := range s.accessTokens {
+		if _, ok := s.sessions[at.sessionID]; !ok || now.After(at.expiresAt) {
+			delete(s.accessTokens, key)
+		}
+	}
+	for key, rt := range s.refreshTokens {
+		if _, ok := s.sessions[rt.sessionID]; !ok || now.After(rt.expiresAt) {
+			delete(s.refreshTokens, key)
+		}
+	}
+	return swept, nil
+}
+
+// issue emite un nuevo par de tokens para la sesión. Debe llamarse con el mutex tomado.
+func (s *MemorySessionStore) issue(sessionID, userID string) (models.TokenPair, error) {
+	access, err := randomToken(32)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	refresh, err := randomToken(32)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	now := s.now()
+	s.accessTokens[hashToken(access)] = accessToken{
+		sessionID: sessionID,
+		userID:    userID,
+		expiresAt: now.Add(s.accessTTL),
+	}
+	s.refreshTokens[hashToken(refresh)] = refreshToken{
+		sessionID: sessionID,
+		expiresAt: now.Add(s.refreshTTL),
+	}
+
+	return models.TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.accessTTL.Seconds()),
+	}, nil
+}
+
+// revoke marca la sesión como revocada y elimina sus tokens de acceso.
+// Los tokens de refresco se conservan para seguir detectando reutilizaciones.
+func (s *MemorySessionStore) revoke(session *models.Session) {
+	now := s.now()
+	session.RevokedAt = &now
+
+	for key, at := range s.accessTokens {
+		if at.sessionID == session.ID {
+			delete(s.accessTokens, key)
+		}
+	}
+}
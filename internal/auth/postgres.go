@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/claudio/todo-api/internal/logger"
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// PostgresSessionStore guarda las sesiones en la tabla sessions y los tokens
+// en access_tokens y refresh_tokens, para que sobrevivan a los reinicios y se
+// compartan entre instancias. Los tokens se guardan como hash SHA-256.
+type PostgresSessionStore struct {
+	db         *sql.DB
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewPostgresSessionStore crea un almacén de sesiones respaldado por
+// PostgreSQL usando las duraciones de ACCESS_TOKEN_TTL y REFRESH_TOKEN_TTL
+func NewPostgresSessionStore(db *sql.DB) *PostgresSessionStore {
+	return &PostgresSessionStore{
+		db:         db,
+		accessTTL:  accessTTL(),
+		refreshTTL: refreshTTL(),
+	}
+}
+
+// CreateSession inicia una nueva sesión para el usuario y emite el primer par de tokens
+func (s *PostgresSessionStore) CreateSession(ctx context.Context, userID, userAgent, ip string) (models.TokenPair, error) {
+	id, err := randomToken(16)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	var tokens models.TokenPair
+	err = s.tx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO sessions (id, user_id, user_agent, ip, created_at, last_used_at, expires_at)
+			 VALUES ($1, $2, $3, $4, NOW(), NOW(), NOW() + $5 * INTERVAL '1 second')`,
+			id, userID, userAgent, ip, s.refreshTTL.Seconds())
+		if err != nil {
+			return err
+		}
+		tokens, err = s.issue(ctx, tx, id)
+		return err
+	})
+	return tokens, err
+}
+
+// Refresh rota el token de refresco. Si el token ya fue utilizado se asume
+// que fue robado y se revoca la familia completa (la sesión). La fila del
+// token se bloquea para que dos refrescos simultáneos no roten el mismo token.
+func (s *PostgresSessionStore) Refresh(ctx context.Context, token string) (models.TokenPair, error) {
+	var tokens models.TokenPair
+	reused := false
+	err := s.tx(ctx, func(tx *sql.Tx) error {
+		var sessionID string
+		var used, expired, revoked bool
+		err := tx.QueryRowContext(ctx,
+			`SELECT r.session_id, r.used, r.expires_at < NOW(), s.revoked_at IS NOT NULL
+			 FROM refresh_tokens r JOIN sessions s ON s.id = r.session_id
+			 WHERE r.token_hash = $1
+			 FOR UPDATE`, hashToken(token),
+		).Scan(&sessionID, &used, &expired, &revoked)
+		if err == sql.ErrNoRows || (err == nil && revoked) {
+			return ErrInvalidToken
+		}
+		if err != nil {
+			return err
+		}
+
+		if used {
+			logger.InfoLogger.Printf("Reutilización de token de refresco detectada en la sesión %s", sessionID)
+			reused = true
+			return revokeSession(ctx, tx, sessionID)
+		}
+		if expired {
+			return ErrInvalidToken
+		}
+
+		// Marcar el token como usado; se conserva para detectar reutilizaciones
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE refresh_tokens SET used = TRUE WHERE token_hash = $1`, hashToken(token)); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE sessions SET last_used_at = NOW(), expires_at = NOW() + $2 * INTERVAL '1 second' WHERE id = $1`,
+			sessionID, s.refreshTTL.Seconds()); err != nil {
+			return err
+		}
+		tokens, err = s.issue(ctx, tx, sessionID)
+		return err
+	})
+	// La revocación por reutilización se confirma antes de devolver el error
+	if err == nil && reused {
+		return models.TokenPair{}, ErrTokenReused
+	}
+	return tokens, err
+}
+
+// Authenticate valida un token de acceso y devuelve la identidad asociada
+func (s *PostgresSessionStore) Authenticate(ctx context.Context, token string) (Identity, error) {
+	var identity Identity
+	var expired bool
+	err := s.db.QueryRowContext(ctx,
+		`SELECT a.session_id, s.user_id, a.expires_at < NOW()
+		 FROM access_tokens a JOIN sessions s ON s.id = a.session_id
+		 WHERE a.token_hash = $1 AND s.revoked_at IS NULL`, hashToken(token),
+	).Scan(&identity.SessionID, &identity.UserID, &expired)
+	if err == sql.ErrNoRows {
+		return Identity{}, ErrInvalidToken
+	}
+	if err != nil {
+		return Identity{}, err
+	}
+	if expired {
+		if _, err := s.db.ExecContext(ctx, `DELETE FROM access_tokens WHERE token_hash = $1`, hashToken(token)); err != nil {
+			return Identity{}, err
+		}
+		return Identity{}, ErrInvalidToken
+	}
+
+	_, err = s.db.ExecContext(ctx, `UPDATE sessions SET last_used_at = NOW() WHERE id = $1`, identity.SessionID)
+	return identity, err
+}
+
+// ListSessions devuelve las sesiones activas de un usuario, la más reciente primero
+func (s *PostgresSessionStore) ListSessions(ctx context.Context, userID string) ([]models.Session, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, user_id, user_agent, ip, created_at, last_used_at, expires_at
+		 FROM sessions
+		 WHERE user_id = $1 AND revoked_at IS NULL AND expires_at >= NOW()
+		 ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IP,
+			&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// RevokeSession revoca una sesión del usuario y todos sus tokens
+func (s *PostgresSessionStore) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	return s.tx(ctx, func(tx *sql.Tx) error {
+		var owner string
+		err := tx.QueryRowContext(ctx,
+			`SELECT user_id FROM sessions WHERE id = $1 AND revoked_at IS NULL FOR UPDATE`, sessionID,
+		).Scan(&owner)
+		if err == sql.ErrNoRows || (err == nil && owner != userID) {
+			return ErrSessionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return revokeSession(ctx, tx, sessionID)
+	})
+}
+
+// Sweep elimina los tokens expirados y las sesiones expiradas; los tokens
+// de las sesiones se eliminan en cascada. Las sesiones revocadas se
+// conservan hasta que expiran para seguir detectando la reutilización de
+// sus tokens de refresco.
+func (s *PostgresSessionStore) Sweep(ctx context.Context) (int, error) {
+	var swept int64
+	err := s.tx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at < NOW()`)
+		if err != nil {
+			return err
+		}
+		swept, _ = result.RowsAffected()
+		if _, err := tx.ExecContext(ctx, `DELETE FROM access_tokens WHERE expires_at < NOW()`); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE expires_at < NOW()`)
+		return err
+	})
+	return int(swept), err
+}
+
+// issue emite un nuevo par de tokens para la sesión dentro de la transacción
+func (s *PostgresSessionStore) issue(ctx context.Context, tx *sql.Tx, sessionID string) (models.TokenPair, error) {
+	access, err := randomToken(32)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	refresh, err := randomToken(32)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO access_tokens (token_hash, session_id, expires_at)
+		 VALUES ($1, $2, NOW() + $3 * INTERVAL '1 second')`,
+		hashToken(access), sessionID, s.accessTTL.Seconds()); err != nil {
+		return models.TokenPair{}, err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO refresh_tokens (token_hash, session_id, expires_at)
+		 VALUES ($1, $2, NOW() + $3 * INTERVAL '1 second')`,
+		hashToken(refresh), sessionID, s.refreshTTL.Seconds()); err != nil {
+		return models.TokenPair{}, err
+	}
+
+	return models.TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.accessTTL.Seconds()),
+	}, nil
+}
+
+// revokeSession marca la sesión como revocada y elimina sus tokens de
+// acceso. Los tokens de refresco se conservan para seguir detectando
+// reutilizaciones.
+func revokeSession(ctx context.Context, tx *sql.Tx, sessionID string) error {
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE sessions SET revoked_at = NOW() WHERE id = $1`, sessionID); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `DELETE FROM access_tokens WHERE session_id = $1`, sessionID)
+	return err
+}
+
+// tx ejecuta fn dentro de una transacción SQL
+func (s *PostgresSessionStore) tx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	sqlTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(sqlTx); err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+	return sqlTx.Commit()
+}
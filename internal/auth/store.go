@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/claudio/todo-api/internal/config"
+	"github.com/claudio/todo-api/internal/logger"
+	"github.com/claudio/todo-api/internal/models"
+)
+
+const (
+	// DefaultAccessTTL es la duración predeterminada de un token de acceso
+	DefaultAccessTTL = 15 * time.Minute
+	// DefaultRefreshTTL es la duración predeterminada de un token de refresco
+	DefaultRefreshTTL = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrInvalidToken indica que el token no existe o ha expirado
+	ErrInvalidToken = errors.New("token inválido o expirado")
+	// ErrTokenReused indica que se presentó un token de refresco ya utilizado
+	ErrTokenReused = errors.New("token de refresco reutilizado, sesión revocada")
+	// ErrSessionNotFound indica que la sesión no existe o no pertenece al usuario
+	ErrSessionNotFound = errors.New("sesión no encontrada")
+)
+
+// SessionStore guarda las sesiones y los tokens emitidos. Cada sesión agrupa
+// una familia de tokens de refresco rotativos; presentar un token de
+// refresco ya utilizado revoca la familia completa.
+type SessionStore interface {
+	// CreateSession inicia una nueva sesión para el usuario y emite el
+	// primer par de tokens
+	CreateSession(ctx context.Context, userID, userAgent, ip string) (models.TokenPair, error)
+	// Refresh rota el token de refresco
+	Refresh(ctx context.Context, token string) (models.TokenPair, error)
+	// Authenticate valida un token de acceso y devuelve la identidad asociada
+	Authenticate(ctx context.Context, token string) (Identity, error)
+	// ListSessions devuelve las sesiones activas de un usuario, la más
+	// reciente primero
+	ListSessions(ctx context.Context, userID string) ([]models.Session, error)
+	// RevokeSession revoca una sesión del usuario y todos sus tokens
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	// Sweep elimina los tokens expirados y las sesiones expiradas con sus
+	// tokens, y devuelve cuántas sesiones eliminó
+	Sweep(ctx context.Context) (int, error)
+}
+
+// accessTTL y refreshTTL devuelven las duraciones de ACCESS_TOKEN_TTL y
+// REFRESH_TOKEN_TTL, o las predeterminadas
+func accessTTL() time.Duration {
+	return config.Duration("ACCESS_TOKEN_TTL", DefaultAccessTTL)
+}
+
+func refreshTTL() time.Duration {
+	return config.Duration("REFRESH_TOKEN_TTL", DefaultRefreshTTL)
+}
+
+// StartSweeper ejecuta Sweep cada interval hasta que se cancele ctx
+func StartSweeper(ctx context.Context, store SessionStore, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				swept, err := store.Sweep(ctx)
+				if err != nil {
+					logger.ErrorLogger.Printf("Error al eliminar las sesiones expiradas: %v", err)
+				} else if swept > 0 {
+					logger.InfoLogger.Printf("Sesiones: %d sesiones expiradas eliminadas", swept)
+				}
+			}
+		}
+	}()
+}
+
+// randomToken genera un token aleatorio codificado en hexadecimal
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken calcula el hash con el que se indexa un token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/claudio/todo-api/internal/logger"
+)
+
+func TestRefreshRotatesTokens(t *testing.T) {
+	logger.Init()
+	ctx := context.Background()
+	store := NewMemorySessionStoreWithTTL(time.Minute, time.Hour)
+
+	tokens, err := store.CreateSession(ctx, "ana", "test", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotar el token de refresco
+	rotated, err := store.Refresh(ctx, tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("No se pudo refrescar el token: %v", err)
+	}
+	if rotated.RefreshToken == tokens.RefreshToken {
+		t.Error("Se esperaba un token de refresco nuevo")
+	}
+
+	// Ambos tokens de acceso siguen siendo válidos hasta que expiren
+	identity, err := store.Authenticate(ctx, rotated.AccessToken)
+	if err != nil {
+		t.Fatalf("Token de acceso rechazado: %v", err)
+	}
+	if identity.UserID != "ana" {
+		t.Errorf("Usuario incorrecto: obtuvo %v, esperaba ana", identity.UserID)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	logger.Init()
+	ctx := context.Background()
+	store := NewMemorySessionStoreWithTTL(time.Minute, time.Hour)
+
+	tokens, _ := store.CreateSession(ctx, "ana", "test", "127.0.0.1")
+	rotated, _ := store.Refresh(ctx, tokens.RefreshToken)
+
+	// Reutilizar el token original debe revocar toda la sesión
+	if _, err := store.Refresh(ctx, tokens.RefreshToken); err != ErrTokenReused {
+		t.Fatalf("Se esperaba ErrTokenReused, obtuvo %v", err)
+	}
+
+	if _, err := store.Authenticate(ctx, rotated.AccessToken); err == nil {
+		t.Error("Se esperaba que el token de acceso fuera revocado")
+	}
+	if _, err := store.Refresh(ctx, rotated.RefreshToken); err == nil {
+		t.Error("Se esperaba que el token de refresco rotado fuera revocado")
+	}
+	if sessions, _ := store.ListSessions(ctx, "ana"); len(sessions) != 0 {
+		t.Errorf("Se esperaban 0 sesiones activas, obtuvo %d", len(sessions))
+	}
+}
+
+func TestAccessTokenExpires(t *testing.T) {
+	logger.Init()
+	ctx := context.Background()
+	store := NewMemorySessionStoreWithTTL(time.Minute, time.Hour)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	tokens, _ := store.CreateSession(ctx, "ana", "test", "127.0.0.1")
+
+	now = now.Add(2 * time.Minute)
+	if _, err := store.Authenticate(ctx, tokens.AccessToken); err != ErrInvalidToken {
+		t.Errorf("Se esperaba ErrInvalidToken, obtuvo %v", err)
+	}
+}
+
+func TestSweepRemovesExpiredSessions(t *testing.T) {
+	logger.Init()
+	ctx := context.Background()
+	store := NewMemorySessionStoreWithTTL(time.Minute, time.Hour)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	expired, _ := store.CreateSession(ctx, "ana", "test", "127.0.0.1")
+	now = now.Add(50 * time.Minute)
+	active, _ := store.CreateSession(ctx, "ana", "test", "127.0.0.1")
+
+	// La primera sesión expira; de la segunda solo expira el token de acceso
+	now = now.Add(20 * time.Minute)
+	swept, err := store.Sweep(ctx)
+	if err != nil || swept != 1 {
+		t.Fatalf("Se esperaba eliminar 1 sesión, obtuvo %d (%v)", swept, err)
+	}
+	if len(store.sessions) != 1 || len(store.accessTokens) != 0 || len(store.refreshTokens) != 1 {
+		t.Errorf("Quedaron %d sesiones, %d tokens de acceso y %d de refresco", len(store.sessions), len(store.accessTokens), len(store.refreshTokens))
+	}
+	if _, err := store.Refresh(ctx, expired.RefreshToken); err != ErrInvalidToken {
+		t.Errorf("Se esperaba ErrInvalidToken, obtuvo %v", err)
+	}
+	if _, err := store.Refresh(ctx, active.RefreshToken); err != nil {
+		t.Errorf("Token de refresco activo rechazado: %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"os"
+	"strings"
+)
+
+// UserStore valida credenciales de usuario. Los usuarios se definen en la
+// variable AUTH_USERS con el formato "usuario:clave,usuario2:clave2".
+type UserStore struct {
+	passwords map[string][32]byte
+}
+
+// NewUserStore carga los usuarios desde el entorno; en desarrollo usa admin:admin
+func NewUserStore() *UserStore {
+	users := os.Getenv("AUTH_USERS")
+	if users == "" {
+		users = "admin:admin"
+	}
+
+	store := &UserStore{passwords: make(map[string][32]byte)}
+	for _, entry := range strings.Split(users, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			continue
+		}
+		store.passwords[parts[0]] = sha256.Sum256([]byte(parts[1]))
+	}
+	return store
+}
+
+// Verify comprueba si la clave corresponde al usuario
+func (u *UserStore) Verify(username, password string) bool {
+	expected, ok := u.passwords[username]
+	given := sha256.Sum256([]byte(password))
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare(expected[:], given[:]) == 1
+}
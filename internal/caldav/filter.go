@@ -0,0 +1,82 @@
+package caldav
+
+import (
+	"strings"
+)
+
+// CompFilter es un comp-filter de calendar-query (RFC 4791, sección 9.7)
+type CompFilter struct {
+	Name         string       `xml:"name,attr"`
+	IsNotDefined *struct{}    `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	Comps        []CompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	Props        []PropFilter `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+}
+
+// PropFilter es un prop-filter de calendar-query
+type PropFilter struct {
+	Name         string     `xml:"name,attr"`
+	IsNotDefined *struct{}  `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TextMatch    *TextMatch `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+}
+
+// TextMatch busca un texto sin distinguir mayúsculas (i;ascii-casemap)
+type TextMatch struct {
+	Value           string `xml:",chardata"`
+	NegateCondition string `xml:"negate-condition,attr"`
+}
+
+// Matches indica si el recurso de una tarea, un VCALENDAR con un solo VTODO
+// cuyas propiedades son todo, cumple el filtro. Los time-range se ignoran y
+// ningún rango excluye una tarea; los clientes filtran por DUE por su cuenta.
+func (f *CompFilter) Matches(todo map[string]string) bool {
+	if f == nil {
+		return true
+	}
+	if !strings.EqualFold(f.Name, "VCALENDAR") || f.IsNotDefined != nil {
+		return false
+	}
+	for _, comp := range f.Comps {
+		if !comp.matchesTodo(todo) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesTodo evalúa un comp-filter hijo de VCALENDAR
+func (f CompFilter) matchesTodo(todo map[string]string) bool {
+	// El recurso no tiene otros componentes, como VEVENT, ni componentes
+	// anidados en el VTODO, como VALARM
+	if !strings.EqualFold(f.Name, "VTODO") {
+		return f.IsNotDefined != nil
+	}
+	if f.IsNotDefined != nil {
+		return false
+	}
+	for _, comp := range f.Comps {
+		if comp.IsNotDefined == nil {
+			return false
+		}
+	}
+	for _, prop := range f.Props {
+		if !prop.matches(todo) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f PropFilter) matches(todo map[string]string) bool {
+	value, ok := todo[strings.ToUpper(f.Name)]
+	if f.IsNotDefined != nil {
+		return !ok
+	}
+	if !ok {
+		return false
+	}
+	if f.TextMatch == nil {
+		return true
+	}
+	contains := strings.Contains(strings.ToLower(value), strings.ToLower(f.TextMatch.Value))
+	return contains != (f.TextMatch.NegateCondition == "yes")
+}
@@ -0,0 +1,62 @@
+package caldav
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore guarda los recursos en memoria
+type MemoryStore struct {
+	mu      sync.RWMutex
+	objects map[int]Object
+}
+
+// NewMemoryStore crea un almacén de recursos en memoria
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{objects: make(map[int]Object)}
+}
+
+// Put guarda el recurso de una tarea
+func (s *MemoryStore) Put(ctx context.Context, object Object) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[object.TaskID] = object
+	return nil
+}
+
+// ByName busca un recurso del propietario por nombre
+func (s *MemoryStore) ByName(ctx context.Context, ownerID, name string) (Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, object := range s.objects {
+		if object.OwnerID == ownerID && object.Name == name {
+			return object, nil
+		}
+	}
+	return Object{}, ErrNotFound
+}
+
+// ByTask devuelve los recursos de las tareas indicadas
+func (s *MemoryStore) ByTask(ctx context.Context, taskIDs []int) (map[int]Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	objects := map[int]Object{}
+	for _, id := range taskIDs {
+		if object, ok := s.objects[id]; ok {
+			objects[id] = object
+		}
+	}
+	return objects, nil
+}
+
+// Delete elimina el recurso de una tarea
+func (s *MemoryStore) Delete(ctx context.Context, taskID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.objects, taskID)
+	return nil
+}
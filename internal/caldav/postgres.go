@@ -0,0 +1,71 @@
+package caldav
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// PostgresStore guarda los recursos en la tabla caldav_objects
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore crea un almacén de recursos respaldado por PostgreSQL
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Put guarda el recurso de una tarea
+func (s *PostgresStore) Put(ctx context.Context, object Object) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO caldav_objects (task_id, owner_id, name, uid)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (task_id) DO UPDATE SET owner_id = EXCLUDED.owner_id, name = EXCLUDED.name, uid = EXCLUDED.uid`,
+		object.TaskID, object.OwnerID, object.Name, object.UID)
+	return err
+}
+
+// ByName busca un recurso del propietario por nombre
+func (s *PostgresStore) ByName(ctx context.Context, ownerID, name string) (Object, error) {
+	object := Object{OwnerID: ownerID, Name: name}
+	err := s.db.QueryRowContext(ctx,
+		`SELECT task_id, uid FROM caldav_objects WHERE owner_id = $1 AND name = $2`, ownerID, name,
+	).Scan(&object.TaskID, &object.UID)
+	if err == sql.ErrNoRows {
+		return object, ErrNotFound
+	}
+	return object, err
+}
+
+// ByTask devuelve los recursos de las tareas indicadas
+func (s *PostgresStore) ByTask(ctx context.Context, taskIDs []int) (map[int]Object, error) {
+	ids := make([]int64, len(taskIDs))
+	for i, id := range taskIDs {
+		ids[i] = int64(id)
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT task_id, owner_id, name, uid FROM caldav_objects WHERE task_id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	objects := map[int]Object{}
+	for rows.Next() {
+		var object Object
+		if err := rows.Scan(&object.TaskID, &object.OwnerID, &object.Name, &object.UID); err != nil {
+			return nil, err
+		}
+		objects[object.TaskID] = object
+	}
+	return objects, rows.Err()
+}
+
+// Delete elimina el recurso de una tarea
+func (s *PostgresStore) Delete(ctx context.Context, taskID int) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM caldav_objects WHERE task_id = $1`, taskID)
+	return err
+}
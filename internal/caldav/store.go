@@ -0,0 +1,33 @@
+// Package caldav contiene lo que el servidor CalDAV necesita además de las
+// tareas: los nombres y UID con que los clientes crearon cada recurso, y la
+// lectura y escritura de los documentos XML de WebDAV y CalDAV.
+package caldav
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound indica que ningún recurso tiene ese nombre
+var ErrNotFound = errors.New("recurso CalDAV no encontrado")
+
+// Object enlaza una tarea con el recurso que la creó desde un cliente. Las
+// tareas sin Object se publican como "{id}.ics" con el UID de ical.UID.
+type Object struct {
+	TaskID  int
+	OwnerID string
+	// Name es el último segmento del href, como "2f6b….ics"
+	Name string
+	UID  string
+}
+
+// Store guarda los recursos creados por clientes CalDAV. Los nombres son
+// únicos para cada propietario.
+type Store interface {
+	// Put guarda el recurso de una tarea y reemplaza el que tuviera
+	Put(ctx context.Context, object Object) error
+	ByName(ctx context.Context, ownerID, name string) (Object, error)
+	// ByTask devuelve los recursos de las tareas indicadas que tienen uno
+	ByTask(ctx context.Context, taskIDs []int) (map[int]Object, error)
+	Delete(ctx context.Context, taskID int) error
+}
@@ -0,0 +1,224 @@
+package caldav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// Espacios de nombres XML de WebDAV, CalDAV y de las extensiones de Apple
+const (
+	NamespaceDAV    = "DAV:"
+	NamespaceCalDAV = "urn:ietf:params:xml:ns:caldav"
+	NamespaceCS     = "http://calendarserver.org/ns/"
+)
+
+// Propiedades que publica el servidor
+var (
+	ResourceType                  = xml.Name{Space: NamespaceDAV, Local: "resourcetype"}
+	DisplayName                   = xml.Name{Space: NamespaceDAV, Local: "displayname"}
+	GetETag                       = xml.Name{Space: NamespaceDAV, Local: "getetag"}
+	GetContentType                = xml.Name{Space: NamespaceDAV, Local: "getcontenttype"}
+	CurrentUserPrincipal          = xml.Name{Space: NamespaceDAV, Local: "current-user-principal"}
+	PrincipalURL                  = xml.Name{Space: NamespaceDAV, Local: "principal-URL"}
+	CalendarHomeSet               = xml.Name{Space: NamespaceCalDAV, Local: "calendar-home-set"}
+	CalendarData                  = xml.Name{Space: NamespaceCalDAV, Local: "calendar-data"}
+	SupportedCalendarComponentSet = xml.Name{Space: NamespaceCalDAV, Local: "supported-calendar-component-set"}
+	GetCTag                       = xml.Name{Space: NamespaceCS, Local: "getctag"}
+)
+
+// Tipos de REPORT admitidos
+var (
+	CalendarQuery    = xml.Name{Space: NamespaceCalDAV, Local: "calendar-query"}
+	CalendarMultiget = xml.Name{Space: NamespaceCalDAV, Local: "calendar-multiget"}
+)
+
+// ErrUnsupportedReport indica un REPORT distinto de calendar-query y calendar-multiget
+var ErrUnsupportedReport = errors.New("REPORT no admitido: use calendar-query o calendar-multiget")
+
+// PropRequest son las propiedades que pide un PROPFIND o un REPORT. AllProp
+// pide todas las propiedades salvo calendar-data.
+type PropRequest struct {
+	AllProp bool
+	Names   []xml.Name
+}
+
+// propElements lee los nombres de los elementos hijos de <prop>
+type propElements struct {
+	Names []struct {
+		XMLName xml.Name
+	} `xml:",any"`
+}
+
+func (p *propElements) names() []xml.Name {
+	if p == nil {
+		return nil
+	}
+	names := make([]xml.Name, len(p.Names))
+	for i, name := range p.Names {
+		names[i] = name.XMLName
+	}
+	return names
+}
+
+// ParsePropfind lee el cuerpo de un PROPFIND; un cuerpo vacío equivale a allprop
+func ParsePropfind(body io.Reader) (PropRequest, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return PropRequest{}, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return PropRequest{AllProp: true}, nil
+	}
+
+	var request struct {
+		XMLName  xml.Name      `xml:"DAV: propfind"`
+		AllProp  *struct{}     `xml:"DAV: allprop"`
+		PropName *struct{}     `xml:"DAV: propname"`
+		Prop     *propElements `xml:"DAV: prop"`
+	}
+	if err := xml.Unmarshal(data, &request); err != nil {
+		return PropRequest{}, err
+	}
+	return PropRequest{AllProp: request.Prop == nil, Names: request.Prop.names()}, nil
+}
+
+// Report es un REPORT calendar-query o calendar-multiget
+type Report struct {
+	Type  xml.Name
+	Props PropRequest
+	// Hrefs son los recursos pedidos en calendar-multiget
+	Hrefs []string
+	// Filter es el filtro de calendar-query; nil no filtra
+	Filter *CompFilter
+}
+
+// ParseReport lee el cuerpo de un REPORT
+func ParseReport(body io.Reader) (Report, error) {
+	var request struct {
+		XMLName xml.Name
+		AllProp *struct{}     `xml:"DAV: allprop"`
+		Prop    *propElements `xml:"DAV: prop"`
+		Hrefs   []string      `xml:"DAV: href"`
+		Filter  *struct {
+			Comp *CompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+		} `xml:"urn:ietf:params:xml:ns:caldav filter"`
+	}
+	if err := xml.NewDecoder(body).Decode(&request); err != nil {
+		return Report{}, err
+	}
+	if request.XMLName != CalendarQuery && request.XMLName != CalendarMultiget {
+		return Report{}, ErrUnsupportedReport
+	}
+
+	report := Report{
+		Type:  request.XMLName,
+		Props: PropRequest{AllProp: request.Prop == nil, Names: request.Prop.names()},
+		Hrefs: request.Hrefs,
+	}
+	if request.Filter != nil {
+		report.Filter = request.Filter.Comp
+	}
+	return report, nil
+}
+
+// Props son los valores de las propiedades de un recurso como XML interno
+// del elemento de la propiedad; Text y Href construyen los más comunes
+type Props map[xml.Name]string
+
+// Text escapa un texto para usarlo como valor de una propiedad
+func Text(value string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(value))
+	return buf.String()
+}
+
+// Href devuelve un elemento <href> de WebDAV. Declara el espacio de nombres
+// porque puede ir dentro de propiedades de CalDAV.
+func Href(path string) string {
+	return `<href xmlns="DAV:">` + Text(path) + `</href>`
+}
+
+// Multistatus es una respuesta 207 con el estado de varios recursos
+type Multistatus struct {
+	XMLName   xml.Name   `xml:"DAV: multistatus"`
+	Responses []response `xml:"response"`
+}
+
+type response struct {
+	Href     string     `xml:"href"`
+	Status   string     `xml:"status,omitempty"`
+	Propstat []propstat `xml:"propstat,omitempty"`
+}
+
+type propstat struct {
+	Prop   []property `xml:"prop>x"`
+	Status string     `xml:"status"`
+}
+
+type property struct {
+	XMLName xml.Name
+	Inner   string `xml:",innerxml"`
+}
+
+// Add agrega un recurso con las propiedades pedidas. Las que el recurso no
+// tiene se informan con 404; con allprop se devuelven todas menos calendar-data.
+func (m *Multistatus) Add(href string, props Props, request PropRequest) {
+	names := request.Names
+	if request.AllProp {
+		names = nil
+		for name := range props {
+			if name != CalendarData {
+				names = append(names, name)
+			}
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return names[i].Space+names[i].Local < names[j].Space+names[j].Local
+		})
+	}
+
+	var found, missing []property
+	for _, name := range names {
+		if value, ok := props[name]; ok {
+			found = append(found, property{XMLName: name, Inner: value})
+		} else {
+			missing = append(missing, property{XMLName: name})
+		}
+	}
+
+	resp := response{Href: href}
+	if len(found) > 0 || len(missing) == 0 {
+		resp.Propstat = append(resp.Propstat, propstat{Prop: found, Status: statusLine(http.StatusOK)})
+	}
+	if len(missing) > 0 {
+		resp.Propstat = append(resp.Propstat, propstat{Prop: missing, Status: statusLine(http.StatusNotFound)})
+	}
+	m.Responses = append(m.Responses, resp)
+}
+
+// AddStatus agrega un recurso sin propiedades, como un href inexistente en
+// calendar-multiget
+func (m *Multistatus) AddStatus(href string, status int) {
+	m.Responses = append(m.Responses, response{Href: href, Status: statusLine(status)})
+}
+
+// Write escribe la respuesta 207
+func (m *Multistatus) Write(w http.ResponseWriter) error {
+	data, err := xml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	w.Write([]byte(xml.Header))
+	_, err = w.Write(data)
+	return err
+}
+
+func statusLine(status int) string {
+	return "HTTP/1.1 " + strconv.Itoa(status) + " " + http.StatusText(status)
+}
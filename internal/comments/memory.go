@@ -0,0 +1,52 @@
+package comments
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// MemoryStore guarda los comentarios en memoria
+type MemoryStore struct {
+	mu       sync.RWMutex
+	nextID   int64
+	comments map[int][]models.Comment
+}
+
+// NewMemoryStore crea un almacén de comentarios en memoria
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{nextID: 1, comments: make(map[int][]models.Comment)}
+}
+
+// Add guarda un comentario al final de los de la tarea
+func (s *MemoryStore) Add(ctx context.Context, comment models.Comment) (models.Comment, error) {
+	comment, err := validate(comment)
+	if err != nil {
+		return comment, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment.ID = s.nextID
+	comment.CreatedAt = time.Now()
+	s.nextID++
+	s.comments[comment.TaskID] = append(s.comments[comment.TaskID], comment)
+	return comment, nil
+}
+
+// ListMany devuelve los comentarios de varias tareas agrupados por tarea
+func (s *MemoryStore) ListMany(ctx context.Context, taskIDs []int) (map[int][]models.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make(map[int][]models.Comment, len(taskIDs))
+	for _, taskID := range taskIDs {
+		comments := make([]models.Comment, len(s.comments[taskID]))
+		copy(comments, s.comments[taskID])
+		result[taskID] = comments
+	}
+	return result, nil
+}
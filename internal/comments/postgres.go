@@ -0,0 +1,62 @@
+package comments
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/lib/pq"
+)
+
+// PostgresStore guarda los comentarios en la tabla task_comments
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore crea un almacén de comentarios respaldado por PostgreSQL
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+const selectComment = `SELECT id, task_id, author, body, created_at FROM task_comments`
+
+// Add inserta un comentario nuevo
+func (s *PostgresStore) Add(ctx context.Context, comment models.Comment) (models.Comment, error) {
+	comment, err := validate(comment)
+	if err != nil {
+		return comment, err
+	}
+
+	err = s.db.QueryRowContext(ctx,
+		`INSERT INTO task_comments (task_id, author, body, created_at)
+		 VALUES ($1, $2, $3, NOW())
+		 RETURNING id, created_at`,
+		comment.TaskID, comment.Author, comment.Body,
+	).Scan(&comment.ID, &comment.CreatedAt)
+	return comment, err
+}
+
+// ListMany devuelve los comentarios de varias tareas agrupados por tarea
+func (s *PostgresStore) ListMany(ctx context.Context, taskIDs []int) (map[int][]models.Comment, error) {
+	ids := make([]int64, len(taskIDs))
+	result := make(map[int][]models.Comment, len(taskIDs))
+	for i, taskID := range taskIDs {
+		ids[i] = int64(taskID)
+		result[taskID] = []models.Comment{}
+	}
+
+	rows, err := s.db.QueryContext(ctx, selectComment+` WHERE task_id = ANY($1) ORDER BY task_id, id`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var comment models.Comment
+		if err := rows.Scan(&comment.ID, &comment.TaskID, &comment.Author, &comment.Body, &comment.CreatedAt); err != nil {
+			return nil, err
+		}
+		result[comment.TaskID] = append(result[comment.TaskID], comment)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,42 @@
+package comments
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// maxBodyLength es la longitud máxima de un comentario en caracteres
+const maxBodyLength = 5000
+
+var (
+	// ErrEmptyBody indica que el comentario no tiene texto
+	ErrEmptyBody = errors.New("el comentario no puede estar vacío")
+	// ErrBodyTooLong indica que el comentario supera la longitud máxima
+	ErrBodyTooLong = errors.New("el comentario no puede superar los 5000 caracteres")
+)
+
+// Store guarda los comentarios de las tareas. La visibilidad de la tarea la
+// comprueba quien llama.
+type Store interface {
+	// Add guarda un comentario nuevo y completa su ID y su fecha
+	Add(ctx context.Context, comment models.Comment) (models.Comment, error)
+	// ListMany devuelve en una sola consulta los comentarios de varias
+	// tareas, agrupados por tarea
+	ListMany(ctx context.Context, taskIDs []int) (map[int][]models.Comment, error)
+}
+
+// validate normaliza y valida el texto del comentario
+func validate(comment models.Comment) (models.Comment, error) {
+	comment.Body = strings.TrimSpace(comment.Body)
+	if comment.Body == "" {
+		return comment, ErrEmptyBody
+	}
+	if utf8.RuneCountInString(comment.Body) > maxBodyLength {
+		return comment, ErrBodyTooLong
+	}
+	return comment, nil
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"time"
+
+	"github.com/claudio/todo-api/internal/logger"
+)
+
+// Duration lee una duración de una variable de entorno o devuelve un valor predeterminado
+func Duration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		logger.ErrorLogger.Printf("Valor inválido para %s: %v, usando %v", key, err, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+// Date lee una fecha (2006-01-02 o RFC3339) de una variable de entorno o
+// devuelve un valor predeterminado
+func Date(key string, defaultValue time.Time) time.Time {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		logger.ErrorLogger.Printf("Valor inválido para %s: %v, usando %v", key, err, defaultValue)
+		return defaultValue
+	}
+	return t
+}
@@ -0,0 +1,122 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// subscriberBuffer es la cantidad de eventos pendientes que admite cada
+// suscriptor antes de ser desconectado por lento
+const subscriberBuffer = 64
+
+// Subscription recibe los eventos publicados que cumplen su filtro.
+// El canal se cierra al cancelar la suscripción o si el suscriptor no
+// consume los eventos a tiempo; en ese caso puede reanudar desde el
+// último ID recibido.
+type Subscription struct {
+	C      <-chan models.TaskEvent
+	ch     chan models.TaskEvent
+	filter func(models.TaskEvent) bool
+}
+
+// Bus es un bus de eventos en proceso con un buffer acotado de eventos
+// recientes para que los suscriptores puedan reanudar
+type Bus struct {
+	mu          sync.Mutex
+	nextID      int64
+	evictedID   int64
+	replay      []models.TaskEvent
+	replaySize  int
+	subscribers map[*Subscription]bool
+}
+
+// NewBus crea un bus que conserva los últimos replaySize eventos
+func NewBus(replaySize int) *Bus {
+	return &Bus{
+		nextID:      1,
+		replay:      []models.TaskEvent{},
+		replaySize:  replaySize,
+		subscribers: make(map[*Subscription]bool),
+	}
+}
+
+// Publish entrega el evento a los suscriptores. Si el evento no trae ID se
+// le asigna el siguiente; si lo trae, como los mensajes del outbox, se
+// conserva y debe ser mayor que el del último evento publicado.
+func (b *Bus) Publish(event models.TaskEvent) models.TaskEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if event.ID == 0 {
+		event.ID = b.nextID
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	b.nextID = event.ID + 1
+
+	b.replay = append(b.replay, event)
+	if len(b.replay) > b.replaySize {
+		evicted := len(b.replay) - b.replaySize
+		b.evictedID = b.replay[evicted-1].ID
+		b.replay = b.replay[evicted:]
+	}
+
+	for sub := range b.subscribers {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			// El suscriptor no consume a tiempo: se desconecta
+			b.remove(sub)
+		}
+	}
+	return event
+}
+
+// Subscribe registra un suscriptor. Si lastID es mayor que cero se devuelven
+// además los eventos posteriores a lastID que siguen en el buffer; complete
+// es falso cuando algunos de esos eventos ya fueron descartados.
+func (b *Bus) Subscribe(lastID int64, filter func(models.TaskEvent) bool) (sub *Subscription, missed []models.TaskEvent, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan models.TaskEvent, subscriberBuffer)
+	sub = &Subscription{C: ch, ch: ch, filter: filter}
+	b.subscribers[sub] = true
+
+	complete = true
+	if lastID > 0 {
+		// Los IDs pueden tener huecos: la reanudación solo es incompleta si
+		// se descartó algún evento posterior a lastID
+		if b.evictedID > lastID {
+			complete = false
+		}
+		for _, event := range b.replay {
+			if event.ID > lastID && (filter == nil || filter(event)) {
+				missed = append(missed, event)
+			}
+		}
+	}
+	return sub, missed, complete
+}
+
+// Unsubscribe cancela la suscripción y cierra su canal
+func (b *Bus) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(sub)
+}
+
+// remove elimina al suscriptor. Debe llamarse con el mutex tomado.
+func (b *Bus) remove(sub *Subscription) {
+	if b.subscribers[sub] {
+		delete(b.subscribers, sub)
+		close(sub.ch)
+	}
+}
@@ -0,0 +1,49 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+func TestSubscribeReplaysMissedEvents(t *testing.T) {
+	bus := NewBus(3)
+	for i := 1; i <= 5; i++ {
+		bus.Publish(models.TaskEvent{Type: models.EventTaskCreated, TaskID: i})
+	}
+
+	// El evento 3 sigue en el buffer: la reanudación es completa
+	sub, missed, complete := bus.Subscribe(2, nil)
+	defer bus.Unsubscribe(sub)
+	if !complete || len(missed) != 3 || missed[0].ID != 3 {
+		t.Errorf("Reanudación incorrecta: completa=%v eventos=%+v", complete, missed)
+	}
+
+	// El evento 2 ya fue descartado: la reanudación es incompleta
+	sub2, missed, complete := bus.Subscribe(1, nil)
+	defer bus.Unsubscribe(sub2)
+	if complete || len(missed) != 3 {
+		t.Errorf("Reanudación incorrecta: completa=%v eventos=%+v", complete, missed)
+	}
+}
+
+func TestSlowSubscriberIsDisconnected(t *testing.T) {
+	bus := NewBus(10)
+	sub, _, _ := bus.Subscribe(0, func(event models.TaskEvent) bool {
+		return event.TaskID == 1
+	})
+
+	for i := 0; i < subscriberBuffer+1; i++ {
+		bus.Publish(models.TaskEvent{Type: models.EventTaskUpdated, TaskID: 1})
+	}
+
+	// El canal debe cerrarse después de los eventos en buffer
+	received := 0
+	for range sub.C {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Errorf("Se esperaban %d eventos, obtuvo %d", subscriberBuffer, received)
+	}
+	bus.Unsubscribe(sub)
+}
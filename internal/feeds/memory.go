@@ -0,0 +1,67 @@
+package feeds
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// MemoryStore guarda las suscripciones en memoria
+type MemoryStore struct {
+	mu     sync.RWMutex
+	users  map[string]string // hash del token → usuario
+	hashes map[string]string // usuario → hash del token
+}
+
+// NewMemoryStore crea un almacén de suscripciones en memoria
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:  make(map[string]string),
+		hashes: make(map[string]string),
+	}
+}
+
+// Create genera un token nuevo y revoca el anterior del usuario
+func (s *MemoryStore) Create(ctx context.Context, userID string) (models.CalendarFeed, error) {
+	token, err := newToken()
+	if err != nil {
+		return models.CalendarFeed{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.users, s.hashes[userID])
+	hash := hashToken(token)
+	s.users[hash] = userID
+	s.hashes[userID] = hash
+	return models.CalendarFeed{Token: token, CreatedAt: time.Now()}, nil
+}
+
+// Lookup devuelve el usuario dueño del token
+func (s *MemoryStore) Lookup(ctx context.Context, token string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	userID, ok := s.users[hashToken(token)]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return userID, nil
+}
+
+// Delete revoca la suscripción del usuario
+func (s *MemoryStore) Delete(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, ok := s.hashes[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(s.users, hash)
+	delete(s.hashes, userID)
+	return nil
+}
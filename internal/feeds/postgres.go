@@ -0,0 +1,60 @@
+package feeds
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// PostgresStore guarda las suscripciones en la tabla calendar_feeds
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore crea un almacén de suscripciones respaldado por PostgreSQL
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Create genera un token nuevo y reemplaza el anterior del usuario
+func (s *PostgresStore) Create(ctx context.Context, userID string) (models.CalendarFeed, error) {
+	token, err := newToken()
+	if err != nil {
+		return models.CalendarFeed{}, err
+	}
+
+	feed := models.CalendarFeed{Token: token}
+	err = s.db.QueryRowContext(ctx,
+		`INSERT INTO calendar_feeds (user_id, token_hash, created_at)
+		 VALUES ($1, $2, NOW())
+		 ON CONFLICT (user_id) DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = EXCLUDED.created_at
+		 RETURNING created_at`,
+		userID, hashToken(token),
+	).Scan(&feed.CreatedAt)
+	return feed, err
+}
+
+// Lookup devuelve el usuario dueño del token
+func (s *PostgresStore) Lookup(ctx context.Context, token string) (string, error) {
+	var userID string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT user_id FROM calendar_feeds WHERE token_hash = $1`, hashToken(token),
+	).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return userID, err
+}
+
+// Delete revoca la suscripción del usuario
+func (s *PostgresStore) Delete(ctx context.Context, userID string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM calendar_feeds WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Package feeds guarda los tokens secretos de las suscripciones iCalendar.
+// Cada usuario tiene como mucho un token; crear uno nuevo invalida el
+// anterior. Los tokens se guardan como hash SHA-256, nunca en texto plano.
+package feeds
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// ErrNotFound indica que el token no corresponde a ninguna suscripción
+var ErrNotFound = errors.New("suscripción de calendario no encontrada")
+
+// Store guarda el token de la suscripción de cada usuario
+type Store interface {
+	// Create genera un token para el usuario y reemplaza el anterior. Solo
+	// el feed devuelto contiene el token en claro.
+	Create(ctx context.Context, userID string) (models.CalendarFeed, error)
+	// Lookup devuelve el usuario dueño del token
+	Lookup(ctx context.Context, token string) (string, error)
+	// Delete revoca la suscripción del usuario
+	Delete(ctx context.Context, userID string) error
+}
+
+// newToken genera un token aleatorio codificado en hexadecimal
+func newToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken devuelve el hash con el que se guarda un token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,70 @@
+package filter
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expr es un nodo del árbol de una expresión de filtro
+type Expr interface {
+	// String devuelve la expresión en forma canónica; volver a interpretarla
+	// produce la misma expresión
+	String() string
+}
+
+// And se cumple si se cumplen ambas expresiones
+type And struct {
+	Left, Right Expr
+}
+
+// Or se cumple si se cumple alguna de las expresiones
+type Or struct {
+	Left, Right Expr
+}
+
+// Not se cumple si no se cumple la expresión
+type Not struct {
+	Expr Expr
+}
+
+// Compare compara un campo con un valor ya convertido al tipo del campo:
+// string, int, bool o time.Time. El operador ":" en campos de texto indica
+// que el campo contiene el valor, sin distinguir mayúsculas.
+type Compare struct {
+	Field string
+	Op    string
+	Value interface{}
+}
+
+func (e And) String() string { return "(" + e.Left.String() + " AND " + e.Right.String() + ")" }
+
+func (e Or) String() string { return "(" + e.Left.String() + " OR " + e.Right.String() + ")" }
+
+func (e Not) String() string { return "NOT " + e.Expr.String() }
+
+func (e Compare) String() string {
+	return e.Field + e.Op + formatValue(e.Value)
+}
+
+// formatValue escribe el valor de forma que el lexer lo lea igual
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return quote(v)
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return quote(v.UTC().Format(time.RFC3339))
+	}
+	return ""
+}
+
+// quote escribe una cadena entre comillas escapando \ y "
+func quote(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	text = strings.ReplaceAll(text, `"`, `\"`)
+	return `"` + text + `"`
+}
@@ -0,0 +1,75 @@
+package filter
+
+import (
+	"strings"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// Match evalúa la expresión sobre una tarea; lo usa el almacén en memoria
+func Match(expr Expr, task models.Task) bool {
+	switch e := expr.(type) {
+	case And:
+		return Match(e.Left, task) && Match(e.Right, task)
+	case Or:
+		return Match(e.Left, task) || Match(e.Right, task)
+	case Not:
+		return !Match(e.Expr, task)
+	case Compare:
+		return e.match(task)
+	}
+	return false
+}
+
+// match evalúa la comparación sobre el valor del campo
+func (e Compare) match(task models.Task) bool {
+	f, _ := lookupField(e.Field)
+
+	switch actual := f.value(task).(type) {
+	case string:
+		expected := e.Value.(string)
+		switch e.Op {
+		case ":":
+			return strings.Contains(strings.ToLower(actual), strings.ToLower(expected))
+		case "!=":
+			return actual != expected
+		default:
+			return actual == expected
+		}
+	case bool:
+		if e.Op == "!=" {
+			return actual != e.Value.(bool)
+		}
+		return actual == e.Value.(bool)
+	case int:
+		return compareResult(e.Op, compareInt(actual, e.Value.(int)))
+	case time.Time:
+		return compareResult(e.Op, compareTime(actual, e.Value.(time.Time)))
+	case []string:
+		found := false
+		for _, tag := range actual {
+			found = found || tag == e.Value.(string)
+		}
+		return found == (e.Op != "!=")
+	}
+	return false
+}
+
+// compareResult traduce el resultado de una comparación según el operador
+func compareResult(op string, cmp int) bool {
+	switch op {
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return cmp == 0
+	}
+}
@@ -0,0 +1,186 @@
+package filter
+
+import (
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// fieldType es el tipo de valor de un campo filtrable
+type fieldType int
+
+const (
+	typeString fieldType = iota
+	typeInt
+	typeBool
+	typeTime
+	// typeTags es una lista de etiquetas; la comparación indica si la tarea
+	// tiene la etiqueta
+	typeTags
+)
+
+// field describe un campo de models.Task que se puede usar en los filtros.
+// Los campos nullable pueden no tener valor; una comparación con un campo
+// sin valor nunca se cumple.
+type field struct {
+	name     string
+	typ      fieldType
+	column   string
+	nullable bool
+}
+
+// fields son los campos filtrables por su nombre JSON
+var fields = map[string]field{
+	"id":           {"id", typeInt, "id", false},
+	"title":        {"title", typeString, "title", false},
+	"description":  {"description", typeString, "COALESCE(description, '')", false},
+	"completed":    {"completed", typeBool, "completed", false},
+	"owner_id":     {"owner_id", typeString, "COALESCE(owner_id, '')", false},
+	"project_id":   {"project_id", typeInt, "COALESCE(project_id, 0)", false},
+	"priority":     {"priority", typeInt, "priority", false},
+	"created_at":   {"created_at", typeTime, "created_at", false},
+	"updated_at":   {"updated_at", typeTime, "updated_at", false},
+	"completed_at": {"completed_at", typeTime, "completed_at", true},
+	"due_date":     {"due_date", typeTime, "due_date", true},
+	"tags":         {"tags", typeTags, "tags", false},
+}
+
+// value devuelve el valor del campo en la tarea, o nil si un campo nullable
+// no tiene valor
+func (f field) value(task models.Task) interface{} {
+	switch f.name {
+	case "id":
+		return task.ID
+	case "title":
+		return task.Title
+	case "description":
+		return task.Description
+	case "completed":
+		return task.Completed
+	case "owner_id":
+		return task.OwnerID
+	case "project_id":
+		return task.ProjectID
+	case "priority":
+		return task.Priority
+	case "created_at":
+		return task.CreatedAt
+	case "completed_at":
+		return optionalTime(task.CompletedAt)
+	case "due_date":
+		return optionalTime(task.DueDate)
+	case "tags":
+		return task.Tags
+	default:
+		return task.UpdatedAt
+	}
+}
+
+func optionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+// aliases son nombres cortos aceptados para algunos campos
+var aliases = map[string]string{
+	"owner":   "owner_id",
+	"project": "project_id",
+	"created": "created_at",
+	"updated": "updated_at",
+	"due":     "due_date",
+	"tag":     "tags",
+}
+
+// lookupField busca un campo por su nombre o alias
+func lookupField(name string) (field, bool) {
+	if canonical, ok := aliases[name]; ok {
+		name = canonical
+	}
+	f, ok := fields[name]
+	return f, ok
+}
+
+// allowedOps devuelve los operadores válidos para el tipo de campo
+func allowedOps(typ fieldType) []string {
+	switch typ {
+	case typeString:
+		return []string{":", "=", "!="}
+	case typeTime:
+		return []string{"<", "<=", ">", ">="}
+	case typeBool, typeTags:
+		return []string{":", "=", "!="}
+	default:
+		return []string{":", "=", "!=", "<", "<=", ">", ">="}
+	}
+}
+
+// compareTime y compareInt devuelven -1, 0 o 1
+func compareTime(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Value devuelve el valor de un campo de la tarea por su nombre o alias;
+// lo usan las vistas guardadas para ordenar y agrupar. Las etiquetas no
+// tienen un único valor y no se pueden usar.
+func Value(name string, task models.Task) (interface{}, bool) {
+	f, ok := lookupField(name)
+	if !ok || f.typ == typeTags {
+		return nil, false
+	}
+	return f.value(task), true
+}
+
+// CompareValues compara dos valores devueltos por Value para el mismo campo
+// y devuelve -1, 0 o 1. Los campos sin valor van después de los demás.
+func CompareValues(a, b interface{}) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return 1
+		}
+		return -1
+	}
+	switch x := a.(type) {
+	case int:
+		return compareInt(x, b.(int))
+	case time.Time:
+		return compareTime(x, b.(time.Time))
+	case bool:
+		y := b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		}
+		return 1
+	case string:
+		switch y := b.(string); {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,111 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+var (
+	now = time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	due = now.Add(48 * time.Hour)
+)
+
+func TestParseAndMatch(t *testing.T) {
+	tasks := []models.Task{
+		{ID: 1, Title: "Informe mensual", Completed: false, ProjectID: 7, CreatedAt: now.Add(-2 * 24 * time.Hour), Tags: []string{"work"}},
+		{ID: 2, Title: "Comprar café", Completed: true, ProjectID: 7, CreatedAt: now.Add(-30 * 24 * time.Hour)},
+		{ID: 3, Title: "Revisar informe", Description: "urgente", OwnerID: "ana", CreatedAt: now, DueDate: &due, Priority: 1, Tags: []string{"urgent", "home"}},
+	}
+
+	tests := []struct {
+		filter string
+		want   []int
+	}{
+		{`title:informe`, []int{1, 3}},
+		{`NOT completed`, []int{1, 3}},
+		{`completed`, []int{2}},
+		{`project=7 AND NOT completed`, []int{1}},
+		{`(project:7 OR owner:ana) created>-7d`, []int{1, 3}},
+		{`title:informe AND (description:urgente OR id<2)`, []int{1, 3}},
+		{`title="Comprar café"`, []int{2}},
+		{`created<2024-06-01`, []int{2}},
+		{`id>=2 and id!=3`, []int{2}},
+		{`due<7d AND NOT completed`, []int{3}},
+		{`NOT due<7d`, []int{1, 2}},
+		{`priority=1 OR priority>5`, []int{3}},
+		{`(tag:work OR tag:urgent) AND due<7d`, []int{3}},
+		{`tag:WORK OR tag=home`, []int{1, 3}},
+		{`tag!=urgent`, []int{1, 2}},
+	}
+
+	for _, test := range tests {
+		expr, err := Parse(test.filter, now)
+		if err != nil {
+			t.Errorf("%s: error inesperado: %v", test.filter, err)
+			continue
+		}
+		got := []int{}
+		for _, task := range tasks {
+			if Match(expr, task) {
+				got = append(got, task.ID)
+			}
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: obtuvo %v, esperaba %v", test.filter, got, test.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	invalid := []string{
+		``,
+		`label:work`,
+		`tag<work`,
+		`title`,
+		`completed:tal vez`,
+		`created:2024-01-01`,
+		`id<abc`,
+		`(title:a`,
+		`title:"sin cerrar`,
+		`title:a OR`,
+		`AND title:a`,
+		`title:a )`,
+	}
+	for _, input := range invalid {
+		if _, err := Parse(input, now); err == nil {
+			t.Errorf("%q: se esperaba un error", input)
+		}
+	}
+}
+
+func TestSQL(t *testing.T) {
+	expr, err := Parse(`(title:"50%" OR project=7) AND NOT completed`, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	where, args := SQL(expr, 2)
+	want := `((title ILIKE $3 ESCAPE '\' OR COALESCE(project_id, 0) = $4) AND (NOT completed = $5))`
+	if where != want {
+		t.Errorf("SQL incorrecto:\n obtuvo %s\n esperaba %s", where, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{`%50\%%`, 7, true}) {
+		t.Errorf("Parámetros incorrectos: %#v", args)
+	}
+
+	// Las fechas opcionales sin valor no cumplen la comparación ni su negación
+	expr, _ = Parse(`NOT due<2024-06-01`, now)
+	if where, _ := SQL(expr, 0); where != `(NOT (due_date IS NOT NULL AND due_date < $1))` {
+		t.Errorf("SQL incorrecto: %s", where)
+	}
+
+	// Las etiquetas se comparan en minúsculas con el operador de contención
+	expr, _ = Parse(`tag:Work OR tag!=home`, now)
+	where, args = SQL(expr, 0)
+	if where != `(tags @> ARRAY[$1]::TEXT[] OR (NOT tags @> ARRAY[$2]::TEXT[]))` || !reflect.DeepEqual(args, []interface{}{"work", "home"}) {
+		t.Errorf("SQL incorrecto: %s %#v", where, args)
+	}
+}
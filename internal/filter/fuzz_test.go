@@ -0,0 +1,45 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzParse(f *testing.F) {
+	seeds := []string{
+		`(project:7 OR owner:ana) AND created>-7d AND NOT completed`,
+		`title:"con \"comillas\" y \\"`,
+		`id>=2 and id!=3`,
+		`NOT NOT (completed)`,
+		`created<"2024-01-01T10:00:00Z"`,
+		`title:café description:"lista de"`,
+		`((((`,
+		`title:`,
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		expr, err := Parse(input, now)
+		if err != nil {
+			return
+		}
+
+		// La forma canónica debe interpretarse igual
+		canonical := expr.String()
+		reparsed, err := Parse(canonical, now)
+		if err != nil {
+			t.Fatalf("La forma canónica %q de %q no se puede interpretar: %v", canonical, input, err)
+		}
+		if reparsed.String() != canonical {
+			t.Fatalf("La forma canónica cambió: %q -> %q", canonical, reparsed.String())
+		}
+
+		// Cada parámetro de la consulta SQL tiene su marcador
+		where, args := SQL(expr, 0)
+		if strings.Count(where, "$") != len(args) {
+			t.Fatalf("%q: %d marcadores para %d parámetros", where, strings.Count(where, "$"), len(args))
+		}
+	})
+}
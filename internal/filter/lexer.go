@@ -0,0 +1,123 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenKind es el tipo de un token del lenguaje de filtros
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOp
+	tokenLParen
+	tokenRParen
+)
+
+// token es una unidad léxica con su posición en la expresión
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// Error describe un error de sintaxis o de validación en la posición indicada
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("filtro inválido en la posición %d: %s", e.Pos, e.Msg)
+}
+
+// operators son los operadores de comparación, los de dos caracteres primero
+var operators = []string{"!=", "<=", ">=", ":", "=", "<", ">"}
+
+// lex divide la expresión en tokens
+func lex(input string) ([]token, error) {
+	tokens := []token{}
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case c == '"':
+			text, next, err := lexString(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: i})
+			i = next
+		default:
+			if op := matchOperator(input[i:]); op != "" {
+				tokens = append(tokens, token{kind: tokenOp, text: op, pos: i})
+				i += len(op)
+				continue
+			}
+			start := i
+			for i < len(input) && isWordByte(input[i]) {
+				i++
+			}
+			if i == start {
+				return nil, &Error{Pos: i, Msg: fmt.Sprintf("carácter inesperado %q", input[i])}
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: input[start:i], pos: start})
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+// lexString lee una cadena entre comillas dobles; \" y \\ se escapan
+func lexString(input string, start int) (string, int, error) {
+	var b strings.Builder
+	i := start + 1
+	for i < len(input) {
+		switch input[i] {
+		case '\\':
+			if i+1 < len(input) {
+				b.WriteByte(input[i+1])
+				i += 2
+				continue
+			}
+			i++
+		case '"':
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(input[i])
+			i++
+		}
+	}
+	return "", 0, &Error{Pos: start, Msg: "cadena sin cerrar"}
+}
+
+// matchOperator devuelve el operador al comienzo del texto, si lo hay
+func matchOperator(text string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(text, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// isWordByte indica si el byte puede formar parte de una palabra. Los bytes
+// no ASCII se aceptan para permitir valores con acentos sin comillas.
+func isWordByte(c byte) bool {
+	if c >= 0x80 {
+		return true
+	}
+	r := rune(c)
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-.+*/@", r)
+}
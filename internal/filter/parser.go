@@ -0,0 +1,266 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxLength es la longitud máxima aceptada para una expresión
+	maxLength = 1000
+	// maxDepth limita el anidamiento de paréntesis y NOT
+	maxDepth = 32
+)
+
+// durationPattern reconoce duraciones relativas como 7d, -12h o 2w
+var durationPattern = regexp.MustCompile(`^(-?)(\d{1,4})([mhdw])$`)
+
+// parser interpreta una lista de tokens por descenso recursivo:
+//
+//	expr       = and { "OR" and }
+//	and        = unary { ["AND"] unary }
+//	unary      = "NOT" unary | primary
+//	primary    = "(" expr ")" | comparison | campo booleano
+//	comparison = campo operador valor
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+	now    time.Time
+}
+
+// Parse interpreta una expresión de filtro y valida los campos, operadores
+// y valores contra models.Task. Las duraciones relativas (7d, -2w) se
+// resuelven respecto a now.
+func Parse(input string, now time.Time) (Expr, error) {
+	if len(input) > maxLength {
+		return nil, &Error{Pos: maxLength, Msg: fmt.Sprintf("la expresión supera los %d caracteres", maxLength)}
+	}
+
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, now: now}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, &Error{Pos: next.pos, Msg: fmt.Sprintf("token inesperado %q", next.text)}
+	}
+	return expr, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// isKeyword indica si el token es la palabra clave indicada, sin distinguir mayúsculas
+func isKeyword(t token, keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for isKeyword(p.peek(), "OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		next := p.peek()
+		switch {
+		case isKeyword(next, "AND"):
+			p.next()
+		case next.kind == tokenLParen || (next.kind == tokenWord && !isKeyword(next, "OR")):
+			// Dos condiciones seguidas se combinan con AND
+		default:
+			return left, nil
+		}
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = And{Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return nil, &Error{Pos: p.peek().pos, Msg: "la expresión está demasiado anidada"}
+	}
+
+	if isKeyword(p.peek(), "NOT") {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Expr: expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, &Error{Pos: closing.pos, Msg: "falta cerrar el paréntesis"}
+		}
+		return expr, nil
+	case tokenWord:
+		if isKeyword(t, "AND") || isKeyword(t, "OR") {
+			return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf("se esperaba una condición antes de %s", strings.ToUpper(t.text))}
+		}
+		return p.parseComparison(t)
+	case tokenEOF:
+		return nil, &Error{Pos: t.pos, Msg: "la expresión termina de forma inesperada"}
+	default:
+		return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf("token inesperado %q", t.text)}
+	}
+}
+
+// parseComparison interpreta "campo operador valor" o un campo booleano solo
+func (p *parser) parseComparison(name token) (Expr, error) {
+	f, ok := lookupField(strings.ToLower(name.text))
+	if !ok {
+		return nil, &Error{Pos: name.pos, Msg: fmt.Sprintf("campo desconocido %q", name.text)}
+	}
+
+	op := p.peek()
+	if op.kind != tokenOp {
+		// Un campo booleano solo equivale a campo=true
+		if f.typ == typeBool {
+			return Compare{Field: f.name, Op: "=", Value: true}, nil
+		}
+		return nil, &Error{Pos: op.pos, Msg: fmt.Sprintf("se esperaba un operador después de %s", f.name)}
+	}
+	p.next()
+
+	if !containsString(allowedOps(f.typ), op.text) {
+		return nil, &Error{Pos: op.pos, Msg: fmt.Sprintf("el operador %s no se puede usar con %s", op.text, f.name)}
+	}
+
+	value := p.next()
+	if value.kind != tokenWord && value.kind != tokenString {
+		return nil, &Error{Pos: value.pos, Msg: fmt.Sprintf("se esperaba un valor para %s", f.name)}
+	}
+
+	converted, err := p.convert(f, value)
+	if err != nil {
+		return nil, err
+	}
+
+	// ":" solo significa "contiene" en los campos de texto; en las etiquetas
+	// significa "tiene la etiqueta", como "="
+	normalized := op.text
+	if normalized == ":" && f.typ != typeString {
+		normalized = "="
+	}
+	return Compare{Field: f.name, Op: normalized, Value: converted}, nil
+}
+
+// convert convierte el valor al tipo del campo
+func (p *parser) convert(f field, value token) (interface{}, error) {
+	invalid := func(expected string) error {
+		return &Error{Pos: value.pos, Msg: fmt.Sprintf("%s espera %s, se recibió %q", f.name, expected, value.text)}
+	}
+
+	switch f.typ {
+	case typeInt:
+		n, err := strconv.Atoi(value.text)
+		if err != nil {
+			return nil, invalid("un número entero")
+		}
+		return n, nil
+	case typeBool:
+		b, err := strconv.ParseBool(strings.ToLower(value.text))
+		if err != nil {
+			return nil, invalid("true o false")
+		}
+		return b, nil
+	case typeTime:
+		if m := durationPattern.FindStringSubmatch(value.text); m != nil {
+			return relativeTime(p.now, m[1] == "-", m[2], m[3]), nil
+		}
+		if t, err := time.Parse("2006-01-02", value.text); err == nil {
+			return t, nil
+		}
+		if t, err := time.Parse(time.RFC3339, value.text); err == nil {
+			return t.UTC().Truncate(time.Second), nil
+		}
+		return nil, invalid("una fecha (2006-01-02, RFC3339 entre comillas) o una duración como 7d")
+	case typeTags:
+		// Las etiquetas se guardan en minúsculas
+		return strings.ToLower(strings.TrimSpace(value.text)), nil
+	default:
+		return value.text, nil
+	}
+}
+
+// relativeTime suma a now la duración indicada. Los días y las semanas se
+// suman como fechas de calendario.
+func relativeTime(now time.Time, negative bool, amount, unit string) time.Time {
+	n, _ := strconv.Atoi(amount)
+	if negative {
+		n = -n
+	}
+
+	var t time.Time
+	switch unit {
+	case "m":
+		t = now.Add(time.Duration(n) * time.Minute)
+	case "h":
+		t = now.Add(time.Duration(n) * time.Hour)
+	case "d":
+		t = now.AddDate(0, 0, n)
+	default:
+		t = now.AddDate(0, 0, 7*n)
+	}
+	return t.UTC().Truncate(time.Second)
+}
+
+// containsString indica si la lista contiene el valor
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
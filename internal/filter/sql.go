@@ -0,0 +1,66 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SQL compila la expresión a una condición WHERE parametrizada para
+// PostgreSQL. Los parámetros se numeran a partir de $offset+1 y los valores
+// nunca se interpolan en el texto de la consulta.
+func SQL(expr Expr, offset int) (string, []interface{}) {
+	c := &compiler{offset: offset}
+	return c.compile(expr), c.args
+}
+
+// compiler acumula los parámetros de la consulta
+type compiler struct {
+	offset int
+	args   []interface{}
+}
+
+func (c *compiler) compile(expr Expr) string {
+	switch e := expr.(type) {
+	case And:
+		return "(" + c.compile(e.Left) + " AND " + c.compile(e.Right) + ")"
+	case Or:
+		return "(" + c.compile(e.Left) + " OR " + c.compile(e.Right) + ")"
+	case Not:
+		return "(NOT " + c.compile(e.Expr) + ")"
+	case Compare:
+		f, _ := lookupField(e.Field)
+		if f.typ == typeTags {
+			// @> usa el índice GIN de las etiquetas
+			has := fmt.Sprintf("%s @> ARRAY[%s]::TEXT[]", f.column, c.arg(e.Value))
+			if e.Op == "!=" {
+				return "(NOT " + has + ")"
+			}
+			return has
+		}
+		if e.Op == ":" {
+			return fmt.Sprintf(`%s ILIKE %s ESCAPE '\'`, f.column, c.arg("%"+escapeLike(e.Value.(string))+"%"))
+		}
+		op := e.Op
+		if op == "!=" {
+			op = "<>"
+		}
+		if f.nullable {
+			// Sin el IS NOT NULL, NOT de una comparación con NULL tampoco se
+			// cumpliría, a diferencia de Match
+			return fmt.Sprintf("(%s IS NOT NULL AND %s %s %s)", f.column, f.column, op, c.arg(e.Value))
+		}
+		return fmt.Sprintf("%s %s %s", f.column, op, c.arg(e.Value))
+	}
+	return "FALSE"
+}
+
+// arg agrega un parámetro y devuelve su marcador
+func (c *compiler) arg(value interface{}) string {
+	c.args = append(c.args, value)
+	return fmt.Sprintf("$%d", c.offset+len(c.args))
+}
+
+// escapeLike escapa los comodines de LIKE
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/claudio/todo-api/internal/audit"
+	"github.com/claudio/todo-api/internal/auth"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditHandler expone la consulta y exportación del registro de auditoría
+type AuditHandler struct {
+	store audit.Store
+}
+
+// NewAuditHandler crea una nueva instancia de AuditHandler
+func NewAuditHandler(store audit.Store) *AuditHandler {
+	return &AuditHandler{store: store}
+}
+
+// GetAudit devuelve los registros de auditoría del usuario que cumplen los filtros
+func (h *AuditHandler) GetAudit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	filter, err := parseAuditFilter(r)
+	if err == errAuditForbidden {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	}
+
+	records, err := h.store.List(r.Context(), filter)
+	if err != nil {
+		log.Printf("Error al consultar la auditoría: %v", err)
+		http.Error(w, "Error al consultar la auditoría", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(records)
+}
+
+// ExportAudit transmite los registros de auditoría del usuario en formato JSON Lines
+func (h *AuditHandler) ExportAudit(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r)
+	if err == errAuditForbidden {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.jsonl"`)
+
+	// Leer por páginas para no cargar todo el registro en memoria
+	encoder := json.NewEncoder(w)
+	page := filter
+	page.Limit = maxAuditLimit
+	for {
+		records, err := h.store.List(r.Context(), page)
+		if err != nil {
+			log.Printf("Error al exportar la auditoría: %v", err)
+			return
+		}
+		for _, record := range records {
+			encoder.Encode(record)
+		}
+		if len(records) < page.Limit {
+			return
+		}
+		page.AfterID = records[len(records)-1].ID
+	}
+}
+
+// errAuditForbidden indica que se pidieron registros de otro usuario
+var errAuditForbidden = errors.New("solo se pueden consultar los registros propios")
+
+// parseAuditFilter lee los filtros de auditoría de la query string. Los
+// registros siempre se limitan a los cambios hechos por el usuario autenticado.
+func parseAuditFilter(r *http.Request) (audit.Filter, error) {
+	query := r.URL.Query()
+	identity, _ := auth.FromContext(r.Context())
+	filter := audit.Filter{
+		Actor:  identity.UserID,
+		Action: query.Get("action"),
+	}
+	if actor := query.Get("actor"); actor != "" && actor != identity.UserID {
+		return filter, errAuditForbidden
+	}
+
+	var err error
+	if value := query.Get("task_id"); value != "" {
+		if filter.TaskID, err = strconv.Atoi(value); err != nil {
+			return filter, errors.New("task_id inválido")
+		}
+	}
+	if value := query.Get("after_id"); value != "" {
+		if filter.AfterID, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return filter, errors.New("after_id inválido")
+		}
+	}
+	if value := query.Get("from"); value != "" {
+		if filter.From, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, errors.New("from debe tener formato RFC3339")
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		if filter.To, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, errors.New("to debe tener formato RFC3339")
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		filter.Limit, err = strconv.Atoi(value)
+		if err != nil || filter.Limit < 1 || filter.Limit > maxAuditLimit {
+			return filter, errors.New("limit debe estar entre 1 y 1000")
+		}
+	}
+	return filter, nil
+}
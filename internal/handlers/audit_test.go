@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/claudio/todo-api/internal/audit"
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/gorilla/mux"
+)
+
+func TestUpdateTaskWritesAuditRecord(t *testing.T) {
+	store := audit.NewMemoryStore()
+	taskHandler := NewTaskHandler(WithAuditStore(store))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/tasks/{id:[0-9]+}", taskHandler.UpdateTask).Methods("PUT")
+
+	body := []byte(`{"title":"Ejemplo de tarea 1","description":"Esta es una tarea de ejemplo predefinida","completed":true}`)
+	req, _ := http.NewRequest("PUT", "/api/tasks/1", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusOK)
+	}
+
+	records, _ := store.List(context.Background(), audit.Filter{TaskID: 1})
+	if len(records) != 1 {
+		t.Fatalf("Se esperaba 1 registro de auditoría, obtuvo %d", len(records))
+	}
+
+	record := records[0]
+	if record.Action != models.ActionUpdate || record.Actor != "anonymous" {
+		t.Errorf("Registro incorrecto: %+v", record)
+	}
+	if len(record.Changes) != 1 || record.Changes[0].Field != "completed" {
+		t.Errorf("Cambios incorrectos: %+v", record.Changes)
+	}
+}
+
+func TestGetAuditOnlyReturnsOwnRecords(t *testing.T) {
+	store := audit.NewMemoryStore()
+	taskHandler := NewTaskHandler(WithAuditStore(store))
+	auditHandler := NewAuditHandler(store)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/tasks", taskHandler.CreateTask).Methods("POST")
+	router.HandleFunc("/api/audit", auditHandler.GetAudit).Methods("GET")
+
+	do := func(user, method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req = req.WithContext(auth.WithIdentity(req.Context(), auth.Identity{UserID: user}))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	do("ana", "POST", "/api/tasks", `{"title": "De Ana"}`)
+	do("luis", "POST", "/api/tasks", `{"title": "De Luis"}`)
+
+	var records []models.AuditRecord
+	json.Unmarshal(do("luis", "GET", "/api/audit", "").Body.Bytes(), &records)
+	if len(records) != 1 || records[0].Actor != "luis" {
+		t.Errorf("Se esperaba solo el registro de luis, obtuvo %+v", records)
+	}
+
+	if rr := do("luis", "GET", "/api/audit?actor=ana", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/middleware"
+	"github.com/gorilla/mux"
+)
+
+// AuthHandler maneja el inicio de sesión, la rotación de tokens y las sesiones
+type AuthHandler struct {
+	users    *auth.UserStore
+	sessions auth.SessionStore
+}
+
+// NewAuthHandler crea una nueva instancia de AuthHandler
+func NewAuthHandler(users *auth.UserStore, sessions auth.SessionStore) *AuthHandler {
+	return &AuthHandler{
+		users:    users,
+		sessions: sessions,
+	}
+}
+
+// Login valida las credenciales e inicia una nueva sesión
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var credentials struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+		http.Error(w, "Error al decodificar JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !h.users.Verify(credentials.Username, credentials.Password) {
+		http.Error(w, "Credenciales inválidas", http.StatusUnauthorized)
+		return
+	}
+
+	tokens, err := h.sessions.CreateSession(r.Context(), credentials.Username, r.UserAgent(), middleware.ClientIP(r))
+	if err != nil {
+		log.Printf("Error al crear la sesión: %v", err)
+		http.Error(w, "Error al crear la sesión", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// Refresh intercambia un token de refresco por un nuevo par de tokens
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Error al decodificar JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := h.sessions.Refresh(r.Context(), body.RefreshToken)
+	if err == auth.ErrInvalidToken || err == auth.ErrTokenReused {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		log.Printf("Error al refrescar el token: %v", err)
+		http.Error(w, "Error al refrescar el token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// Logout revoca la sesión asociada al token de acceso actual
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	identity, _ := auth.FromContext(r.Context())
+
+	if err := h.sessions.RevokeSession(r.Context(), identity.UserID, identity.SessionID); err != nil {
+		h.writeRevokeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSessions devuelve las sesiones activas del usuario autenticado
+func (h *AuthHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	identity, _ := auth.FromContext(r.Context())
+
+	sessions, err := h.sessions.ListSessions(r.Context(), identity.UserID)
+	if err != nil {
+		log.Printf("Error al listar las sesiones: %v", err)
+		http.Error(w, "Error al listar las sesiones", http.StatusInternalServerError)
+		return
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == identity.SessionID
+	}
+
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// RevokeSession revoca una sesión del usuario autenticado
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	identity, _ := auth.FromContext(r.Context())
+
+	params := mux.Vars(r)
+	if err := h.sessions.RevokeSession(r.Context(), identity.UserID, params["id"]); err != nil {
+		h.writeRevokeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeRevokeError responde 404 si la sesión no existe y 500 ante otros errores
+func (h *AuthHandler) writeRevokeError(w http.ResponseWriter, err error) {
+	if err == auth.ErrSessionNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	log.Printf("Error al revocar la sesión: %v", err)
+	http.Error(w, "Error al revocar la sesión", http.StatusInternalServerError)
+}
@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/claudio/todo-api/internal/apiversion"
+	"github.com/claudio/todo-api/internal/filter"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/store"
+)
+
+// Modos de ejecución de una operación masiva
+const (
+	// bulkAtomic aplica todas las operaciones o ninguna
+	bulkAtomic = "atomic"
+	// bulkBestEffort aplica cada operación por separado
+	bulkBestEffort = "best_effort"
+)
+
+// maxBulkOperations es el máximo de operaciones aceptadas en una solicitud
+const maxBulkOperations = 1000
+
+// Acciones disponibles para las operaciones masivas por filtro
+const (
+	bulkActionComplete   = "complete"
+	bulkActionUncomplete = "uncomplete"
+	bulkActionDelete     = "delete"
+)
+
+// errBulkList indica que no se pudieron obtener las tareas que cumplen el filtro
+var errBulkList = errors.New("Error al listar las tareas")
+
+// errBulkSkipped indica que la operación no se aplicó porque otra del lote falló
+var errBulkSkipped = errors.New("no aplicada: otra operación del lote falló")
+
+// bulkOperation es una operación individual dentro de un lote: create,
+// update (reemplaza la tarea), delete, complete o uncomplete. RawTask está
+// en la representación de la versión de la API y se interpreta en Task.
+type bulkOperation struct {
+	Op      string          `json:"op"`
+	ID      int             `json:"id,omitempty"`
+	RawTask json.RawMessage `json:"task,omitempty"`
+	Task    *models.Task    `json:"-"`
+}
+
+// bulkRequest es el cuerpo de POST /api/tasks/bulk. Se indica una lista de
+// operaciones o bien un filtro con una acción. El filtro usa el mismo
+// lenguaje de expresiones que GET /api/tasks?filter=.
+type bulkRequest struct {
+	Mode       string          `json:"mode"`
+	Operations []bulkOperation `json:"operations,omitempty"`
+	Filter     *string         `json:"filter,omitempty"`
+	Action     string          `json:"action,omitempty"`
+}
+
+// bulkResult es el resultado de una operación del lote
+type bulkResult struct {
+	Index  int          `json:"index"`
+	Op     string       `json:"op"`
+	ID     int          `json:"id,omitempty"`
+	Status int          `json:"status"`
+	Error  string       `json:"error,omitempty"`
+	Task   *models.Task `json:"task,omitempty"`
+}
+
+// bulkResponse resume el resultado del lote
+type bulkResponse struct {
+	Mode      string       `json:"mode"`
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+	Results   []bulkResult `json:"results"`
+}
+
+// encode devuelve la respuesta con las tareas en la representación de la versión
+func (r bulkResponse) encode(codec apiversion.TaskCodec) interface{} {
+	results := make([]interface{}, len(r.Results))
+	for i, result := range r.Results {
+		results[i] = struct {
+			bulkResult
+			Task interface{} `json:"task,omitempty"`
+		}{result, apiversion.EncodeTaskPtr(codec, result.Task)}
+	}
+	return struct {
+		bulkResponse
+		Results []interface{} `json:"results"`
+	}{r, results}
+}
+
+// bulkChange es una modificación aplicada dentro de la transacción del lote
+type bulkChange struct {
+	action        string
+	before, after *models.Task
+}
+
+// BulkTasks aplica un lote de operaciones de creación, actualización y
+// eliminación. En modo atomic (el predeterminado) si una operación falla no
+// se aplica ninguna y se responde 422; en modo best_effort cada operación se
+// aplica por separado y se informa su resultado.
+func (h *TaskHandler) BulkTasks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req bulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = bulkAtomic
+	}
+	codec := taskCodec(r)
+	for i, operation := range req.Operations {
+		if len(operation.RawTask) == 0 || string(operation.RawTask) == "null" {
+			continue
+		}
+		task, err := codec.DecodeTask(operation.RawTask)
+		if err != nil {
+			http.Error(w, "Error al decodificar la tarea de la operación "+strconv.Itoa(i)+": "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Operations[i].Task = &task
+	}
+	if req.Mode != bulkAtomic && req.Mode != bulkBestEffort {
+		http.Error(w, "Modo inválido: debe ser atomic o best_effort", http.StatusBadRequest)
+		return
+	}
+
+	source := sourceFromRequest(r)
+	operations := req.Operations
+	if req.Filter != nil {
+		if len(req.Operations) > 0 {
+			http.Error(w, "Se deben indicar operaciones o un filtro, no ambos", http.StatusBadRequest)
+			return
+		}
+		var err error
+		operations, err = h.filterOperations(r, source, *req.Filter, req.Action)
+		if err == errBulkList {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if len(operations) > maxBulkOperations {
+		http.Error(w, "Se aceptan como máximo "+strconv.Itoa(maxBulkOperations)+" operaciones por lote", http.StatusBadRequest)
+		return
+	}
+
+	response := bulkResponse{Mode: req.Mode}
+	if req.Mode == bulkAtomic {
+		response.Results = h.applyBulkAtomic(r, source, operations)
+	} else {
+		response.Results = h.applyBulkBestEffort(r, source, operations)
+	}
+	for _, result := range response.Results {
+		if result.Status < 300 {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+
+	if req.Mode == bulkAtomic && response.Failed > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(response.encode(codec))
+}
+
+// applyBulkAtomic aplica todas las operaciones en una única transacción
+func (h *TaskHandler) applyBulkAtomic(r *http.Request, source changeSource, operations []bulkOperation) []bulkResult {
+	results := make([]bulkResult, len(operations))
+	var changes []bulkChange
+
+	err := h.store.Tx(r.Context(), func(tx store.Tx) error {
+		for i, operation := range operations {
+			action := bulkAction(operation.Op)
+			before, after, err := applyBulkOperation(tx, source, operation)
+			results[i] = bulkOperationResult(i, operation, after, err)
+			if err != nil {
+				// Las operaciones restantes no se aplican
+				for j := i + 1; j < len(operations); j++ {
+					results[j] = bulkOperationResult(j, operations[j], nil, errBulkSkipped)
+				}
+				return err
+			}
+			if err := tx.AddOutbox(changeEvent(source, action, before, after)); err != nil {
+				return err
+			}
+			changes = append(changes, bulkChange{action: action, before: before, after: after})
+		}
+		// La auditoría y las revisiones se escriben al final, cuando ya no
+		// puede fallar ninguna operación
+		for _, change := range changes {
+			if err := h.writeChange(tx, source, change.action, change.before, change.after); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// Ninguna operación quedó aplicada
+		for i := range results {
+			if results[i].Status < 300 {
+				results[i].Status = http.StatusConflict
+				results[i].Error = errBulkSkipped.Error()
+				results[i].Task = nil
+			}
+		}
+		return results
+	}
+	return results
+}
+
+// applyBulkBestEffort aplica cada operación en su propia transacción
+func (h *TaskHandler) applyBulkBestEffort(r *http.Request, source changeSource, operations []bulkOperation) []bulkResult {
+	results := make([]bulkResult, len(operations))
+	for i, operation := range operations {
+		after, err := h.mutate(r.Context(), source, bulkAction(operation.Op), func(tx store.Tx) (*models.Task, *models.Task, error) {
+			return applyBulkOperation(tx, source, operation)
+		})
+		results[i] = bulkOperationResult(i, operation, after, err)
+	}
+	return results
+}
+
+// applyBulkOperation aplica una operación dentro de la transacción
+func applyBulkOperation(tx store.Tx, source changeSource, operation bulkOperation) (*models.Task, *models.Task, error) {
+	// Solo se modifican las tareas visibles para el usuario
+	check := visibleCheck(source)
+
+	var before, after *models.Task
+	var err error
+	switch operation.Op {
+	case "create":
+		if operation.Task == nil {
+			return nil, nil, errTitleRequired
+		}
+		before, after, err = txCreate(tx, source, *operation.Task)
+	case "update":
+		if operation.Task == nil {
+			return nil, nil, errors.New("la tarea es obligatoria")
+		}
+		before, after, err = txUpdate(tx, operation.ID, *operation.Task, check)
+	case "delete":
+		before, after, err = txDelete(tx, operation.ID, check)
+	case bulkActionComplete, bulkActionUncomplete:
+		before, after, err = txSetCompleted(tx, operation.ID, operation.Op == bulkActionComplete, check)
+	default:
+		err = errors.New("operación desconocida: " + operation.Op)
+	}
+	return before, after, err
+}
+
+// txSetCompleted marca una tarea como completada o pendiente sin modificar
+// el resto de sus campos
+func txSetCompleted(tx store.Tx, id int, completed bool, check taskCheck) (*models.Task, *models.Task, error) {
+	before, err := txGetActive(tx, id, check)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	task := before
+	task.Completed = completed
+	task.UpdatedAt = time.Now()
+	stampCompletion(&task, &before, task.UpdatedAt)
+	if err := tx.Save(&task); err != nil {
+		return nil, nil, err
+	}
+	return &before, &task, nil
+}
+
+// bulkAction traduce el tipo de operación a la acción de auditoría
+func bulkAction(op string) string {
+	switch op {
+	case "create":
+		return models.ActionCreate
+	case "delete":
+		return models.ActionDelete
+	default:
+		return models.ActionUpdate
+	}
+}
+
+// bulkOperationResult construye el resultado de una operación
+func bulkOperationResult(index int, operation bulkOperation, task *models.Task, err error) bulkResult {
+	result := bulkResult{Index: index, Op: operation.Op, ID: operation.ID}
+	switch err {
+	case nil:
+		result.Status = http.StatusOK
+		if operation.Op == "create" {
+			result.Status = http.StatusCreated
+			result.ID = task.ID
+		}
+		if operation.Op != "delete" {
+			result.Task = task
+		}
+		return result
+	case errTaskNotFound:
+		result.Status = http.StatusNotFound
+	case errBulkSkipped:
+		result.Status = http.StatusConflict
+	default:
+		result.Status = http.StatusBadRequest
+	}
+	result.Error = err.Error()
+	return result
+}
+
+// filterOperations convierte una acción por filtro en la lista de
+// operaciones sobre las tareas visibles que cumplen la expresión
+func (h *TaskHandler) filterOperations(r *http.Request, source changeSource, expression, action string) ([]bulkOperation, error) {
+	if action != bulkActionComplete && action != bulkActionUncomplete && action != bulkActionDelete {
+		return nil, errors.New("Acción inválida: debe ser complete, uncomplete o delete")
+	}
+	// Un filtro vacío seleccionaría todas las tareas
+	if strings.TrimSpace(expression) == "" {
+		return nil, errors.New("El filtro no puede estar vacío")
+	}
+
+	tasks, err := h.listVisible(r.Context(), source.userID, expression)
+	if filterErr, ok := err.(*filter.Error); ok {
+		return nil, filterErr
+	}
+	if err != nil {
+		log.Printf("Error al listar las tareas para la operación masiva: %v", err)
+		return nil, errBulkList
+	}
+
+	operations := []bulkOperation{}
+	for _, task := range tasks {
+		// Las tareas que ya están en el estado pedido no se modifican
+		if action != bulkActionDelete && task.Completed == (action == bulkActionComplete) {
+			continue
+		}
+		operations = append(operations, bulkOperation{Op: action, ID: task.ID})
+	}
+	return operations, nil
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestBulkTasks(t *testing.T) {
+	taskHandler := NewTaskHandler()
+	router := mux.NewRouter()
+	router.HandleFunc("/api/tasks/bulk", taskHandler.BulkTasks).Methods("POST")
+
+	do := func(body string) (*httptest.ResponseRecorder, bulkResponse) {
+		req, _ := http.NewRequest("POST", "/api/tasks/bulk", bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		var response bulkResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		return rr, response
+	}
+
+	// En modo atómico un error revierte todo el lote
+	rr, response := do(`{"operations": [
+		{"op": "create", "task": {"title": "Nueva"}},
+		{"op": "delete", "id": 99}
+	]}`)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusUnprocessableEntity)
+	}
+	if response.Results[0].Status != http.StatusConflict || response.Results[1].Status != http.StatusNotFound {
+		t.Errorf("Resultados incorrectos: %+v", response.Results)
+	}
+	if tasks, _ := taskHandler.store.List(context.Background()); len(tasks) != 2 {
+		t.Errorf("El lote atómico no debió aplicarse: %d tareas", len(tasks))
+	}
+
+	// En modo best_effort se aplican las operaciones válidas
+	rr, response = do(`{"mode": "best_effort", "operations": [
+		{"op": "create", "task": {"title": "Nueva"}},
+		{"op": "delete", "id": 99}
+	]}`)
+	if rr.Code != http.StatusOK || response.Succeeded != 1 || response.Failed != 1 {
+		t.Fatalf("Resultado best_effort incorrecto: %v %+v", rr.Code, response)
+	}
+	if response.Results[0].Status != http.StatusCreated || response.Results[0].ID != 3 {
+		t.Errorf("Creación incorrecta: %+v", response.Results[0])
+	}
+
+	// Completar todas las tareas pendientes que coinciden con el filtro
+	rr, response = do(`{"filter": "NOT completed", "action": "complete"}`)
+	if rr.Code != http.StatusOK || response.Succeeded != 2 {
+		t.Fatalf("Acción por filtro incorrecta: %v %+v", rr.Code, response)
+	}
+	tasks, _ := taskHandler.store.List(context.Background())
+	for _, task := range tasks {
+		if !task.Completed {
+			t.Errorf("La tarea %d debería estar completada", task.ID)
+		}
+	}
+
+	// Un filtro inválido se rechaza con el error del lenguaje de expresiones
+	if rr, _ := do(`{"filter": "completed:tal vez", "action": "delete"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,608 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/caldav"
+	"github.com/claudio/todo-api/internal/ical"
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// CalDAVPrefix es la ruta bajo la que se publica el servidor CalDAV
+const CalDAVPrefix = "/caldav"
+
+// maxCalDAVBody es el tamaño máximo de un VTODO o de un cuerpo XML
+const maxCalDAVBody = 1 << 20
+
+// errPreconditionFailed indica que If-Match o If-None-Match no se cumplen
+var errPreconditionFailed = errors.New("el recurso cambió: el ETag no coincide")
+
+// CalDAVHandler publica las tareas como recursos VTODO de CalDAV (RFC 4791)
+// para que las apps de recordatorios las sincronicen. Cada proyecto es un
+// calendario y las tareas sin proyecto están en el calendario inbox:
+//
+//	/caldav/principals/{usuario}/
+//	/caldav/calendars/{usuario}/
+//	/caldav/calendars/{usuario}/inbox/
+//	/caldav/calendars/{usuario}/project-{id}/{recurso}.ics
+//
+// Solo existen los proyectos con alguna tarea visible; los clientes no pueden
+// crear ni eliminar calendarios.
+type CalDAVHandler struct {
+	tasks   *TaskHandler
+	objects caldav.Store
+}
+
+// NewCalDAVHandler crea una nueva instancia de CalDAVHandler
+func NewCalDAVHandler(tasks *TaskHandler, objects caldav.Store) *CalDAVHandler {
+	return &CalDAVHandler{
+		tasks:   tasks,
+		objects: objects,
+	}
+}
+
+// Niveles de la jerarquía de CalDAV
+const (
+	davRoot = iota
+	davPrincipal
+	davHome
+	davCalendar
+	davObject
+)
+
+// davTarget es el recurso al que apunta una ruta de CalDAV
+type davTarget struct {
+	kind      int
+	user      string
+	projectID int
+	name      string
+}
+
+// davResource es una tarea publicada como recurso de un calendario
+type davResource struct {
+	task models.Task
+	uid  string
+	name string
+	body []byte
+	etag string
+	// custom indica que el nombre y el UID los eligió el cliente
+	custom bool
+}
+
+// Options anuncia las capacidades de WebDAV y CalDAV del servidor
+func (h *CalDAVHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("DAV", "1, 3, calendar-access")
+	w.Header().Set("Allow", "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT")
+	w.WriteHeader(http.StatusOK)
+}
+
+// Propfind devuelve las propiedades del recurso y, con Depth: 1, las de sus
+// hijos. Depth: infinity se trata como 1.
+func (h *CalDAVHandler) Propfind(w http.ResponseWriter, r *http.Request) {
+	target, ok := h.target(w, r)
+	if !ok {
+		return
+	}
+	request, err := caldav.ParsePropfind(http.MaxBytesReader(w, r.Body, maxCalDAVBody))
+	if err != nil {
+		http.Error(w, "Error al decodificar PROPFIND: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	depth := r.Header.Get("Depth")
+
+	ms := &caldav.Multistatus{}
+	switch target.kind {
+	case davRoot:
+		ms.Add(CalDAVPrefix+"/", h.principalProps(target.user, false), request)
+	case davPrincipal:
+		ms.Add(principalHref(target.user), h.principalProps(target.user, true), request)
+	case davHome:
+		calendars, err := h.calendars(r.Context(), target.user)
+		if err != nil {
+			h.writeError(w, "listar los calendarios", err)
+			return
+		}
+		ms.Add(homeHref(target.user), caldav.Props{
+			caldav.ResourceType:         `<collection xmlns="DAV:"/>`,
+			caldav.DisplayName:          caldav.Text("Calendarios de " + target.user),
+			caldav.CurrentUserPrincipal: caldav.Href(principalHref(target.user)),
+		}, request)
+		if depth != "0" {
+			for _, projectID := range sortedProjects(calendars) {
+				ms.Add(calendarHref(target.user, projectID), calendarProps(target.user, projectID, calendars[projectID]), request)
+			}
+		}
+	case davCalendar:
+		resources, ok := h.calendar(w, r, target)
+		if !ok {
+			return
+		}
+		ms.Add(calendarHref(target.user, target.projectID), calendarProps(target.user, target.projectID, resources), request)
+		if depth != "0" {
+			for _, resource := range resources {
+				ms.Add(objectHref(target.user, target.projectID, resource.name), objectProps(resource), request)
+			}
+		}
+	case davObject:
+		resource, err := h.resolve(r.Context(), target)
+		if err != nil {
+			h.writeError(w, "obtener el recurso", err)
+			return
+		}
+		ms.Add(objectHref(target.user, target.projectID, resource.name), objectProps(resource), request)
+	}
+	ms.Write(w)
+}
+
+// Report responde calendar-query con los VTODO del calendario que cumplen el
+// filtro y calendar-multiget con los recursos pedidos por href
+func (h *CalDAVHandler) Report(w http.ResponseWriter, r *http.Request) {
+	target, ok := h.target(w, r)
+	if !ok {
+		return
+	}
+	if target.kind != davCalendar {
+		http.Error(w, "REPORT solo se admite sobre un calendario", http.StatusForbidden)
+		return
+	}
+	report, err := caldav.ParseReport(http.MaxBytesReader(w, r.Body, maxCalDAVBody))
+	if err == caldav.ErrUnsupportedReport {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error al decodificar REPORT: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ms := &caldav.Multistatus{}
+	if report.Type == caldav.CalendarMultiget {
+		for _, href := range report.Hrefs {
+			resource, err := h.resolveHref(r.Context(), target.user, href)
+			if err == errTaskNotFound {
+				ms.AddStatus(href, http.StatusNotFound)
+				continue
+			}
+			if err != nil {
+				h.writeError(w, "obtener los recursos", err)
+				return
+			}
+			ms.Add(href, objectProps(resource), report.Props)
+		}
+		ms.Write(w)
+		return
+	}
+
+	resources, ok := h.calendar(w, r, target)
+	if !ok {
+		return
+	}
+	for _, resource := range resources {
+		if report.Filter.Matches(ical.Properties(resource.uid, resource.task)) {
+			ms.Add(objectHref(target.user, target.projectID, resource.name), objectProps(resource), report.Props)
+		}
+	}
+	ms.Write(w)
+}
+
+// GetObject devuelve el VCALENDAR de una tarea con su ETag
+func (h *CalDAVHandler) GetObject(w http.ResponseWriter, r *http.Request) {
+	target, ok := h.objectTarget(w, r)
+	if !ok {
+		return
+	}
+	resource, err := h.resolve(r.Context(), target)
+	if err != nil {
+		h.writeError(w, "obtener el recurso", err)
+		return
+	}
+
+	w.Header().Set("ETag", resource.etag)
+	if etagMatches(r.Header.Get("If-None-Match"), resource.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Write(resource.body)
+}
+
+// PutObject crea o reemplaza una tarea a partir de un VTODO. Con If-Match
+// solo se reemplaza si la tarea no cambió; con If-None-Match: * solo se crea.
+// La respuesta no lleva ETag porque el servidor descarta las propiedades que
+// las tareas no guardan y el cliente debe volver a leer el recurso.
+func (h *CalDAVHandler) PutObject(w http.ResponseWriter, r *http.Request) {
+	target, ok := h.objectTarget(w, r)
+	if !ok {
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCalDAVBody))
+	if err != nil {
+		http.Error(w, "El cuerpo supera el tamaño máximo", http.StatusRequestEntityTooLarge)
+		return
+	}
+	todo, err := ical.ParseTodo(body)
+	if err == ical.ErrNoTodo {
+		http.Error(w, "Los calendarios solo admiten VTODO", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error al decodificar el VTODO: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ifMatch := r.Header.Get("If-Match")
+	task := models.Task{
+		Title:       todo.Summary,
+		Description: todo.Description,
+		Completed:   todo.Completed,
+		ProjectID:   target.projectID,
+		DueDate:     todo.Due,
+		Priority:    todo.Priority,
+		Recurrence:  todo.Recurrence,
+		Tags:        todo.Tags,
+	}
+	source := sourceFromRequest(r)
+
+	existing, err := h.resolve(r.Context(), target)
+	switch {
+	case err == errTaskNotFound:
+		if ifMatch != "" {
+			h.writeError(w, "guardar el recurso", errPreconditionFailed)
+			return
+		}
+		h.createObject(w, r, source, target, todo.UID, task)
+	case err != nil:
+		h.writeError(w, "guardar el recurso", err)
+	case r.Header.Get("If-None-Match") == "*":
+		h.writeError(w, "guardar el recurso", errPreconditionFailed)
+	default:
+		check := h.objectCheck(source, existing, ifMatch)
+		if _, err := h.tasks.updateTask(r.Context(), source, existing.task.ID, task, check); err != nil {
+			h.writeError(w, "guardar el recurso", err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// createObject crea la tarea de un recurso nuevo y guarda el nombre y el UID
+// que eligió el cliente
+func (h *CalDAVHandler) createObject(w http.ResponseWriter, r *http.Request, source changeSource, target davTarget, uid string, task models.Task) {
+	created, err := h.tasks.createTask(r.Context(), source, task)
+	if err != nil {
+		h.writeError(w, "crear el recurso", err)
+		return
+	}
+
+	if uid == "" {
+		uid = ical.UID(created.ID)
+	}
+	object := caldav.Object{TaskID: created.ID, OwnerID: target.user, Name: target.name, UID: uid}
+	if err := h.objects.Put(r.Context(), object); err != nil {
+		h.writeError(w, "crear el recurso", err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// DeleteObject mueve la tarea a la papelera. El nombre del recurso se
+// conserva por si la tarea se restaura.
+func (h *CalDAVHandler) DeleteObject(w http.ResponseWriter, r *http.Request) {
+	target, ok := h.objectTarget(w, r)
+	if !ok {
+		return
+	}
+	resource, err := h.resolve(r.Context(), target)
+	if err != nil {
+		h.writeError(w, "eliminar el recurso", err)
+		return
+	}
+
+	source := sourceFromRequest(r)
+	check := h.objectCheck(source, resource, r.Header.Get("If-Match"))
+	if err := h.tasks.deleteTask(r.Context(), source, resource.task.ID, check); err != nil {
+		h.writeError(w, "eliminar el recurso", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// objectCheck comprueba dentro de la transacción que la tarea sigue siendo
+// visible y, si hay If-Match, que su ETag no cambió
+func (h *CalDAVHandler) objectCheck(source changeSource, resource davResource, ifMatch string) taskCheck {
+	visible := visibleCheck(source)
+	return func(current models.Task) error {
+		if err := visible(current); err != nil {
+			return err
+		}
+		if ifMatch != "" && !etagMatches(ifMatch, newDAVResource(current, resource.uid, resource.name, resource.custom).etag) {
+			return errPreconditionFailed
+		}
+		return nil
+	}
+}
+
+// target interpreta la ruta de la solicitud. Responde 404 si no corresponde
+// a ningún recurso y 403 si pertenece a otro usuario.
+func (h *CalDAVHandler) target(w http.ResponseWriter, r *http.Request) (davTarget, bool) {
+	identity, _ := auth.FromContext(r.Context())
+	target, ok := parseDAVPath(r.URL.Path)
+	if !ok {
+		http.Error(w, "Recurso no encontrado", http.StatusNotFound)
+		return target, false
+	}
+	if target.kind == davRoot {
+		target.user = identity.UserID
+	} else if target.user != identity.UserID {
+		http.Error(w, "Solo puede acceder a sus propios calendarios", http.StatusForbidden)
+		return target, false
+	}
+	return target, true
+}
+
+// objectTarget es como target, pero solo acepta recursos de un calendario
+func (h *CalDAVHandler) objectTarget(w http.ResponseWriter, r *http.Request) (davTarget, bool) {
+	target, ok := h.target(w, r)
+	if ok && target.kind != davObject {
+		w.Header().Set("Allow", "OPTIONS, PROPFIND, REPORT")
+		http.Error(w, "Método no permitido en una colección", http.StatusMethodNotAllowed)
+		return target, false
+	}
+	return target, ok
+}
+
+// parseDAVPath interpreta una ruta bajo CalDAVPrefix
+func parseDAVPath(path string) (davTarget, bool) {
+	rest := strings.Trim(strings.TrimPrefix(path, CalDAVPrefix), "/")
+	if rest == "" {
+		return davTarget{kind: davRoot}, true
+	}
+
+	segments := strings.Split(rest, "/")
+	switch {
+	case segments[0] == "principals" && len(segments) == 2:
+		return davTarget{kind: davPrincipal, user: segments[1]}, true
+	case segments[0] != "calendars" || len(segments) < 2 || len(segments) > 4:
+		return davTarget{}, false
+	case len(segments) == 2:
+		return davTarget{kind: davHome, user: segments[1]}, true
+	}
+
+	target := davTarget{kind: davCalendar, user: segments[1]}
+	if segments[2] != "inbox" {
+		id, err := strconv.Atoi(strings.TrimPrefix(segments[2], "project-"))
+		if !strings.HasPrefix(segments[2], "project-") || err != nil || id <= 0 {
+			return davTarget{}, false
+		}
+		target.projectID = id
+	}
+	if len(segments) == 4 {
+		target.kind = davObject
+		target.name = segments[3]
+	}
+	return target, true
+}
+
+// calendars devuelve los recursos de todas las tareas visibles agrupados por
+// proyecto. El calendario inbox siempre existe, aunque esté vacío.
+func (h *CalDAVHandler) calendars(ctx context.Context, userID string) (map[int][]davResource, error) {
+	tasks, err := h.tasks.listVisible(ctx, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	resources, err := h.resources(ctx, tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	calendars := map[int][]davResource{0: {}}
+	for _, resource := range resources {
+		calendars[resource.task.ProjectID] = append(calendars[resource.task.ProjectID], resource)
+	}
+	return calendars, nil
+}
+
+// calendar devuelve los recursos de un calendario o responde 404 si no existe
+func (h *CalDAVHandler) calendar(w http.ResponseWriter, r *http.Request, target davTarget) ([]davResource, bool) {
+	calendars, err := h.calendars(r.Context(), target.user)
+	if err != nil {
+		h.writeError(w, "listar el calendario", err)
+		return nil, false
+	}
+	resources, ok := calendars[target.projectID]
+	if !ok {
+		http.Error(w, "Calendario no encontrado", http.StatusNotFound)
+		return nil, false
+	}
+	return resources, true
+}
+
+// resources convierte las tareas en recursos con el nombre y el UID que les
+// dio el cliente, si se crearon por CalDAV
+func (h *CalDAVHandler) resources(ctx context.Context, tasks []models.Task) ([]davResource, error) {
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	objects, err := h.objects.ByTask(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]davResource, len(tasks))
+	for i, task := range tasks {
+		if object, ok := objects[task.ID]; ok {
+			resources[i] = newDAVResource(task, object.UID, object.Name, true)
+		} else {
+			resources[i] = newDAVResource(task, ical.UID(task.ID), defaultObjectName(task.ID), false)
+		}
+	}
+	return resources, nil
+}
+
+// resolve busca la tarea de un recurso: primero entre los nombres que
+// eligieron los clientes y después como "{id}.ics". La tarea debe ser
+// visible, no estar en la papelera y pertenecer al calendario de la ruta.
+func (h *CalDAVHandler) resolve(ctx context.Context, target davTarget) (davResource, error) {
+	var resource davResource
+	object, err := h.objects.ByName(ctx, target.user, target.name)
+	if err != nil && err != caldav.ErrNotFound {
+		return resource, err
+	}
+	if err == caldav.ErrNotFound {
+		id, convErr := strconv.Atoi(strings.TrimSuffix(target.name, ".ics"))
+		if convErr != nil || defaultObjectName(id) != target.name {
+			return resource, errTaskNotFound
+		}
+		object = caldav.Object{TaskID: id}
+	}
+
+	task, err := h.tasks.store.Get(ctx, object.TaskID)
+	if err != nil {
+		return resource, err
+	}
+	if task.DeletedAt != nil || !task.VisibleTo(target.user) || task.ProjectID != target.projectID {
+		return resource, errTaskNotFound
+	}
+
+	resources, err := h.resources(ctx, []models.Task{task})
+	if err != nil {
+		return resource, err
+	}
+	// Una tarea creada por CalDAV ya no responde a su nombre predeterminado
+	if resources[0].name != target.name {
+		return resource, errTaskNotFound
+	}
+	return resources[0], nil
+}
+
+// resolveHref busca el recurso de un href de calendar-multiget
+func (h *CalDAVHandler) resolveHref(ctx context.Context, userID, href string) (davResource, error) {
+	path := href
+	if parsed, err := url.Parse(href); err == nil {
+		path = parsed.Path
+	}
+	target, ok := parseDAVPath(path)
+	if !ok || target.kind != davObject || target.user != userID {
+		return davResource{}, errTaskNotFound
+	}
+	return h.resolve(ctx, target)
+}
+
+// writeError responde con el código que corresponde al error
+func (h *CalDAVHandler) writeError(w http.ResponseWriter, action string, err error) {
+	if invalid, ok := err.(*invalidTaskError); ok {
+		http.Error(w, "VTODO inválido: "+invalid.Error(), http.StatusBadRequest)
+		return
+	}
+	switch err {
+	case errTaskNotFound:
+		http.Error(w, "Recurso no encontrado", http.StatusNotFound)
+	case errPreconditionFailed:
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	case errTitleRequired:
+		http.Error(w, "El VTODO necesita SUMMARY", http.StatusBadRequest)
+	default:
+		log.Printf("Error al %s: %v", action, err)
+		http.Error(w, "Error al "+action, http.StatusInternalServerError)
+	}
+}
+
+// newDAVResource serializa la tarea y calcula su ETag
+func newDAVResource(task models.Task, uid, name string, custom bool) davResource {
+	body := ical.Resource(uid, task)
+	return davResource{task: task, uid: uid, name: name, body: body, etag: contentETag(body), custom: custom}
+}
+
+// defaultObjectName es el nombre de las tareas que no se crearon por CalDAV
+func defaultObjectName(taskID int) string {
+	return strconv.Itoa(taskID) + ".ics"
+}
+
+// principalProps son las propiedades del principal del usuario, que también
+// se devuelven en la raíz para que los clientes lo descubran
+func (h *CalDAVHandler) principalProps(userID string, principal bool) caldav.Props {
+	resourceType := `<collection xmlns="DAV:"/>`
+	if principal {
+		resourceType = `<principal xmlns="DAV:"/>`
+	}
+	return caldav.Props{
+		caldav.ResourceType:         resourceType,
+		caldav.DisplayName:          caldav.Text(userID),
+		caldav.CurrentUserPrincipal: caldav.Href(principalHref(userID)),
+		caldav.PrincipalURL:         caldav.Href(principalHref(userID)),
+		caldav.CalendarHomeSet:      caldav.Href(homeHref(userID)),
+	}
+}
+
+// calendarProps son las propiedades de un calendario. getctag cambia cuando
+// cambia cualquiera de sus recursos.
+func calendarProps(userID string, projectID int, resources []davResource) caldav.Props {
+	name := "Bandeja de entrada"
+	if projectID != 0 {
+		name = "Proyecto " + strconv.Itoa(projectID)
+	}
+
+	hash := sha256.New()
+	for _, resource := range resources {
+		io.WriteString(hash, resource.name+resource.etag)
+	}
+	return caldav.Props{
+		caldav.ResourceType:                  `<collection xmlns="DAV:"/><calendar xmlns="urn:ietf:params:xml:ns:caldav"/>`,
+		caldav.DisplayName:                   caldav.Text(name),
+		caldav.SupportedCalendarComponentSet: `<comp xmlns="urn:ietf:params:xml:ns:caldav" name="VTODO"/>`,
+		caldav.CurrentUserPrincipal:          caldav.Href(principalHref(userID)),
+		caldav.GetCTag:                       caldav.Text(hex.EncodeToString(hash.Sum(nil)[:16])),
+	}
+}
+
+// objectProps son las propiedades del recurso de una tarea
+func objectProps(resource davResource) caldav.Props {
+	return caldav.Props{
+		caldav.ResourceType:   "",
+		caldav.GetETag:        caldav.Text(resource.etag),
+		caldav.GetContentType: "text/calendar; charset=utf-8; component=VTODO",
+		caldav.CalendarData:   caldav.Text(string(resource.body)),
+	}
+}
+
+// sortedProjects devuelve los proyectos con inbox (0) primero
+func sortedProjects(calendars map[int][]davResource) []int {
+	ids := make([]int, 0, len(calendars))
+	for id := range calendars {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func principalHref(userID string) string {
+	return CalDAVPrefix + "/principals/" + url.PathEscape(userID) + "/"
+}
+
+func homeHref(userID string) string {
+	return CalDAVPrefix + "/calendars/" + url.PathEscape(userID) + "/"
+}
+
+func calendarHref(userID string, projectID int) string {
+	name := "inbox"
+	if projectID != 0 {
+		name = "project-" + strconv.Itoa(projectID)
+	}
+	return homeHref(userID) + name + "/"
+}
+
+func objectHref(userID string, projectID int, name string) string {
+	return calendarHref(userID, projectID) + url.PathEscape(name)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/caldav"
+	"github.com/gorilla/mux"
+)
+
+func TestCalDAVObjects(t *testing.T) {
+	taskHandler := NewTaskHandler()
+	davHandler := NewCalDAVHandler(taskHandler, caldav.NewMemoryStore())
+	router := mux.NewRouter()
+	router.PathPrefix("/caldav/").HandlerFunc(davHandler.Propfind).Methods("PROPFIND")
+	router.PathPrefix("/caldav/").HandlerFunc(davHandler.Report).Methods("REPORT")
+	router.PathPrefix("/caldav/").HandlerFunc(davHandler.GetObject).Methods("GET")
+	router.PathPrefix("/caldav/").HandlerFunc(davHandler.PutObject).Methods("PUT")
+	router.PathPrefix("/caldav/").HandlerFunc(davHandler.DeleteObject).Methods("DELETE")
+
+	do := func(method, path, body string, headers ...string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req = req.WithContext(auth.WithIdentity(req.Context(), auth.Identity{UserID: "ana"}))
+		for i := 0; i < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	expect := func(rr *httptest.ResponseRecorder, status int) {
+		t.Helper()
+		if rr.Code != status {
+			t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v: %s", rr.Code, status, rr.Body.String())
+		}
+	}
+	todo := func(summary, status string) string {
+		return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:9f1c-movil\r\nSUMMARY:" + summary +
+			"\r\nSTATUS:" + status + "\r\nBEGIN:VALARM\r\nACTION:DISPLAY\r\nEND:VALARM\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	}
+	path := "/caldav/calendars/ana/project-5/9f1c-movil.ics"
+
+	// Crear una tarea con el nombre y el UID que eligió el cliente
+	expect(do("PUT", path, todo("Llamar al banco", "NEEDS-ACTION"), "If-None-Match", "*"), http.StatusCreated)
+	expect(do("PUT", path, todo("Otra", "NEEDS-ACTION"), "If-None-Match", "*"), http.StatusPreconditionFailed)
+	expect(do("PUT", "/caldav/calendars/ana/inbox/evento.ics", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"), http.StatusForbidden)
+
+	rr := do("GET", path, "")
+	expect(rr, http.StatusOK)
+	etag := rr.Header().Get("ETag")
+	if !strings.Contains(rr.Body.String(), "UID:9f1c-movil\r\n") || !strings.Contains(rr.Body.String(), "CATEGORIES:project-5\r\n") {
+		t.Errorf("VTODO incorrecto: %s", rr.Body.String())
+	}
+	task, _ := taskHandler.store.Get(context.Background(), 3)
+	if task.Title != "Llamar al banco" || task.OwnerID != "ana" || task.ProjectID != 5 {
+		t.Errorf("Tarea creada incorrecta: %+v", task)
+	}
+	// La tarea no responde a su nombre predeterminado
+	expect(do("GET", "/caldav/calendars/ana/project-5/3.ics", ""), http.StatusNotFound)
+
+	// If-Match protege de sobrescribir cambios de otro cliente
+	expect(do("PUT", path, todo("Llamar al banco", "COMPLETED"), "If-Match", `"viejo"`), http.StatusPreconditionFailed)
+	expect(do("PUT", path, todo("Llamar al banco", "COMPLETED"), "If-Match", etag), http.StatusNoContent)
+	if rr := do("GET", path, "", "If-None-Match", etag); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "STATUS:COMPLETED") {
+		t.Errorf("La tarea debió completarse: %v %s", rr.Code, rr.Body.String())
+	}
+
+	// El proyecto aparece como calendario
+	rr = do("PROPFIND", "/caldav/calendars/ana/", "", "Depth", "1")
+	expect(rr, http.StatusMultiStatus)
+	if !strings.Contains(rr.Body.String(), "<href>/caldav/calendars/ana/project-5/</href>") {
+		t.Errorf("Falta el calendario del proyecto: %s", rr.Body.String())
+	}
+	expect(do("PROPFIND", "/caldav/calendars/luis/", ""), http.StatusForbidden)
+
+	// calendar-query devuelve solo las tareas pendientes de la bandeja
+	query := `<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+		<d:prop><d:getetag/></d:prop>
+		<c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VTODO">
+			<c:prop-filter name="STATUS"><c:text-match negate-condition="yes">COMPLETED</c:text-match></c:prop-filter>
+		</c:comp-filter></c:comp-filter></c:filter>
+	</c:calendar-query>`
+	rr = do("REPORT", "/caldav/calendars/ana/inbox/", query)
+	expect(rr, http.StatusMultiStatus)
+	if strings.Count(rr.Body.String(), "<response>") != 1 || !strings.Contains(rr.Body.String(), "/inbox/1.ics") {
+		t.Errorf("Resultado de calendar-query incorrecto: %s", rr.Body.String())
+	}
+
+	// calendar-multiget informa 404 para los recursos que no existen
+	multiget := `<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+		<d:prop><d:getetag/><c:calendar-data/></d:prop>
+		<d:href>` + path + `</d:href><d:href>/caldav/calendars/ana/project-5/nada.ics</d:href>
+	</c:calendar-multiget>`
+	rr = do("REPORT", "/caldav/calendars/ana/project-5/", multiget)
+	expect(rr, http.StatusMultiStatus)
+	if !strings.Contains(rr.Body.String(), "SUMMARY:Llamar al banco") || !strings.Contains(rr.Body.String(), "HTTP/1.1 404 Not Found") {
+		t.Errorf("Resultado de calendar-multiget incorrecto: %s", rr.Body.String())
+	}
+
+	// Eliminar mueve la tarea a la papelera
+	expect(do("DELETE", path, ""), http.StatusNoContent)
+	expect(do("GET", path, ""), http.StatusNotFound)
+}
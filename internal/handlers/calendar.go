@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/feeds"
+	"github.com/claudio/todo-api/internal/ical"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/gorilla/mux"
+)
+
+// CalendarHandler publica las tareas de cada usuario como un calendario
+// iCalendar en una URL secreta, para suscribirse desde apps de calendario
+type CalendarHandler struct {
+	tasks *TaskHandler
+	feeds feeds.Store
+}
+
+// NewCalendarHandler crea una nueva instancia de CalendarHandler
+func NewCalendarHandler(tasks *TaskHandler, feedStore feeds.Store) *CalendarHandler {
+	return &CalendarHandler{
+		tasks: tasks,
+		feeds: feedStore,
+	}
+}
+
+// CreateFeed genera la URL secreta del calendario del usuario. Si ya tenía
+// una, la anterior deja de funcionar. El token solo se devuelve en esta respuesta.
+func (h *CalendarHandler) CreateFeed(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	identity, _ := auth.FromContext(r.Context())
+
+	feed, err := h.feeds.Create(r.Context(), identity.UserID)
+	if err != nil {
+		log.Printf("Error al crear la suscripción de calendario: %v", err)
+		http.Error(w, "Error al crear la suscripción de calendario", http.StatusInternalServerError)
+		return
+	}
+
+	// La URL del calendario cuelga del mismo prefijo de versión que esta ruta
+	prefix := strings.TrimSuffix(r.URL.Path, "/feed")
+	feed.URL = requestOrigin(r) + prefix + "/" + feed.Token + ".ics"
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(feed)
+}
+
+// DeleteFeed revoca la URL secreta del calendario del usuario
+func (h *CalendarHandler) DeleteFeed(w http.ResponseWriter, r *http.Request) {
+	identity, _ := auth.FromContext(r.Context())
+
+	err := h.feeds.Delete(r.Context(), identity.UserID)
+	if err == feeds.ErrNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error al revocar la suscripción de calendario: %v", err)
+		http.Error(w, "Error al revocar la suscripción de calendario", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetFeed devuelve el calendario con un VTODO por cada tarea con fecha de
+// vencimiento visible para el dueño del token. Los clientes revalidan con
+// If-None-Match y reciben 304 si ninguna tarea cambió.
+func (h *CalendarHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.feeds.Lookup(r.Context(), mux.Vars(r)["token"])
+	if err == feeds.ErrNotFound {
+		http.Error(w, "Calendario no encontrado", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error al buscar la suscripción de calendario: %v", err)
+		http.Error(w, "Error al obtener el calendario", http.StatusInternalServerError)
+		return
+	}
+
+	visible, err := h.tasks.listVisible(r.Context(), userID, "")
+	if err != nil {
+		log.Printf("Error al listar las tareas del calendario: %v", err)
+		http.Error(w, "Error al obtener el calendario", http.StatusInternalServerError)
+		return
+	}
+	// Las tareas sin vencimiento no tienen lugar en un calendario
+	tasks := []models.Task{}
+	for _, task := range visible {
+		if task.DueDate != nil {
+			tasks = append(tasks, task)
+		}
+	}
+
+	body := ical.Calendar("Tareas de "+userID, tasks)
+	etag := contentETag(body)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if modified := lastModified(tasks); !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Content-Disposition", `inline; filename="tasks.ics"`)
+	w.Write(body)
+}
+
+// requestOrigin devuelve el esquema y el host con los que el cliente llegó al
+// servidor, respetando X-Forwarded-Proto detrás de un proxy
+func requestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// contentETag devuelve un ETag fuerte calculado a partir del contenido
+func contentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches indica si un encabezado If-None-Match o If-Match incluye el
+// ETag. La comparación es débil: se ignora el prefijo W/.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// lastModified devuelve la fecha de la última modificación de las tareas
+func lastModified(tasks []models.Task) time.Time {
+	var latest time.Time
+	for _, task := range tasks {
+		if task.UpdatedAt.After(latest) {
+			latest = task.UpdatedAt
+		}
+	}
+	return latest
+}
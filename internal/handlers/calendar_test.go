@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/feeds"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/gorilla/mux"
+)
+
+func TestCalendarFeed(t *testing.T) {
+	taskHandler := NewTaskHandler()
+	calendarHandler := NewCalendarHandler(taskHandler, feeds.NewMemoryStore())
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/calendar/feed", calendarHandler.CreateFeed).Methods("POST")
+	router.HandleFunc("/api/v1/calendar/feed", calendarHandler.DeleteFeed).Methods("DELETE")
+	router.HandleFunc("/api/v1/calendar/{token:[0-9a-f]+}.ics", calendarHandler.GetFeed).Methods("GET")
+
+	createFeed := func() models.CalendarFeed {
+		req, _ := http.NewRequest("POST", "/api/v1/calendar/feed", nil)
+		req.Host = "todo.example.com"
+		req = req.WithContext(auth.WithIdentity(req.Context(), auth.Identity{UserID: "ana"}))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusCreated)
+		}
+		var feed models.CalendarFeed
+		json.Unmarshal(rr.Body.Bytes(), &feed)
+		return feed
+	}
+	getFeed := func(path, etag string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set("If-None-Match", etag)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Solo las tareas con vencimiento aparecen en el calendario
+	due := time.Date(2026, time.October, 20, 9, 0, 0, 0, time.UTC)
+	task := models.Task{Title: "Pagar el alquiler", DueDate: &due, Priority: 1, Recurrence: "FREQ=MONTHLY"}
+	if _, err := taskHandler.createTask(context.Background(), changeSource{userID: "ana"}, task); err != nil {
+		t.Fatal(err)
+	}
+
+	feed := createFeed()
+	path := "/api/v1/calendar/" + feed.Token + ".ics"
+	if feed.URL != "http://todo.example.com"+path {
+		t.Errorf("URL del calendario incorrecta: %s", feed.URL)
+	}
+
+	rr := getFeed(path, "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusOK)
+	}
+	body := rr.Body.String()
+	if !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/calendar") || strings.Count(body, "BEGIN:VTODO") != 1 {
+		t.Errorf("Calendario incorrecto: %s", body)
+	}
+	for _, line := range []string{"DUE:20261020T090000Z", "PRIORITY:1", "RRULE:FREQ=MONTHLY"} {
+		if !strings.Contains(body, line+"\r\n") {
+			t.Errorf("Falta %s en el calendario: %s", line, body)
+		}
+	}
+
+	// Sin cambios, el cliente recibe 304 con el mismo ETag
+	etag := rr.Header().Get("ETag")
+	if rr := getFeed(path, etag); rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+		t.Errorf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusNotModified)
+	}
+
+	// Un token nuevo invalida el anterior
+	newFeed := createFeed()
+	if rr := getFeed(path, ""); rr.Code != http.StatusNotFound {
+		t.Errorf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusNotFound)
+	}
+	if rr := getFeed("/api/v1/calendar/"+newFeed.Token+".ics", etag); rr.Code != http.StatusNotModified {
+		t.Errorf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusNotModified)
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/claudio/todo-api/internal/apiversion"
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/events"
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// heartbeatInterval es cada cuánto se envía un comentario para mantener viva la conexión
+const heartbeatInterval = 15 * time.Second
+
+// EventsHandler transmite los cambios de tareas mediante Server-Sent Events
+type EventsHandler struct {
+	bus       *events.Bus
+	heartbeat time.Duration
+}
+
+// NewEventsHandler crea una nueva instancia de EventsHandler
+func NewEventsHandler(bus *events.Bus) *EventsHandler {
+	return &EventsHandler{
+		bus:       bus,
+		heartbeat: heartbeatInterval,
+	}
+}
+
+// Stream mantiene abierta la conexión y envía cada evento de tarea visible
+// para el usuario. Con ?scope=mine solo se envían los eventos de sus tareas.
+// El encabezado Last-Event-ID permite reanudar sin perder eventos.
+func (h *EventsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	identity, _ := auth.FromContext(r.Context())
+	onlyMine := r.URL.Query().Get("scope") == "mine"
+	codec := taskCodec(r)
+
+	lastID, _ := strconv.ParseInt(r.Header.Get("Last-Event-ID"), 10, 64)
+
+	filter := func(event models.TaskEvent) bool {
+		if onlyMine {
+			return event.Task != nil && event.Task.OwnerID == identity.UserID
+		}
+		return event.VisibleTo(identity.UserID)
+	}
+
+	sub, missed, complete := h.bus.Subscribe(lastID, filter)
+	defer h.bus.Unsubscribe(sub)
+
+	// La conexión es de larga duración: desactivar el WriteTimeout del servidor
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Si faltan eventos en el buffer el cliente debe recargar el estado completo
+	if !complete {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, event := range missed {
+		writeEvent(w, codec, event)
+	}
+	rc.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case event, ok := <-sub.C:
+			if !ok {
+				// El bus desconectó al suscriptor por lento; el cliente reanudará
+				return
+			}
+			writeEvent(w, codec, event)
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeEvent escribe un evento en formato SSE, con la tarea en la
+// representación de la versión de la API
+func writeEvent(w http.ResponseWriter, codec apiversion.TaskCodec, event models.TaskEvent) {
+	data, _ := json.Marshal(apiversion.EncodeEvent(codec, event))
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
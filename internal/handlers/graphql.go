@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+)
+
+// maxGraphQLBody es el tamaño máximo del cuerpo de una consulta GraphQL
+const maxGraphQLBody = 1 << 20
+
+// GraphQLHandler expone las tareas, sus comentarios y los proyectos en
+// /graphql. Las mutaciones de tareas usan las mismas operaciones que la API
+// REST, con su auditoría, historial y eventos.
+type GraphQLHandler struct {
+	tasks  *TaskHandler
+	schema graphql.Schema
+}
+
+// graphqlRequest es el estado de una solicitud disponible en los resolvers
+type graphqlRequest struct {
+	source changeSource
+	loader *graphqlLoader
+}
+
+// graphqlContextKey es la clave del estado de la solicitud en el contexto
+type graphqlContextKey struct{}
+
+// graphqlBody es el cuerpo de una solicitud GraphQL por HTTP
+type graphqlBody struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// NewGraphQLHandler crea el manejador de GraphQL. El esquema es fijo, así
+// que un error al construirlo es un error de programación.
+func NewGraphQLHandler(tasks *TaskHandler) *GraphQLHandler {
+	schema, err := newGraphQLSchema(tasks)
+	if err != nil {
+		panic("esquema GraphQL inválido: " + err.Error())
+	}
+	return &GraphQLHandler{tasks: tasks, schema: schema}
+}
+
+// ExecuteGraphQL ejecuta una consulta o mutación. Las consultas que superan la
+// profundidad o la complejidad máximas se rechazan sin ejecutarse.
+func (h *GraphQLHandler) ExecuteGraphQL(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var body graphqlBody
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLBody)).Decode(&body); err != nil || body.Query == "" {
+		writeGraphQLError(w, http.StatusBadRequest, "El cuerpo debe ser JSON con el campo query")
+		return
+	}
+	if err := checkGraphQLLimits(body.Query, body.OperationName, body.Variables); err != nil {
+		writeGraphQLError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	source := sourceFromRequest(r)
+	ctx := context.WithValue(r.Context(), graphqlContextKey{}, &graphqlRequest{
+		source: source,
+		loader: newGraphQLLoader(r.Context(), h.tasks, source.userID),
+	})
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  body.Query,
+		VariableValues: body.Variables,
+		OperationName:  body.OperationName,
+		Context:        ctx,
+	})
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// graphqlFromContext devuelve el estado de la solicitud de un resolver
+func graphqlFromContext(p graphql.ResolveParams) *graphqlRequest {
+	return p.Context.Value(graphqlContextKey{}).(*graphqlRequest)
+}
+
+// writeGraphQLError responde con un error en el formato de GraphQL
+func writeGraphQLError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []map[string]string{{"message": message}},
+	})
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+const (
+	// maxGraphQLDepth es el anidamiento máximo de campos de una consulta
+	maxGraphQLDepth = 8
+	// maxGraphQLComplexity es el coste máximo de una consulta; cada campo
+	// cuesta 1 y las listas multiplican el coste de sus campos por el
+	// número de elementos que piden
+	maxGraphQLComplexity = 1000
+)
+
+// graphqlLimits recorre el documento para calcular la profundidad y la
+// complejidad de la operación antes de ejecutarla
+type graphqlLimits struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	// visiting evita ciclos entre fragmentos; la validación de la consulta
+	// los rechaza después con un mensaje propio
+	visiting map[string]bool
+}
+
+// checkGraphQLLimits rechaza las consultas que superan la profundidad o la
+// complejidad máximas. Los errores de sintaxis se dejan a la ejecución, que
+// los devuelve con su posición.
+func checkGraphQLLimits(query, operationName string, variables map[string]interface{}) error {
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return nil
+	}
+
+	limits := &graphqlLimits{
+		fragments: map[string]*ast.FragmentDefinition{},
+		variables: variables,
+		visiting:  map[string]bool{},
+	}
+	var operations []*ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			limits.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operations = append(operations, definition)
+			}
+		}
+	}
+
+	for _, operation := range operations {
+		depth, complexity := limits.selectionSet(operation.SelectionSet, 1)
+		if depth > maxGraphQLDepth {
+			return fmt.Errorf("la consulta supera la profundidad máxima de %d niveles", maxGraphQLDepth)
+		}
+		if complexity > maxGraphQLComplexity {
+			return fmt.Errorf("la consulta supera la complejidad máxima de %d (obtuvo %d)", maxGraphQLComplexity, complexity)
+		}
+	}
+	return nil
+}
+
+// selectionSet devuelve la profundidad máxima y la complejidad de los campos
+// seleccionados; level es el nivel de los campos del conjunto
+func (l *graphqlLimits) selectionSet(set *ast.SelectionSet, level int) (int, int) {
+	if set == nil {
+		return level - 1, 0
+	}
+
+	depth, complexity := level-1, 0
+	for _, selection := range set.Selections {
+		var d, c int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			d, c = l.selectionSet(selection.SelectionSet, level+1)
+			c = 1 + c*l.multiplier(selection)
+		case *ast.InlineFragment:
+			d, c = l.selectionSet(selection.SelectionSet, level)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := l.fragments[name]
+			if !ok || l.visiting[name] {
+				continue
+			}
+			l.visiting[name] = true
+			d, c = l.selectionSet(fragment.SelectionSet, level)
+			l.visiting[name] = false
+		}
+		if d > depth {
+			depth = d
+		}
+		complexity += c
+	}
+	return depth, complexity
+}
+
+// multiplier estima cuántos elementos devuelve el campo a partir de los
+// argumentos first o limit; sin ellos se usa el tamaño de página por defecto
+// en las listas paginadas y 1 en los demás campos
+func (l *graphqlLimits) multiplier(field *ast.Field) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "first" && argument.Name.Value != "limit" {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(value.Value); err == nil && n > 0 {
+				return n
+			}
+		case *ast.Variable:
+			if n, ok := l.variables[value.Name.Value].(float64); ok && n > 0 {
+				return int(n)
+			}
+		}
+		return defaultGraphQLPageSize
+	}
+
+	switch field.Name.Value {
+	case "tasks", "history", "comments":
+		return defaultGraphQLPageSize
+	}
+	return 1
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"sort"
+
+	"github.com/claudio/todo-api/internal/filter"
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// graphqlLoader agrupa las lecturas de una consulta GraphQL. Los resolvers
+// registran los IDs que necesitan y devuelven una función diferida; la
+// ejecución resuelve las funciones después de recorrer todos los campos
+// hermanos, de modo que la primera lectura trae los datos de todos ellos en
+// una sola llamada al almacén.
+type graphqlLoader struct {
+	tasks  *TaskHandler
+	ctx    context.Context
+	userID string
+
+	projects  *idBatch
+	histories *idBatch
+	comments  *idBatch
+
+	projectTasks map[int][]models.Task
+	revisions    map[int][]models.TaskRevision
+	taskComments map[int][]models.Comment
+}
+
+// newGraphQLLoader crea el cargador de una solicitud
+func newGraphQLLoader(ctx context.Context, tasks *TaskHandler, userID string) *graphqlLoader {
+	l := &graphqlLoader{
+		tasks:        tasks,
+		ctx:          ctx,
+		userID:       userID,
+		projectTasks: map[int][]models.Task{},
+		revisions:    map[int][]models.TaskRevision{},
+		taskComments: map[int][]models.Comment{},
+	}
+	l.projects = newIDBatch(l.fetchProjectTasks)
+	l.histories = newIDBatch(l.fetchRevisions)
+	l.comments = newIDBatch(l.fetchComments)
+	return l
+}
+
+// ProjectTasks devuelve una función que obtiene las tareas visibles del proyecto
+func (l *graphqlLoader) ProjectTasks(projectID int) func() ([]models.Task, error) {
+	l.projects.add(projectID)
+	return func() ([]models.Task, error) {
+		if err := l.projects.load(); err != nil {
+			return nil, err
+		}
+		return l.projectTasks[projectID], nil
+	}
+}
+
+// History devuelve una función que obtiene el historial de la tarea
+func (l *graphqlLoader) History(taskID int) func() ([]models.TaskRevision, error) {
+	l.histories.add(taskID)
+	return func() ([]models.TaskRevision, error) {
+		if err := l.histories.load(); err != nil {
+			return nil, err
+		}
+		return l.revisions[taskID], nil
+	}
+}
+
+// Comments devuelve una función que obtiene los comentarios de la tarea
+func (l *graphqlLoader) Comments(taskID int) func() ([]models.Comment, error) {
+	l.comments.add(taskID)
+	return func() ([]models.Comment, error) {
+		if err := l.comments.load(); err != nil {
+			return nil, err
+		}
+		return l.taskComments[taskID], nil
+	}
+}
+
+// fetchProjectTasks lee con una sola consulta las tareas de varios proyectos
+func (l *graphqlLoader) fetchProjectTasks(projectIDs []int) error {
+	var expr filter.Expr
+	for _, id := range projectIDs {
+		var compare filter.Expr = filter.Compare{Field: "project_id", Op: "=", Value: id}
+		if expr == nil {
+			expr = compare
+		} else {
+			expr = filter.Or{Left: expr, Right: compare}
+		}
+		l.projectTasks[id] = []models.Task{}
+	}
+
+	tasks, err := l.tasks.store.Find(l.ctx, expr)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if task.DeletedAt == nil && task.VisibleTo(l.userID) {
+			l.projectTasks[task.ProjectID] = append(l.projectTasks[task.ProjectID], task)
+		}
+	}
+	return nil
+}
+
+// fetchRevisions lee con una sola consulta el historial de varias tareas
+func (l *graphqlLoader) fetchRevisions(taskIDs []int) error {
+	revisions, err := l.tasks.history.ListMany(l.ctx, taskIDs)
+	if err != nil {
+		return err
+	}
+	for _, id := range taskIDs {
+		l.revisions[id] = revisions[id]
+	}
+	return nil
+}
+
+// fetchComments lee con una sola consulta los comentarios de varias tareas
+func (l *graphqlLoader) fetchComments(taskIDs []int) error {
+	comments, err := l.tasks.comments.ListMany(l.ctx, taskIDs)
+	if err != nil {
+		return err
+	}
+	for _, id := range taskIDs {
+		l.taskComments[id] = comments[id]
+	}
+	return nil
+}
+
+// idBatch acumula los IDs pendientes de leer y los lee todos juntos la
+// primera vez que se necesita alguno
+type idBatch struct {
+	fetch   func(ids []int) error
+	pending []int
+	seen    map[int]bool
+	err     error
+}
+
+// newIDBatch crea un lote que lee los IDs con fetch
+func newIDBatch(fetch func(ids []int) error) *idBatch {
+	return &idBatch{fetch: fetch, seen: map[int]bool{}}
+}
+
+// add registra un ID; los IDs ya leídos o pendientes se ignoran
+func (b *idBatch) add(id int) {
+	if !b.seen[id] {
+		b.seen[id] = true
+		b.pending = append(b.pending, id)
+	}
+}
+
+// load lee los IDs pendientes. Un error se conserva para el resto de la
+// consulta en lugar de reintentar la lectura en cada campo.
+func (b *idBatch) load() error {
+	if len(b.pending) == 0 || b.err != nil {
+		return b.err
+	}
+
+	ids := b.pending
+	b.pending = nil
+	sort.Ints(ids)
+	b.err = b.fetch(ids)
+	return b.err
+}
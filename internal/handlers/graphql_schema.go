@@ -0,0 +1,527 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/claudio/todo-api/internal/comments"
+	"github.com/claudio/todo-api/internal/filter"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/store"
+	"github.com/graphql-go/graphql"
+)
+
+const (
+	// defaultGraphQLPageSize es el número de tareas por página por defecto
+	defaultGraphQLPageSize = 20
+	// maxGraphQLPageSize es el máximo de tareas que se pueden pedir por página
+	maxGraphQLPageSize = 100
+	// cursorPrefix distingue los cursores de tareas
+	cursorPrefix = "task:"
+)
+
+// errInvalidCursor indica que el cursor de paginación no es válido
+var errInvalidCursor = errors.New("cursor inválido")
+
+// graphqlConnection es una página de tareas
+type graphqlConnection struct {
+	nodes       []models.Task
+	totalCount  int
+	hasNextPage bool
+	endCursor   string
+}
+
+// newGraphQLSchema define los tipos, consultas y mutaciones de GraphQL. Los
+// resolvers obtienen el origen de la solicitud y el cargador del contexto.
+func newGraphQLSchema(h *TaskHandler) (graphql.Schema, error) {
+	fieldChangeType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "FieldChange",
+		Description: "Cambio de un campo; los valores se codifican en JSON",
+		Fields: graphql.Fields{
+			"field": {Type: graphql.NewNonNull(graphql.String), Resolve: changeField(func(c models.FieldChange) interface{} { return c.Field })},
+			"from":  {Type: graphql.String, Resolve: changeField(func(c models.FieldChange) interface{} { return jsonValue(c.From) })},
+			"to":    {Type: graphql.String, Resolve: changeField(func(c models.FieldChange) interface{} { return jsonValue(c.To) })},
+		},
+	})
+
+	revisionType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Revision",
+		Description: "Versión guardada de una tarea después de un cambio",
+		Fields: graphql.Fields{
+			"revision":  {Type: graphql.NewNonNull(graphql.Int), Resolve: revisionField(func(r models.TaskRevision) interface{} { return r.Revision })},
+			"action":    {Type: graphql.NewNonNull(graphql.String), Resolve: revisionField(func(r models.TaskRevision) interface{} { return r.Action })},
+			"actor":     {Type: graphql.NewNonNull(graphql.String), Resolve: revisionField(func(r models.TaskRevision) interface{} { return r.Actor })},
+			"createdAt": {Type: graphql.NewNonNull(graphql.DateTime), Resolve: revisionField(func(r models.TaskRevision) interface{} { return r.CreatedAt })},
+			"changes":   {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(fieldChangeType))), Resolve: revisionField(func(r models.TaskRevision) interface{} { return r.Changes })},
+		},
+	})
+
+	commentType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Comment",
+		Description: "Comentario sobre una tarea",
+		Fields: graphql.Fields{
+			"id":        {Type: graphql.NewNonNull(graphql.Int), Resolve: commentField(func(c models.Comment) interface{} { return int(c.ID) })},
+			"author":    {Type: graphql.NewNonNull(graphql.String), Resolve: commentField(func(c models.Comment) interface{} { return c.Author })},
+			"body":      {Type: graphql.NewNonNull(graphql.String), Resolve: commentField(func(c models.Comment) interface{} { return c.Body })},
+			"createdAt": {Type: graphql.NewNonNull(graphql.DateTime), Resolve: commentField(func(c models.Comment) interface{} { return c.CreatedAt })},
+		},
+	})
+
+	taskType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Task",
+		Description: "Tarea",
+		Fields: graphql.Fields{
+			"id":          {Type: graphql.NewNonNull(graphql.Int), Resolve: taskField(func(t models.Task) interface{} { return t.ID })},
+			"title":       {Type: graphql.NewNonNull(graphql.String), Resolve: taskField(func(t models.Task) interface{} { return t.Title })},
+			"description": {Type: graphql.NewNonNull(graphql.String), Resolve: taskField(func(t models.Task) interface{} { return t.Description })},
+			"completed":   {Type: graphql.NewNonNull(graphql.Boolean), Resolve: taskField(func(t models.Task) interface{} { return t.Completed })},
+			"ownerId":     {Type: graphql.String, Resolve: taskField(func(t models.Task) interface{} { return optionalString(t.OwnerID) })},
+			"projectId":   {Type: graphql.Int, Resolve: taskField(func(t models.Task) interface{} { return optionalInt(t.ProjectID) })},
+			"createdAt":   {Type: graphql.NewNonNull(graphql.DateTime), Resolve: taskField(func(t models.Task) interface{} { return t.CreatedAt })},
+			"updatedAt":   {Type: graphql.NewNonNull(graphql.DateTime), Resolve: taskField(func(t models.Task) interface{} { return t.UpdatedAt })},
+			"completedAt": {Type: graphql.DateTime, Resolve: taskField(func(t models.Task) interface{} { return optionalTime(t.CompletedAt) })},
+			"dueDate":     {Type: graphql.DateTime, Resolve: taskField(func(t models.Task) interface{} { return optionalTime(t.DueDate) })},
+			"priority":    {Type: graphql.Int, Resolve: taskField(func(t models.Task) interface{} { return optionalInt(t.Priority) })},
+			"recurrence":  {Type: graphql.String, Resolve: taskField(func(t models.Task) interface{} { return optionalString(t.Recurrence) })},
+			"tags":        {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))), Resolve: taskField(func(t models.Task) interface{} { return append([]string{}, t.Tags...) })},
+			"history": {
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(revisionType))),
+				Description: "Revisiones de la tarea, de la más antigua a la más reciente",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					load := graphqlFromContext(p).loader.History(p.Source.(models.Task).ID)
+					return func() (interface{}, error) {
+						revisions, err := load()
+						if err != nil {
+							return nil, internalGraphQLError("obtener el historial", err)
+						}
+						return revisions, nil
+					}, nil
+				},
+			},
+			"comments": {
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(commentType))),
+				Description: "Comentarios de la tarea, del más antiguo al más reciente",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					load := graphqlFromContext(p).loader.Comments(p.Source.(models.Task).ID)
+					return func() (interface{}, error) {
+						comments, err := load()
+						if err != nil {
+							return nil, internalGraphQLError("obtener los comentarios", err)
+						}
+						return comments, nil
+					}, nil
+				},
+			},
+		},
+	})
+
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": {Type: graphql.NewNonNull(graphql.Boolean), Resolve: connectionField(func(c graphqlConnection) interface{} { return c.hasNextPage })},
+			"endCursor":   {Type: graphql.String, Resolve: connectionField(func(c graphqlConnection) interface{} { return optionalString(c.endCursor) })},
+		},
+	})
+
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "TaskConnection",
+		Description: "Página de tareas ordenadas por ID",
+		Fields: graphql.Fields{
+			"nodes":      {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(taskType))), Resolve: connectionField(func(c graphqlConnection) interface{} { return c.nodes })},
+			"totalCount": {Type: graphql.NewNonNull(graphql.Int), Resolve: connectionField(func(c graphqlConnection) interface{} { return c.totalCount })},
+			"pageInfo":   {Type: graphql.NewNonNull(pageInfoType), Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source, nil }},
+		},
+	})
+
+	pageArgs := graphql.FieldConfigArgument{
+		"first":     {Type: graphql.Int, DefaultValue: defaultGraphQLPageSize, Description: "Tareas por página, hasta " + strconv.Itoa(maxGraphQLPageSize)},
+		"after":     {Type: graphql.String, Description: "endCursor de la página anterior"},
+		"completed": {Type: graphql.Boolean},
+	}
+
+	projectType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Project",
+		Description: "Proyecto al que pertenecen las tareas",
+		Fields: graphql.Fields{
+			"id": {Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(int), nil }},
+			"tasks": {
+				Type: graphql.NewNonNull(connectionType),
+				Args: pageArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					load := graphqlFromContext(p).loader.ProjectTasks(p.Source.(int))
+					return func() (interface{}, error) {
+						tasks, err := load()
+						if err != nil {
+							return nil, internalGraphQLError("listar las tareas del proyecto", err)
+						}
+						return paginateTasks(filterCompleted(tasks, p.Args), p.Args)
+					}, nil
+				},
+			},
+		},
+	})
+
+	taskType.AddFieldConfig("project", &graphql.Field{
+		Type: projectType,
+		Resolve: taskField(func(t models.Task) interface{} {
+			if t.ProjectID == 0 {
+				return nil
+			}
+			return t.ProjectID
+		}),
+	})
+
+	tasksArgs := graphql.FieldConfigArgument{
+		"filter":    {Type: graphql.String, Description: "Expresión de filtro, como en GET /tasks?filter="},
+		"projectId": {Type: graphql.Int},
+	}
+	for name, arg := range pageArgs {
+		tasksArgs[name] = arg
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"task": {
+				Type: taskType,
+				Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.Int)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					task, err := h.store.Get(p.Context, p.Args["id"].(int))
+					if err == store.ErrNotFound {
+						return nil, nil
+					}
+					if err != nil {
+						return nil, internalGraphQLError("obtener la tarea", err)
+					}
+					if task.DeletedAt != nil || !task.VisibleTo(graphqlFromContext(p).source.userID) {
+						return nil, nil
+					}
+					return task, nil
+				},
+			},
+			"tasks": {
+				Type: graphql.NewNonNull(connectionType),
+				Args: tasksArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return h.graphqlTasks(p)
+				},
+			},
+			"project": {
+				Type: graphql.NewNonNull(projectType),
+				Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.Int)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Args["id"].(int), nil
+				},
+			},
+		},
+	})
+
+	taskInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "TaskInput",
+		Description: "Datos de una tarea; updateTask reemplaza la tarea como PUT /tasks/{id}",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":       {Type: graphql.NewNonNull(graphql.String)},
+			"description": {Type: graphql.String},
+			"completed":   {Type: graphql.Boolean},
+			"projectId":   {Type: graphql.Int},
+			"dueDate":     {Type: graphql.DateTime},
+			"priority":    {Type: graphql.Int, Description: "De 1, la más alta, a 9, la más baja"},
+			"recurrence":  {Type: graphql.String, Description: "Regla RRULE de iCalendar, como FREQ=WEEKLY"},
+			"tags":        {Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+		},
+	})
+
+	idArg := graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.Int)}}
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createTask": {
+				Type: graphql.NewNonNull(taskType),
+				Args: graphql.FieldConfigArgument{"input": {Type: graphql.NewNonNull(taskInput)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					task, err := h.createTask(p.Context, graphqlFromContext(p).source, taskFromInput(p.Args["input"]))
+					return task, mutationError("crear la tarea", err)
+				},
+			},
+			"updateTask": {
+				Type: graphql.NewNonNull(taskType),
+				Args: graphql.FieldConfigArgument{
+					"id":    {Type: graphql.NewNonNull(graphql.Int)},
+					"input": {Type: graphql.NewNonNull(taskInput)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					source := graphqlFromContext(p).source
+					task, err := h.updateTask(p.Context, source, p.Args["id"].(int), taskFromInput(p.Args["input"]), visibleCheck(source))
+					return task, mutationError("actualizar la tarea", err)
+				},
+			},
+			"setTaskCompleted": {
+				Type: graphql.NewNonNull(taskType),
+				Args: graphql.FieldConfigArgument{
+					"id":        {Type: graphql.NewNonNull(graphql.Int)},
+					"completed": {Type: graphql.NewNonNull(graphql.Boolean)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					source := graphqlFromContext(p).source
+					id, completed := p.Args["id"].(int), p.Args["completed"].(bool)
+					task, err := h.mutate(p.Context, source, models.ActionUpdate, func(tx store.Tx) (*models.Task, *models.Task, error) {
+						return txSetCompleted(tx, id, completed, visibleCheck(source))
+					})
+					if err != nil {
+						return nil, mutationError("actualizar la tarea", err)
+					}
+					return *task, nil
+				},
+			},
+			"deleteTask": {
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Mueve la tarea a la papelera",
+				Args:        idArg,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					source := graphqlFromContext(p).source
+					err := h.deleteTask(p.Context, source, p.Args["id"].(int), visibleCheck(source))
+					return err == nil, mutationError("eliminar la tarea", err)
+				},
+			},
+			"addComment": {
+				Type:        graphql.NewNonNull(commentType),
+				Description: "Agrega un comentario a una tarea visible",
+				Args: graphql.FieldConfigArgument{
+					"taskId": {Type: graphql.NewNonNull(graphql.Int)},
+					"body":   {Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					comment, err := h.addComment(p.Context, graphqlFromContext(p).source, p.Args["taskId"].(int), p.Args["body"].(string))
+					if err != nil {
+						return nil, mutationError("agregar el comentario", err)
+					}
+					return comment, nil
+				},
+			},
+			"restoreTask": {
+				Type:        graphql.NewNonNull(taskType),
+				Description: "Saca la tarea de la papelera",
+				Args:        idArg,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					source := graphqlFromContext(p).source
+					task, err := h.restoreTask(p.Context, source, p.Args["id"].(int), visibleCheck(source))
+					if err != nil {
+						return nil, mutationError("restaurar la tarea", err)
+					}
+					return *task, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// graphqlTasks resuelve la consulta tasks con el filtro y la página indicados
+func (h *TaskHandler) graphqlTasks(p graphql.ResolveParams) (interface{}, error) {
+	expression, _ := p.Args["filter"].(string)
+	visible, err := h.listVisible(p.Context, graphqlFromContext(p).source.userID, expression)
+	if _, invalid := err.(*filter.Error); invalid {
+		return nil, err
+	}
+	if err != nil {
+		return nil, internalGraphQLError("listar las tareas", err)
+	}
+
+	projectID, byProject := p.Args["projectId"].(int)
+	tasks := []models.Task{}
+	for _, task := range visible {
+		if !byProject || task.ProjectID == projectID {
+			tasks = append(tasks, task)
+		}
+	}
+	return paginateTasks(filterCompleted(tasks, p.Args), p.Args)
+}
+
+// paginateTasks devuelve la página de tareas que empieza después del cursor
+// after; las tareas deben estar ordenadas por ID
+func paginateTasks(tasks []models.Task, args map[string]interface{}) (graphqlConnection, error) {
+	first, _ := args["first"].(int)
+	if first < 1 || first > maxGraphQLPageSize {
+		return graphqlConnection{}, errors.New("first debe estar entre 1 y " + strconv.Itoa(maxGraphQLPageSize))
+	}
+
+	start := 0
+	if after, _ := args["after"].(string); after != "" {
+		afterID, err := decodeCursor(after)
+		if err != nil {
+			return graphqlConnection{}, err
+		}
+		for start < len(tasks) && tasks[start].ID <= afterID {
+			start++
+		}
+	}
+
+	end := start + first
+	if end > len(tasks) {
+		end = len(tasks)
+	}
+	page := graphqlConnection{
+		nodes:       tasks[start:end],
+		totalCount:  len(tasks),
+		hasNextPage: end < len(tasks),
+	}
+	if end > start {
+		page.endCursor = encodeCursor(tasks[end-1].ID)
+	}
+	return page, nil
+}
+
+// filterCompleted aplica el argumento completed si se indicó
+func filterCompleted(tasks []models.Task, args map[string]interface{}) []models.Task {
+	completed, ok := args["completed"].(bool)
+	if !ok {
+		return tasks
+	}
+	filtered := []models.Task{}
+	for _, task := range tasks {
+		if task.Completed == completed {
+			filtered = append(filtered, task)
+		}
+	}
+	return filtered
+}
+
+// encodeCursor convierte el ID de una tarea en un cursor opaco
+func encodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(id)))
+}
+
+// decodeCursor obtiene el ID de la tarea de un cursor
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, errInvalidCursor
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil {
+		return 0, errInvalidCursor
+	}
+	return id, nil
+}
+
+// taskFromInput convierte un TaskInput en una tarea
+func taskFromInput(value interface{}) models.Task {
+	input, _ := value.(map[string]interface{})
+	task := models.Task{}
+	task.Title, _ = input["title"].(string)
+	task.Description, _ = input["description"].(string)
+	task.Completed, _ = input["completed"].(bool)
+	task.ProjectID, _ = input["projectId"].(int)
+	if due, ok := input["dueDate"].(time.Time); ok {
+		task.DueDate = &due
+	}
+	task.Priority, _ = input["priority"].(int)
+	task.Recurrence, _ = input["recurrence"].(string)
+	tags, _ := input["tags"].([]interface{})
+	for _, tag := range tags {
+		if tag, ok := tag.(string); ok {
+			task.Tags = append(task.Tags, tag)
+		}
+	}
+	return task
+}
+
+// mutationError traduce los errores de las operaciones de tareas a los
+// mensajes que ve el cliente
+func mutationError(action string, err error) error {
+	if _, ok := err.(*invalidTaskError); ok {
+		return err
+	}
+	switch err {
+	case nil:
+		return nil
+	case errTaskNotFound:
+		return errors.New("tarea no encontrada")
+	case errTitleRequired, errNotInTrash, comments.ErrEmptyBody, comments.ErrBodyTooLong:
+		return err
+	}
+	return internalGraphQLError(action, err)
+}
+
+// internalGraphQLError registra el error y devuelve un mensaje genérico
+func internalGraphQLError(action string, err error) error {
+	log.Printf("Error al %s desde GraphQL: %v", action, err)
+	return errors.New("error al " + action)
+}
+
+// taskField crea un resolver que lee un campo de models.Task
+func taskField(get func(models.Task) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(models.Task)), nil
+	}
+}
+
+// commentField crea un resolver que lee un campo de models.Comment
+func commentField(get func(models.Comment) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(models.Comment)), nil
+	}
+}
+
+// revisionField crea un resolver que lee un campo de models.TaskRevision
+func revisionField(get func(models.TaskRevision) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(models.TaskRevision)), nil
+	}
+}
+
+// changeField crea un resolver que lee un campo de models.FieldChange
+func changeField(get func(models.FieldChange) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(models.FieldChange)), nil
+	}
+}
+
+// connectionField crea un resolver que lee un campo de una página de tareas
+func connectionField(get func(graphqlConnection) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(graphqlConnection)), nil
+	}
+}
+
+// optionalString devuelve nil para la cadena vacía
+func optionalString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// optionalInt devuelve nil para el cero
+func optionalInt(value int) interface{} {
+	if value == 0 {
+		return nil
+	}
+	return value
+}
+
+// optionalTime devuelve nil si no hay fecha
+func optionalTime(value *time.Time) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+// jsonValue codifica en JSON el valor de un cambio
+func jsonValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return string(encoded)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/claudio/todo-api/internal/comments"
+	"github.com/claudio/todo-api/internal/history"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/gorilla/mux"
+)
+
+// countingHistory cuenta las lecturas del historial
+type countingHistory struct {
+	history.Store
+	list, listMany int
+}
+
+func (c *countingHistory) List(ctx context.Context, taskID int) ([]models.TaskRevision, error) {
+	c.list++
+	return c.Store.List(ctx, taskID)
+}
+
+func (c *countingHistory) ListMany(ctx context.Context, taskIDs []int) (map[int][]models.TaskRevision, error) {
+	c.listMany++
+	return c.Store.ListMany(ctx, taskIDs)
+}
+
+// countingComments cuenta las lecturas de comentarios
+type countingComments struct {
+	comments.Store
+	listMany int
+}
+
+func (c *countingComments) ListMany(ctx context.Context, taskIDs []int) (map[int][]models.Comment, error) {
+	c.listMany++
+	return c.Store.ListMany(ctx, taskIDs)
+}
+
+func TestGraphQL(t *testing.T) {
+	revisions := &countingHistory{Store: history.NewMemoryStore()}
+	notes := &countingComments{Store: comments.NewMemoryStore()}
+	taskHandler := NewTaskHandler(WithHistoryStore(revisions), WithCommentStore(notes))
+	router := mux.NewRouter()
+	router.HandleFunc("/api/graphql", NewGraphQLHandler(taskHandler).ExecuteGraphQL).Methods("POST")
+
+	do := func(query string, variables map[string]interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+		body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+		req, _ := http.NewRequest("POST", "/api/graphql", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		var response map[string]interface{}
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		return rr, response
+	}
+
+	// Las mutaciones usan las operaciones de TaskHandler
+	for _, title := range []string{"Primera del proyecto", "Segunda del proyecto"} {
+		rr, response := do(`mutation($title: String!) { createTask(input: {title: $title, projectId: 7}) { id projectId } }`,
+			map[string]interface{}{"title": title})
+		if rr.Code != http.StatusOK || response["errors"] != nil {
+			t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v (%v)", rr.Code, http.StatusOK, response["errors"])
+		}
+	}
+	_, response := do(`mutation { setTaskCompleted(id: 3, completed: true) { completed completedAt } }`, nil)
+	if task := response["data"].(map[string]interface{})["setTaskCompleted"].(map[string]interface{}); task["completedAt"] == nil {
+		t.Errorf("La tarea completada no tiene completedAt: %v", task)
+	}
+	_, response = do(`mutation { updateTask(id: 99, input: {title: "x"}) { id } }`, nil)
+	if errs, _ := response["errors"].([]interface{}); len(errs) != 1 {
+		t.Errorf("Se esperaba un error al actualizar una tarea inexistente: %v", response)
+	}
+
+	_, response = do(`mutation { addComment(taskId: 3, body: "  Revisar mañana  ") { id author body } }`, nil)
+	if comment := response["data"].(map[string]interface{})["addComment"].(map[string]interface{}); comment["body"] != "Revisar mañana" || comment["author"] != "anonymous" {
+		t.Errorf("Comentario incorrecto: %v", comment)
+	}
+	_, response = do(`mutation { addComment(taskId: 99, body: "x") { id } }`, nil)
+	if errs, _ := response["errors"].([]interface{}); len(errs) != 1 {
+		t.Errorf("Se esperaba un error al comentar una tarea inexistente: %v", response)
+	}
+
+	// Paginación con cursor y relaciones anidadas: el historial y los
+	// comentarios de todas las tareas de la página se leen con una sola
+	// llamada a cada almacén
+	_, response = do(`{ tasks(first: 2, filter: "title:proyecto") {
+		totalCount
+		pageInfo { hasNextPage endCursor }
+		nodes { id project { id tasks(completed: true) { totalCount } } history { revision action } comments { body } }
+	} }`, nil)
+	connection := response["data"].(map[string]interface{})["tasks"].(map[string]interface{})
+	nodes := connection["nodes"].([]interface{})
+	if connection["totalCount"].(float64) != 2 || len(nodes) != 2 {
+		t.Fatalf("Página incorrecta: %v", connection)
+	}
+	project := nodes[0].(map[string]interface{})["project"].(map[string]interface{})
+	if project["tasks"].(map[string]interface{})["totalCount"].(float64) != 1 {
+		t.Errorf("Tareas completadas del proyecto incorrectas: %v", project)
+	}
+	if revisions.listMany != 1 || revisions.list != 0 {
+		t.Errorf("El historial debió leerse en un solo lote: List=%d ListMany=%d", revisions.list, revisions.listMany)
+	}
+	if notes.listMany != 1 {
+		t.Errorf("Los comentarios debieron leerse en un solo lote: ListMany=%d", notes.listMany)
+	}
+	if comments := nodes[0].(map[string]interface{})["comments"].([]interface{}); len(comments) != 1 {
+		t.Errorf("Comentarios de la tarea incorrectos: %v", nodes[0])
+	}
+
+	_, response = do(`query($after: String) { tasks(first: 1, after: $after) { nodes { id } pageInfo { hasNextPage } } }`,
+		map[string]interface{}{"after": encodeCursor(3)})
+	connection = response["data"].(map[string]interface{})["tasks"].(map[string]interface{})
+	if nodes := connection["nodes"].([]interface{}); len(nodes) != 1 || nodes[0].(map[string]interface{})["id"].(float64) != 4 {
+		t.Errorf("La página después del cursor es incorrecta: %v", connection)
+	}
+
+	// Las consultas demasiado profundas o costosas no se ejecutan
+	deep := `{ task(id: 1) { project { tasks { nodes { project { tasks { nodes { project { tasks { nodes { id } } } } } } } } } } }`
+	if rr, response := do(deep, nil); rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "profundidad") {
+		t.Errorf("Se esperaba rechazar la consulta profunda: %v %v", rr.Code, response)
+	}
+	costly := `{ tasks(first: 100) { nodes { history { changes { field from to } } } } }`
+	if rr, _ := do(costly, nil); rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "complejidad") {
+		t.Errorf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"log"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/events"
+	"github.com/claudio/todo-api/internal/filter"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/taskspb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TaskService implementa el servicio gRPC de tareas con las mismas
+// operaciones que la API REST, de modo que la validación, la auditoría, el
+// historial y los eventos son idénticos en ambos transportes
+type TaskService struct {
+	taskspb.UnimplementedTaskServiceServer
+	tasks *TaskHandler
+	bus   *events.Bus
+}
+
+// NewTaskService crea el servicio gRPC sobre el manejador de tareas y el bus
+// de eventos del que Watch recibe los cambios
+func NewTaskService(tasks *TaskHandler, bus *events.Bus) *TaskService {
+	return &TaskService{tasks: tasks, bus: bus}
+}
+
+// Get devuelve una tarea visible que no está en la papelera
+func (s *TaskService) Get(ctx context.Context, req *taskspb.GetTaskRequest) (*taskspb.Task, error) {
+	source := sourceFromGRPC(ctx)
+	task, err := s.tasks.store.Get(ctx, int(req.GetId()))
+	if err == nil && (task.DeletedAt != nil || !task.VisibleTo(source.userID)) {
+		err = errTaskNotFound
+	}
+	if err != nil {
+		return nil, grpcError("obtener la tarea", err)
+	}
+	return taskToProto(task), nil
+}
+
+// List envía las tareas visibles que cumplen el filtro, ordenadas por ID
+func (s *TaskService) List(req *taskspb.ListTasksRequest, stream taskspb.TaskService_ListServer) error {
+	source := sourceFromGRPC(stream.Context())
+	tasks, err := s.tasks.listVisible(stream.Context(), source.userID, req.GetFilter())
+	if err != nil {
+		return grpcError("listar las tareas", err)
+	}
+
+	for _, task := range tasks {
+		if req.Completed != nil && task.Completed != req.GetCompleted() {
+			continue
+		}
+		if req.GetProjectId() != 0 && int64(task.ProjectID) != req.GetProjectId() {
+			continue
+		}
+		if err := stream.Send(taskToProto(task)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Create crea una tarea propiedad del usuario autenticado
+func (s *TaskService) Create(ctx context.Context, req *taskspb.CreateTaskRequest) (*taskspb.Task, error) {
+	task, err := s.tasks.createTask(ctx, sourceFromGRPC(ctx), taskFromProto(req.GetTask()))
+	if err != nil {
+		return nil, grpcError("crear la tarea", err)
+	}
+	return taskToProto(task), nil
+}
+
+// Update reemplaza una tarea visible
+func (s *TaskService) Update(ctx context.Context, req *taskspb.UpdateTaskRequest) (*taskspb.Task, error) {
+	source := sourceFromGRPC(ctx)
+	task, err := s.tasks.updateTask(ctx, source, int(req.GetId()), taskFromProto(req.GetTask()), visibleCheck(source))
+	if err != nil {
+		return nil, grpcError("actualizar la tarea", err)
+	}
+	return taskToProto(task), nil
+}
+
+// Delete mueve una tarea visible a la papelera
+func (s *TaskService) Delete(ctx context.Context, req *taskspb.DeleteTaskRequest) (*taskspb.DeleteTaskResponse, error) {
+	source := sourceFromGRPC(ctx)
+	if err := s.tasks.deleteTask(ctx, source, int(req.GetId()), visibleCheck(source)); err != nil {
+		return nil, grpcError("eliminar la tarea", err)
+	}
+	return &taskspb.DeleteTaskResponse{}, nil
+}
+
+// Watch envía los eventos de las tareas visibles, empezando por los que
+// siguen en el buffer del bus después de last_event_id. Si el cliente no
+// consume a tiempo la llamada termina con Unavailable y puede reanudarse.
+func (s *TaskService) Watch(req *taskspb.WatchTasksRequest, stream taskspb.TaskService_WatchServer) error {
+	source := sourceFromGRPC(stream.Context())
+	projectID := int(req.GetProjectId())
+	wants := func(event models.TaskEvent) bool {
+		return event.VisibleTo(source.userID) && (projectID == 0 || event.Task.ProjectID == projectID)
+	}
+
+	sub, missed, complete := s.bus.Subscribe(req.GetLastEventId(), wants)
+	defer s.bus.Unsubscribe(sub)
+	if !complete {
+		return status.Error(codes.OutOfRange, "Algunos eventos posteriores a last_event_id ya no están disponibles")
+	}
+
+	for _, event := range missed {
+		if err := stream.Send(eventToProto(event)); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-sub.C:
+			if !ok {
+				return status.Error(codes.Unavailable, "Cliente demasiado lento; reanude con last_event_id")
+			}
+			if err := stream.Send(eventToProto(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sourceFromGRPC obtiene el origen de una modificación hecha por gRPC
+func sourceFromGRPC(ctx context.Context) changeSource {
+	source := changeSource{actor: "anonymous"}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get("x-request-id"); len(ids) > 0 {
+			source.requestID = ids[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		source.ip = p.Addr.String()
+	}
+	if identity, ok := auth.FromContext(ctx); ok {
+		source.userID = identity.UserID
+		source.actor = identity.UserID
+	}
+	return source
+}
+
+// grpcError traduce los errores de las operaciones de tareas a códigos gRPC
+func grpcError(action string, err error) error {
+	switch err.(type) {
+	case *filter.Error, *invalidTaskError:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	switch err {
+	case errTaskNotFound:
+		return status.Error(codes.NotFound, "Tarea no encontrada")
+	case errTitleRequired:
+		return status.Error(codes.InvalidArgument, "El título es obligatorio")
+	}
+	log.Printf("Error al %s desde gRPC: %v", action, err)
+	return status.Error(codes.Internal, "Error al "+action)
+}
+
+// taskToProto convierte una tarea al mensaje de protobuf
+func taskToProto(task models.Task) *taskspb.Task {
+	message := &taskspb.Task{
+		Id:          int64(task.ID),
+		Title:       task.Title,
+		Description: task.Description,
+		Completed:   task.Completed,
+		OwnerId:     task.OwnerID,
+		ProjectId:   int64(task.ProjectID),
+		Priority:    int32(task.Priority),
+		Recurrence:  task.Recurrence,
+		Tags:        task.Tags,
+		CreatedAt:   timestamppb.New(task.CreatedAt),
+		UpdatedAt:   timestamppb.New(task.UpdatedAt),
+	}
+	if task.CompletedAt != nil {
+		message.CompletedAt = timestamppb.New(*task.CompletedAt)
+	}
+	if task.DueDate != nil {
+		message.DueDate = timestamppb.New(*task.DueDate)
+	}
+	return message
+}
+
+// taskFromProto convierte los campos asignables por el cliente en una tarea
+func taskFromProto(input *taskspb.TaskInput) models.Task {
+	task := models.Task{
+		Title:       input.GetTitle(),
+		Description: input.GetDescription(),
+		Completed:   input.GetCompleted(),
+		ProjectID:   int(input.GetProjectId()),
+		Priority:    int(input.GetPriority()),
+		Recurrence:  input.GetRecurrence(),
+		Tags:        input.GetTags(),
+	}
+	if input.GetDueDate() != nil {
+		due := input.GetDueDate().AsTime()
+		task.DueDate = &due
+	}
+	return task
+}
+
+// eventToProto convierte un evento del bus al mensaje de protobuf
+func eventToProto(event models.TaskEvent) *taskspb.TaskEvent {
+	message := &taskspb.TaskEvent{
+		Id:         event.ID,
+		Type:       event.Type,
+		TaskId:     int64(event.TaskID),
+		Actor:      event.Actor,
+		OccurredAt: timestamppb.New(event.OccurredAt),
+	}
+	if event.Task != nil {
+		message.Task = taskToProto(*event.Task)
+	}
+	return message
+}
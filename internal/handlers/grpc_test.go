@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/events"
+	"github.com/claudio/todo-api/internal/middleware"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/taskspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestTaskServiceOverBufconn(t *testing.T) {
+	sessions := auth.NewMemorySessionStore()
+	tokens, _ := sessions.CreateSession(context.Background(), "ana", "test", "127.0.0.1")
+	bus := events.NewBus(100)
+	taskHandler := NewTaskHandler()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(middleware.GRPCUnaryAuth(sessions)),
+		grpc.ChainStreamInterceptor(middleware.GRPCStreamAuth(sessions)),
+	)
+	taskspb.RegisterTaskServiceServer(server, NewTaskService(taskHandler, bus))
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("No se pudo conectar: %v", err)
+	}
+	defer conn.Close()
+	client := taskspb.NewTaskServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ana := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tokens.AccessToken)
+
+	// Un token inválido se rechaza; sin token la llamada es anónima
+	_, err = client.Get(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer otro"), &taskspb.GetTaskRequest{Id: 1})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Código incorrecto con un token inválido: obtuvo %v, esperaba %v", status.Code(err), codes.Unauthenticated)
+	}
+
+	// La validación es la misma que en la API REST
+	_, err = client.Create(ana, &taskspb.CreateTaskRequest{Task: &taskspb.TaskInput{}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Código incorrecto sin título: obtuvo %v, esperaba %v", status.Code(err), codes.InvalidArgument)
+	}
+	created, err := client.Create(ana, &taskspb.CreateTaskRequest{Task: &taskspb.TaskInput{Title: "Privada", ProjectId: 4}})
+	if err != nil || created.Id != 3 || created.OwnerId != "ana" {
+		t.Fatalf("Creación incorrecta: %v %v", created, err)
+	}
+	if _, err := client.Get(ctx, &taskspb.GetTaskRequest{Id: created.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("Un usuario anónimo no debe ver la tarea privada: %v", err)
+	}
+
+	// Update reemplaza la tarea: la fecha límite, la prioridad, la
+	// recurrencia y las etiquetas enviadas se conservan y se devuelven
+	due := timestamppb.New(time.Date(2030, 5, 1, 9, 0, 0, 0, time.UTC))
+	updated, err := client.Update(ana, &taskspb.UpdateTaskRequest{Id: created.Id, Task: &taskspb.TaskInput{
+		Title: "Privada", Completed: true, DueDate: due, Priority: 3, Recurrence: "FREQ=WEEKLY", Tags: []string{"work"},
+	}})
+	if err != nil || !updated.Completed || updated.CompletedAt == nil {
+		t.Fatalf("Actualización incorrecta: %v %v", updated, err)
+	}
+	fetched, err := client.Get(ana, &taskspb.GetTaskRequest{Id: created.Id})
+	if err != nil || !fetched.DueDate.AsTime().Equal(due.AsTime()) || fetched.Priority != 3 || fetched.Recurrence != "FREQ=WEEKLY" ||
+		len(fetched.Tags) != 1 || fetched.Tags[0] != "work" {
+		t.Errorf("La fecha límite, la prioridad, la recurrencia o las etiquetas no se conservaron: %v %v", fetched, err)
+	}
+
+	// List envía las tareas visibles por streaming
+	stream, err := client.List(ana, &taskspb.ListTasksRequest{Completed: boolPtr(true)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []int64{}
+	for {
+		task, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, task.Id)
+	}
+	if len(ids) != 2 || ids[0] != 2 || ids[1] != 3 {
+		t.Errorf("Tareas completadas incorrectas: %v", ids)
+	}
+
+	// Watch reanuda desde last_event_id con los eventos que siguen en el bus
+	bus.Publish(models.TaskEvent{Type: models.EventTaskCreated, TaskID: 3, Task: &models.Task{ID: 3, ProjectID: 4, OwnerID: "ana"}})
+	bus.Publish(models.TaskEvent{Type: models.EventTaskUpdated, TaskID: 1, Task: &models.Task{ID: 1, ProjectID: 9}})
+	bus.Publish(models.TaskEvent{Type: models.EventTaskDeleted, TaskID: 3, Task: &models.Task{ID: 3, ProjectID: 4, OwnerID: "ana"}})
+	watch, err := client.Watch(ana, &taskspb.WatchTasksRequest{ProjectId: 4, LastEventId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err := watch.Recv()
+	if err != nil || event.TaskId != 3 || event.Type != models.EventTaskDeleted {
+		t.Errorf("Evento incorrecto: %v %v", event, err)
+	}
+
+	if _, err := client.Delete(ctx, &taskspb.DeleteTaskRequest{Id: created.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("Un usuario anónimo no debe eliminar la tarea privada: %v", err)
+	}
+	if _, err := client.Delete(ana, &taskspb.DeleteTaskRequest{Id: created.Id}); err != nil {
+		t.Errorf("Error al eliminar: %v", err)
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/claudio/todo-api/internal/apiversion"
+	"github.com/claudio/todo-api/internal/history"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/store"
+	"github.com/gorilla/mux"
+)
+
+// GetTaskHistory devuelve las revisiones de una tarea con los cambios de cada una
+func (h *TaskHandler) GetTaskHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	if !h.writeVisible(w, r, id) {
+		return
+	}
+
+	revisions, err := h.history.List(r.Context(), id)
+	if err != nil {
+		log.Printf("Error al consultar el historial de la tarea %d: %v", id, err)
+		http.Error(w, "Error al consultar el historial", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(apiversion.EncodeRevisions(taskCodec(r), revisions))
+}
+
+// RestoreTaskRevision revierte una tarea al estado de una revisión anterior.
+// Si la tarea está en la papelera se recupera, y si fue purgada se vuelve a
+// crear con el mismo ID.
+func (h *TaskHandler) RestoreTaskRevision(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+	number, err := strconv.Atoi(params["revision"])
+	if err != nil {
+		http.Error(w, "Revisión inválida", http.StatusBadRequest)
+		return
+	}
+
+	if !h.writeVisible(w, r, id) {
+		return
+	}
+
+	revision, err := h.history.Get(r.Context(), id, number)
+	if err == history.ErrNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error al consultar la revisión %d de la tarea %d: %v", number, id, err)
+		http.Error(w, "Error al consultar la revisión", http.StatusInternalServerError)
+		return
+	}
+	if revision.Action == models.ActionDelete {
+		http.Error(w, "No se puede restaurar una revisión de eliminación", http.StatusBadRequest)
+		return
+	}
+
+	source := sourceFromRequest(r)
+	restored, err := h.mutate(r.Context(), source, models.ActionRestore, func(tx store.Tx) (*models.Task, *models.Task, error) {
+		restored := revision.Task
+		restored.ID = id
+		restored.UpdatedAt = time.Now()
+		restored.DeletedAt = nil
+
+		var before *models.Task
+		current, err := tx.Get(id)
+		switch err {
+		case nil:
+			if !current.VisibleTo(source.userID) {
+				return nil, nil, errTaskNotFound
+			}
+			before = &current
+			restored.CreatedAt = current.CreatedAt
+			restored.OwnerID = current.OwnerID
+		case store.ErrNotFound:
+		default:
+			return nil, nil, err
+		}
+
+		if err := tx.Save(&restored); err != nil {
+			return nil, nil, err
+		}
+		return before, &restored, nil
+	})
+	if err == errTaskNotFound {
+		http.Error(w, "Tarea no encontrada", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error al restaurar la revisión %d de la tarea %d: %v", number, id, err)
+		http.Error(w, "Error al restaurar la revisión", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(taskCodec(r).EncodeTask(*restored))
+}
+
+// getTaskAsOf responde con el estado que tenía una tarea en el instante indicado
+func (h *TaskHandler) getTaskAsOf(w http.ResponseWriter, r *http.Request, format string, id int, value string) {
+	asOf, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		http.Error(w, "as_of debe tener formato RFC3339", http.StatusBadRequest)
+		return
+	}
+	if !h.writeVisible(w, r, id) {
+		return
+	}
+
+	revision, err := h.history.AsOf(r.Context(), id, asOf)
+	if err == history.ErrNotFound {
+		// Una tarea sin revisiones no ha cambiado desde que se creó
+		if task, err := h.store.Get(r.Context(), id); err == nil && !task.CreatedAt.After(asOf) {
+			respond(w, format, http.StatusOK, taskCodec(r).EncodeTask(task))
+			return
+		}
+		http.Error(w, "Tarea no encontrada", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error al consultar el historial de la tarea %d: %v", id, err)
+		http.Error(w, "Error al consultar el historial", http.StatusInternalServerError)
+		return
+	}
+	if revision.Action == models.ActionDelete {
+		http.Error(w, "Tarea no encontrada", http.StatusNotFound)
+		return
+	}
+
+	respond(w, format, http.StatusOK, taskCodec(r).EncodeTask(revision.Task))
+}
+
+// writeVisible responde 404 si la tarea no es visible para el usuario e
+// indica si la solicitud puede continuar
+func (h *TaskHandler) writeVisible(w http.ResponseWriter, r *http.Request, id int) bool {
+	err := h.checkVisible(r.Context(), id, sourceFromRequest(r).userID)
+	if err == errTaskNotFound {
+		http.Error(w, "Tarea no encontrada", http.StatusNotFound)
+		return false
+	}
+	if err != nil {
+		log.Printf("Error al obtener la tarea %d: %v", id, err)
+		http.Error(w, "Error al obtener la tarea", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
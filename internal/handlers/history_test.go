@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/gorilla/mux"
+)
+
+func TestRestoreTaskRevision(t *testing.T) {
+	taskHandler := NewTaskHandler()
+	router := mux.NewRouter()
+	router.HandleFunc("/api/tasks", taskHandler.CreateTask).Methods("POST")
+	router.HandleFunc("/api/tasks/{id:[0-9]+}", taskHandler.UpdateTask).Methods("PUT")
+	router.HandleFunc("/api/tasks/{id:[0-9]+}/history", taskHandler.GetTaskHistory).Methods("GET")
+	router.HandleFunc("/api/tasks/{id:[0-9]+}/history/{revision:[0-9]+}/restore", taskHandler.RestoreTaskRevision).Methods("POST")
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Crear la tarea y sobrescribirla
+	do("POST", "/api/tasks", `{"title":"Original"}`)
+	do("PUT", "/api/tasks/3", `{"title":"Modificada"}`)
+
+	// El historial debe tener dos revisiones
+	var revisions []models.TaskRevision
+	json.Unmarshal(do("GET", "/api/tasks/3/history", "").Body.Bytes(), &revisions)
+	if len(revisions) != 2 {
+		t.Fatalf("Se esperaban 2 revisiones, obtuvo %d", len(revisions))
+	}
+	if revisions[1].Changes[0].Field != "title" {
+		t.Errorf("Cambios incorrectos: %+v", revisions[1].Changes)
+	}
+
+	// Restaurar la primera revisión
+	rr := do("POST", "/api/tasks/3/history/1/restore", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusOK)
+	}
+
+	var restored models.Task
+	json.Unmarshal(rr.Body.Bytes(), &restored)
+	if restored.Title != "Original" {
+		t.Errorf("Título incorrecto: obtuvo %v, esperaba Original", restored.Title)
+	}
+	if task, err := taskHandler.store.Get(context.Background(), 3); err != nil || task.Title != "Original" {
+		t.Errorf("La tarea no fue restaurada: %+v", task)
+	}
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/history"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/reports"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// defaultReportDays es la cantidad de días de un informe sin rango
+	defaultReportDays = 14
+	// maxReportDays es el máximo de días de un informe
+	maxReportDays = 366
+)
+
+// ReportHandler genera los informes de los proyectos a partir del historial
+// de revisiones de sus tareas
+type ReportHandler struct {
+	history history.Store
+}
+
+// NewReportHandler crea una nueva instancia de ReportHandler
+func NewReportHandler(historyStore history.Store) *ReportHandler {
+	return &ReportHandler{history: historyStore}
+}
+
+// reportRequest son los parámetros comunes de los informes de un proyecto
+type reportRequest struct {
+	projectID int
+	userID    string
+	from, to  time.Time
+	days      []time.Time
+	csv       bool
+}
+
+// GetBurndown devuelve las tareas pendientes del proyecto al final de cada
+// día entre from y to (ambos incluidos), en JSON o en CSV con ?format=csv
+func (h *ReportHandler) GetBurndown(w http.ResponseWriter, r *http.Request) {
+	req, revisions, ok := h.loadReport(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	points := reports.Burndown(revisions, req.projectID, req.userID, req.days, now)
+	if req.csv {
+		rows := [][]string{{"date", "scope", "remaining", "done", "ideal"}}
+		for _, p := range points {
+			rows = append(rows, []string{p.Date, strconv.Itoa(p.Scope), strconv.Itoa(p.Remaining),
+				strconv.Itoa(p.Done), strconv.FormatFloat(p.Ideal, 'f', 2, 64)})
+		}
+		writeReportCSV(w, req, "burndown", rows)
+		return
+	}
+	writeReportJSON(w, req, points, now)
+}
+
+// GetCumulativeFlow devuelve las tareas del proyecto abiertas y terminadas
+// al final de cada día entre from y to, en JSON o en CSV con ?format=csv
+func (h *ReportHandler) GetCumulativeFlow(w http.ResponseWriter, r *http.Request) {
+	req, revisions, ok := h.loadReport(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	points := reports.CumulativeFlow(revisions, req.projectID, req.userID, req.days, now)
+	if req.csv {
+		rows := [][]string{{"date", "open", "done"}}
+		for _, p := range points {
+			rows = append(rows, []string{p.Date, strconv.Itoa(p.Open), strconv.Itoa(p.Done)})
+		}
+		writeReportCSV(w, req, "cumulative-flow", rows)
+		return
+	}
+	writeReportJSON(w, req, points, now)
+}
+
+// loadReport valida los parámetros y obtiene el historial del proyecto
+func (h *ReportHandler) loadReport(w http.ResponseWriter, r *http.Request) (reportRequest, []models.TaskRevision, bool) {
+	identity, _ := auth.FromContext(r.Context())
+	params := r.URL.Query()
+
+	projectID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || projectID <= 0 {
+		http.Error(w, "ID de proyecto inválido", http.StatusBadRequest)
+		return reportRequest{}, nil, false
+	}
+	req := reportRequest{
+		projectID: projectID,
+		userID:    identity.UserID,
+		csv:       params.Get("format") == "csv" || strings.Contains(r.Header.Get("Accept"), "text/csv"),
+	}
+
+	now := time.Now().UTC()
+	req.to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if value := params.Get("to"); value != "" {
+		if req.to, err = time.Parse("2006-01-02", value); err != nil {
+			http.Error(w, "Fecha to inválida: use el formato 2006-01-02", http.StatusBadRequest)
+			return req, nil, false
+		}
+	}
+	req.from = req.to.AddDate(0, 0, 1-defaultReportDays)
+	if value := params.Get("from"); value != "" {
+		if req.from, err = time.Parse("2006-01-02", value); err != nil {
+			http.Error(w, "Fecha from inválida: use el formato 2006-01-02", http.StatusBadRequest)
+			return req, nil, false
+		}
+	}
+	if req.from.After(req.to) {
+		http.Error(w, "from no puede ser posterior a to", http.StatusBadRequest)
+		return req, nil, false
+	}
+	if req.to.Sub(req.from) >= maxReportDays*24*time.Hour {
+		http.Error(w, "El rango admite como máximo "+strconv.Itoa(maxReportDays)+" días", http.StatusBadRequest)
+		return req, nil, false
+	}
+	req.days = reports.Days(req.from, req.to)
+
+	revisions, err := h.history.ForProject(r.Context(), projectID, req.to.AddDate(0, 0, 1))
+	if err != nil {
+		log.Printf("Error al leer el historial del proyecto %d: %v", projectID, err)
+		http.Error(w, "Error al generar el informe", http.StatusInternalServerError)
+		return req, nil, false
+	}
+	return req, revisions, true
+}
+
+// writeReportJSON envía los puntos del informe en JSON
+func writeReportJSON(w http.ResponseWriter, req reportRequest, points interface{}, now time.Time) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ProjectReport{
+		ProjectID:   req.projectID,
+		From:        req.from.Format("2006-01-02"),
+		To:          req.to.Format("2006-01-02"),
+		Points:      points,
+		GeneratedAt: now,
+	})
+}
+
+// writeReportCSV envía las filas del informe como CSV descargable
+func writeReportCSV(w http.ResponseWriter, req reportRequest, name string, rows [][]string) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="project-%d-%s.csv"`, req.projectID, name))
+
+	writer := csv.NewWriter(w)
+	writer.WriteAll(rows)
+	if err := writer.Error(); err != nil {
+		log.Printf("Error al escribir el informe %s: %v", name, err)
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/claudio/todo-api/internal/apiversion"
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/search"
+)
+
+const (
+	// defaultSearchLimit es la cantidad de resultados devueltos por defecto
+	defaultSearchLimit = 20
+	// maxSearchLimit es el máximo de resultados que se pueden pedir
+	maxSearchLimit = 100
+	// snippetLength es la longitud máxima de los fragmentos resaltados
+	snippetLength = 160
+)
+
+// SearchTasks busca tareas por texto en el título y la descripción. El
+// parámetro q admite frases entre comillas y prefijos terminados en *; las
+// tareas deben cumplir todas las condiciones.
+func (h *TaskHandler) SearchTasks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	text := r.URL.Query().Get("q")
+	query := search.Parse(text)
+	if query.Empty() {
+		http.Error(w, "El parámetro q es obligatorio", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxSearchLimit {
+			http.Error(w, "El límite debe estar entre 1 y "+strconv.Itoa(maxSearchLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	identity, _ := auth.FromContext(r.Context())
+	hits, err := h.store.Search(r.Context(), query, identity.UserID, limit)
+	if err != nil {
+		log.Printf("Error al buscar tareas: %v", err)
+		http.Error(w, "Error al buscar tareas", http.StatusInternalServerError)
+		return
+	}
+
+	for i := range hits {
+		highlights := map[string]string{}
+		if snippet := search.Highlight(hits[i].Task.Title, query, snippetLength); snippet != "" {
+			highlights["title"] = snippet
+		}
+		if snippet := search.Highlight(hits[i].Task.Description, query, snippetLength); snippet != "" {
+			highlights["description"] = snippet
+		}
+		hits[i].Highlights = highlights
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"query":   text,
+		"results": apiversion.EncodeSearchHits(taskCodec(r), hits),
+	})
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/store"
+)
+
+const (
+	// defaultStatsDays y defaultStatsWeeks son el tamaño predeterminado de
+	// las series diarias y semanales
+	defaultStatsDays  = 30
+	defaultStatsWeeks = 12
+	// maxStatsBuckets es el máximo de intervalos de una serie
+	maxStatsBuckets = 366
+)
+
+// GetStats devuelve estadísticas de las tareas visibles para el usuario:
+// totales, vencidas, tareas por proyecto y por etiqueta, porcentaje de
+// completadas, tiempo promedio hasta completar y una serie diaria o semanal (?bucket=day|week) entre
+// from y to (fechas 2006-01-02, to exclusivo)
+func (h *TaskHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	identity, _ := auth.FromContext(r.Context())
+	params := r.URL.Query()
+
+	query := models.StatsQuery{UserID: identity.UserID, Bucket: params.Get("bucket")}
+	if query.Bucket == "" {
+		query.Bucket = models.StatsDaily
+	}
+	if query.Bucket != models.StatsDaily && query.Bucket != models.StatsWeekly {
+		http.Error(w, "El intervalo debe ser day o week", http.StatusBadRequest)
+		return
+	}
+
+	// Por defecto la serie termina con el intervalo actual
+	query.To = store.BucketStart(time.Now(), query.Bucket).AddDate(0, 0, bucketDays(query.Bucket))
+	if value := params.Get("to"); value != "" {
+		to, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			http.Error(w, "Fecha to inválida: use el formato 2006-01-02", http.StatusBadRequest)
+			return
+		}
+		query.To = to
+	}
+	if query.Bucket == models.StatsDaily {
+		query.From = query.To.AddDate(0, 0, -defaultStatsDays)
+	} else {
+		query.From = query.To.AddDate(0, 0, -7*defaultStatsWeeks)
+	}
+	if value := params.Get("from"); value != "" {
+		from, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			http.Error(w, "Fecha from inválida: use el formato 2006-01-02", http.StatusBadRequest)
+			return
+		}
+		query.From = from
+	}
+
+	// Los intervalos quedan completos aunque from caiga a mitad de semana
+	query.From = store.BucketStart(query.From, query.Bucket)
+	if !query.From.Before(query.To) {
+		http.Error(w, "from debe ser anterior a to", http.StatusBadRequest)
+		return
+	}
+	if buckets := int(query.To.Sub(query.From).Hours()/24) / bucketDays(query.Bucket); buckets > maxStatsBuckets {
+		http.Error(w, "El rango admite como máximo "+strconv.Itoa(maxStatsBuckets)+" intervalos", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.store.Stats(r.Context(), query)
+	if err != nil {
+		log.Printf("Error al calcular las estadísticas: %v", err)
+		http.Error(w, "Error al calcular las estadísticas", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(stats)
+}
+
+// bucketDays devuelve la cantidad de días de un intervalo
+func bucketDays(bucket string) int {
+	if bucket == models.StatsWeekly {
+		return 7
+	}
+	return 1
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/gorilla/mux"
+)
+
+func TestGetStats(t *testing.T) {
+	taskHandler := NewTaskHandler()
+	router := mux.NewRouter()
+	router.HandleFunc("/api/stats", taskHandler.GetStats).Methods("GET")
+	router.HandleFunc("/api/tasks", taskHandler.CreateTask).Methods("POST")
+	router.HandleFunc("/api/tasks/{id:[0-9]+}", taskHandler.UpdateTask).Methods("PUT")
+
+	// Completar la primera tarea de ejemplo asigna completed_at
+	body := []byte(`{"title":"Ejemplo de tarea 1","completed":true,"tags":["work"]}`)
+	req, _ := http.NewRequest("PUT", "/api/tasks/1", bytes.NewBuffer(body))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	req, _ = http.NewRequest("GET", "/api/stats?bucket=week", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusOK)
+	}
+
+	var stats models.TaskStats
+	json.Unmarshal(rr.Body.Bytes(), &stats)
+	if stats.Total != 2 || stats.Completed != 2 || stats.CompletionRate != 1 || stats.AvgLeadTimeHours == nil {
+		t.Errorf("Totales incorrectos: %+v", stats)
+	}
+	if len(stats.Series) != 12 {
+		t.Fatalf("Se esperaban 12 semanas, obtuvo %d", len(stats.Series))
+	}
+	if last := stats.Series[11]; last.Created != 2 || last.Completed != 2 || last.CompletionRate != 1 {
+		t.Errorf("Semana actual incorrecta: %+v", last)
+	}
+	if len(stats.ByProject) != 1 || stats.ByProject[0].Total != 2 {
+		t.Errorf("Proyectos incorrectos: %+v", stats.ByProject)
+	}
+
+	// Una tarea pendiente con la fecha de vencimiento pasada cuenta como vencida
+	body = []byte(`{"title":"Pagar","due_date":"2020-01-01T00:00:00Z","tags":["work","home"]}`)
+	req, _ = http.NewRequest("POST", "/api/tasks", bytes.NewBuffer(body))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	req, _ = http.NewRequest("GET", "/api/stats", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	stats = models.TaskStats{}
+	json.Unmarshal(rr.Body.Bytes(), &stats)
+	if stats.Total != 3 || stats.Overdue != 1 {
+		t.Errorf("Vencidas incorrectas: %+v", stats)
+	}
+	wantTags := []models.TagStats{{Tag: "home", Total: 1}, {Tag: "work", Total: 2, Completed: 1}}
+	if len(stats.ByTag) != len(wantTags) || stats.ByTag[0] != wantTags[0] || stats.ByTag[1] != wantTags[1] {
+		t.Errorf("Etiquetas incorrectas: %+v", stats.ByTag)
+	}
+
+	for _, query := range []string{"bucket=month", "from=2024-02-01&to=2024-01-01", "from=2000-01-01&to=2024-01-01"} {
+		req, _ := http.NewRequest("GET", "/api/stats?"+query, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: se esperaba 400, obtuvo %v", query, rr.Code)
+		}
+	}
+}
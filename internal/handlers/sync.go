@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/claudio/todo-api/internal/apiversion"
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/models"
+)
+
+const (
+	// defaultSyncLimit es la cantidad de cambios devueltos por página
+	defaultSyncLimit = 500
+	// maxSyncLimit es el máximo de cambios que se pueden pedir por página
+	maxSyncLimit = 1000
+	// maxSyncMutations es el máximo de mutaciones aceptadas en un lote
+	maxSyncMutations = 500
+)
+
+// errSyncConflict indica que la tarea cambió desde la versión que conocía el cliente
+var errSyncConflict = errors.New("la tarea fue modificada en el servidor")
+
+// syncChange es una tarea modificada o una tombstone, ordenables por secuencia
+type syncChange struct {
+	seq       int64
+	task      *models.Task
+	tombstone *models.Tombstone
+}
+
+// GetSync devuelve los cambios de las tareas visibles para el usuario desde
+// el token indicado en since. Sin token se devuelven todas las tareas como
+// creadas. El token de la respuesta se usa en la siguiente llamada; si
+// has_more es true quedan cambios pendientes.
+func (h *TaskHandler) GetSync(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	identity, _ := auth.FromContext(r.Context())
+
+	var since int64
+	if token := r.URL.Query().Get("since"); token != "" {
+		parsed, err := strconv.ParseInt(token, 10, 64)
+		if err != nil || parsed < 0 {
+			http.Error(w, "Token de sincronización inválido", http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	limit := defaultSyncLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxSyncLimit {
+			http.Error(w, "El límite debe estar entre 1 y "+strconv.Itoa(maxSyncLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	// Se pide un elemento más de cada lista para saber si quedan cambios
+	tasks, tombstones, err := h.store.Changes(r.Context(), since, limit+1)
+	if err != nil {
+		log.Printf("Error al consultar los cambios desde %d: %v", since, err)
+		http.Error(w, "Error al consultar los cambios", http.StatusInternalServerError)
+		return
+	}
+
+	changes := make([]syncChange, 0, len(tasks)+len(tombstones))
+	for i := range tasks {
+		changes = append(changes, syncChange{seq: tasks[i].Seq, task: &tasks[i]})
+	}
+	for i := range tombstones {
+		changes = append(changes, syncChange{seq: tombstones[i].Seq, tombstone: &tombstones[i]})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].seq < changes[j].seq
+	})
+
+	response := models.SyncChanges{
+		Created: []models.Task{},
+		Updated: []models.Task{},
+		Deleted: []models.Tombstone{},
+		HasMore: len(changes) > limit,
+	}
+	if response.HasMore {
+		changes = changes[:limit]
+	}
+
+	// El token avanza aunque el cambio no sea visible para el usuario
+	next := since
+	for _, change := range changes {
+		next = change.seq
+
+		switch {
+		case change.tombstone != nil:
+			// Un cliente sin token no tiene nada que borrar
+			if since > 0 && (change.tombstone.OwnerID == "" || change.tombstone.OwnerID == identity.UserID) {
+				response.Deleted = append(response.Deleted, *change.tombstone)
+			}
+		case !change.task.VisibleTo(identity.UserID):
+		case change.task.DeletedAt != nil:
+			// Las tareas en la papelera se envían como tombstones, salvo que
+			// el cliente nunca las haya recibido
+			if since > 0 && change.task.CreatedSeq <= since {
+				response.Deleted = append(response.Deleted, models.Tombstone{
+					TaskID:    change.task.ID,
+					Seq:       change.task.Seq,
+					DeletedAt: *change.task.DeletedAt,
+				})
+			}
+		case change.task.CreatedSeq > since:
+			response.Created = append(response.Created, *change.task)
+		default:
+			response.Updated = append(response.Updated, *change.task)
+		}
+	}
+	response.Token = strconv.FormatInt(next, 10)
+
+	json.NewEncoder(w).Encode(apiversion.EncodeSyncChanges(taskCodec(r), response))
+}
+
+// PostSync aplica un lote de mutaciones hechas por el cliente sin conexión.
+// Cada mutación se aplica por separado y tiene su propio resultado; las
+// actualizaciones y eliminaciones cuyo base_seq no coincide con la versión
+// actual se rechazan como conflicto.
+func (h *TaskHandler) PostSync(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// La tarea de cada mutación está en la representación de la versión
+	var body struct {
+		Mutations []struct {
+			models.SyncMutation
+			Task json.RawMessage `json:"task,omitempty"`
+		} `json:"mutations"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Error al decodificar JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(body.Mutations) > maxSyncMutations {
+		http.Error(w, "Se aceptan como máximo "+strconv.Itoa(maxSyncMutations)+" mutaciones por lote", http.StatusBadRequest)
+		return
+	}
+
+	codec := taskCodec(r)
+	source := sourceFromRequest(r)
+	results := make([]models.SyncResult, 0, len(body.Mutations))
+	for _, raw := range body.Mutations {
+		mutation := raw.SyncMutation
+		if len(raw.Task) > 0 && string(raw.Task) != "null" {
+			task, err := codec.DecodeTask(raw.Task)
+			if err != nil {
+				results = append(results, invalidSyncResult(models.SyncResult{ClientID: mutation.ClientID}, "Error al decodificar la tarea: "+err.Error()))
+				continue
+			}
+			mutation.Task = &task
+		}
+		results = append(results, h.applySyncMutation(r, source, mutation))
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"results": apiversion.EncodeSyncResults(taskCodec(r), results)})
+}
+
+// applySyncMutation aplica una mutación y traduce el error a un resultado
+func (h *TaskHandler) applySyncMutation(r *http.Request, source changeSource, mutation models.SyncMutation) models.SyncResult {
+	result := models.SyncResult{ClientID: mutation.ClientID}
+
+	// Solo se modifican tareas visibles y en la versión que conoce el cliente
+	check := func(current models.Task) error {
+		if !current.VisibleTo(source.userID) {
+			return errTaskNotFound
+		}
+		if current.Seq != mutation.BaseSeq {
+			return errSyncConflict
+		}
+		return nil
+	}
+
+	var task models.Task
+	var err error
+	switch mutation.Op {
+	case models.SyncCreate:
+		if mutation.Task == nil {
+			return invalidSyncResult(result, "La tarea es obligatoria")
+		}
+		task, err = h.createTask(r.Context(), source, *mutation.Task)
+	case models.SyncUpdate:
+		if mutation.Task == nil {
+			return invalidSyncResult(result, "La tarea es obligatoria")
+		}
+		if mutation.BaseSeq <= 0 {
+			return invalidSyncResult(result, "base_seq es obligatorio")
+		}
+		task, err = h.updateTask(r.Context(), source, mutation.TaskID, *mutation.Task, check)
+	case models.SyncDelete:
+		if mutation.BaseSeq <= 0 {
+			return invalidSyncResult(result, "base_seq es obligatorio")
+		}
+		err = h.deleteTask(r.Context(), source, mutation.TaskID, check)
+		if err == errTaskNotFound {
+			// Eliminar una tarea que ya está en la papelera no es un error
+			if current, getErr := h.store.Get(r.Context(), mutation.TaskID); getErr == nil &&
+				current.DeletedAt != nil && current.VisibleTo(source.userID) {
+				err = nil
+			}
+		}
+	default:
+		return invalidSyncResult(result, "Operación desconocida: "+mutation.Op)
+	}
+
+	if invalid, ok := err.(*invalidTaskError); ok {
+		return invalidSyncResult(result, invalid.Error())
+	}
+	switch err {
+	case nil:
+		result.Status = models.SyncApplied
+		if mutation.Op != models.SyncDelete {
+			result.Task = &task
+		}
+	case errTitleRequired:
+		return invalidSyncResult(result, "El título es obligatorio")
+	case errTaskNotFound:
+		result.Status = models.SyncNotFound
+	case errSyncConflict:
+		result.Status = models.SyncConflict
+		if current, getErr := h.store.Get(r.Context(), mutation.TaskID); getErr == nil {
+			result.Task = &current
+		}
+	default:
+		log.Printf("Error al sincronizar la tarea %d: %v", mutation.TaskID, err)
+		result.Status = models.SyncInvalid
+		result.Error = "Error al aplicar la mutación"
+	}
+	return result
+}
+
+// invalidSyncResult marca el resultado como inválido con el mensaje indicado
+func invalidSyncResult(result models.SyncResult, message string) models.SyncResult {
+	result.Status = models.SyncInvalid
+	result.Error = message
+	return result
+}
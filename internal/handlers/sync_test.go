@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/gorilla/mux"
+)
+
+func TestSyncDeltaAndConflicts(t *testing.T) {
+	taskHandler := NewTaskHandler()
+	router := mux.NewRouter()
+	router.HandleFunc("/api/sync", taskHandler.GetSync).Methods("GET")
+	router.HandleFunc("/api/sync", taskHandler.PostSync).Methods("POST")
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Sin token todas las tareas llegan como creadas
+	var initial models.SyncChanges
+	json.Unmarshal(do("GET", "/api/sync", "").Body.Bytes(), &initial)
+	if len(initial.Created) != 2 || len(initial.Updated) != 0 || initial.Token != "2" {
+		t.Fatalf("Sincronización inicial incorrecta: %+v", initial)
+	}
+
+	rr := do("POST", "/api/sync", `{"mutations": [
+		{"client_id": "a", "op": "update", "task_id": 1, "base_seq": 1, "task": {"title": "Editada sin conexión"}},
+		{"client_id": "b", "op": "update", "task_id": 1, "base_seq": 1, "task": {"title": "Versión vieja"}},
+		{"client_id": "c", "op": "delete", "task_id": 2, "base_seq": 2},
+		{"client_id": "d", "op": "create", "task": {"title": "Nueva"}},
+		{"client_id": "e", "op": "update", "task_id": 99, "base_seq": 1, "task": {"title": "x"}}
+	]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusOK)
+	}
+
+	var pushed struct {
+		Results []models.SyncResult `json:"results"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &pushed)
+	expected := []string{models.SyncApplied, models.SyncConflict, models.SyncApplied, models.SyncApplied, models.SyncNotFound}
+	for i, status := range expected {
+		if pushed.Results[i].Status != status {
+			t.Errorf("Resultado %s: obtuvo %v, esperaba %v", pushed.Results[i].ClientID, pushed.Results[i].Status, status)
+		}
+	}
+	if conflict := pushed.Results[1].Task; conflict == nil || conflict.Title != "Editada sin conexión" {
+		t.Errorf("El conflicto debe incluir la versión del servidor: %+v", conflict)
+	}
+
+	// Desde el token inicial se ven la actualización, la creación y la eliminación
+	var delta models.SyncChanges
+	json.Unmarshal(do("GET", "/api/sync?since="+initial.Token, "").Body.Bytes(), &delta)
+	if len(delta.Updated) != 1 || delta.Updated[0].ID != 1 {
+		t.Errorf("Actualizadas incorrectas: %+v", delta.Updated)
+	}
+	if len(delta.Created) != 1 || delta.Created[0].Title != "Nueva" {
+		t.Errorf("Creadas incorrectas: %+v", delta.Created)
+	}
+	if len(delta.Deleted) != 1 || delta.Deleted[0].TaskID != 2 {
+		t.Errorf("Eliminadas incorrectas: %+v", delta.Deleted)
+	}
+
+	// Con el último token no hay cambios pendientes
+	var empty models.SyncChanges
+	json.Unmarshal(do("GET", "/api/sync?since="+delta.Token, "").Body.Bytes(), &empty)
+	if len(empty.Created)+len(empty.Updated)+len(empty.Deleted) != 0 || empty.Token != delta.Token {
+		t.Errorf("Se esperaba una respuesta vacía: %+v", empty)
+	}
+}
@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/filter"
+	"github.com/claudio/todo-api/internal/ical"
+	"github.com/claudio/todo-api/internal/middleware"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/store"
+)
+
+var (
+	// errTaskNotFound indica que la tarea no existe o está en la papelera
+	errTaskNotFound = store.ErrNotFound
+	// errTitleRequired indica que la tarea no tiene título
+	errTitleRequired = errors.New("el título es obligatorio")
+)
+
+// invalidTaskError indica que un campo de la tarea, distinto del título, no
+// es válido
+type invalidTaskError struct {
+	message string
+}
+
+func (e *invalidTaskError) Error() string {
+	return e.message
+}
+
+// Límites de las etiquetas de una tarea
+const (
+	maxTags      = 20
+	maxTagLength = 50
+)
+
+// validateTask comprueba los campos de una tarea antes de guardarla
+func validateTask(task models.Task) error {
+	if task.Title == "" {
+		return errTitleRequired
+	}
+	if task.Priority < 0 || task.Priority > 9 {
+		return &invalidTaskError{"la prioridad debe estar entre 0 y 9"}
+	}
+	if task.Recurrence != "" {
+		if task.DueDate == nil {
+			return &invalidTaskError{"la recurrencia necesita una fecha de vencimiento"}
+		}
+		if err := ical.ValidateRecurrence(task.Recurrence); err != nil {
+			return &invalidTaskError{err.Error()}
+		}
+	}
+	tags := models.NormalizeTags(task.Tags)
+	if len(tags) > maxTags {
+		return &invalidTaskError{fmt.Sprintf("una tarea puede tener como máximo %d etiquetas", maxTags)}
+	}
+	for _, tag := range tags {
+		if len(tag) > maxTagLength || strings.ContainsAny(tag, " \t\n,") {
+			return &invalidTaskError{fmt.Sprintf("etiqueta inválida %q: hasta %d caracteres, sin espacios ni comas", tag, maxTagLength)}
+		}
+	}
+	return nil
+}
+
+// taskCheck valida el estado actual de una tarea dentro de la transacción
+// antes de modificarla; nil no aplica ninguna validación
+type taskCheck func(current models.Task) error
+
+// visibleCheck solo permite modificar las tareas visibles para el usuario
+func visibleCheck(source changeSource) taskCheck {
+	return func(current models.Task) error {
+		if !current.VisibleTo(source.userID) {
+			return errTaskNotFound
+		}
+		return nil
+	}
+}
+
+// changeSource identifica quién originó una modificación
+type changeSource struct {
+	userID    string
+	actor     string
+	requestID string
+	ip        string
+}
+
+// systemSource se usa para las modificaciones de procesos en segundo plano
+var systemSource = changeSource{actor: "system"}
+
+// sourceFromRequest obtiene el origen de una modificación hecha por HTTP
+func sourceFromRequest(r *http.Request) changeSource {
+	source := changeSource{
+		actor:     "anonymous",
+		requestID: middleware.RequestIDFromContext(r.Context()),
+		ip:        middleware.ClientIP(r),
+	}
+	if identity, ok := auth.FromContext(r.Context()); ok {
+		source.userID = identity.UserID
+		source.actor = identity.UserID
+	}
+	return source
+}
+
+// mutate ejecuta fn en una transacción y guarda en ella, junto con la propia
+// modificación, el evento del outbox, el registro de auditoría y la revisión.
+// La publicación del evento queda a cargo del relay.
+func (h *TaskHandler) mutate(ctx context.Context, source changeSource, action string, fn func(tx store.Tx) (before, after *models.Task, err error)) (*models.Task, error) {
+	var before, after *models.Task
+	err := h.store.Tx(ctx, func(tx store.Tx) error {
+		var err error
+		before, after, err = fn(tx)
+		if err != nil {
+			return err
+		}
+		if err := tx.AddOutbox(changeEvent(source, action, before, after)); err != nil {
+			return err
+		}
+		return h.writeChange(tx, source, action, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// createTask valida y guarda una nueva tarea. Las operaciones de este archivo
+// son compartidas por la API REST y los demás transportes.
+func (h *TaskHandler) createTask(ctx context.Context, source changeSource, task models.Task) (models.Task, error) {
+	created, err := h.mutate(ctx, source, models.ActionCreate, func(tx store.Tx) (*models.Task, *models.Task, error) {
+		return txCreate(tx, source, task)
+	})
+	if err != nil {
+		return task, err
+	}
+	return *created, nil
+}
+
+// updateTask sobrescribe una tarea existente; las tareas en la papelera no se modifican
+func (h *TaskHandler) updateTask(ctx context.Context, source changeSource, id int, updated models.Task, check taskCheck) (models.Task, error) {
+	after, err := h.mutate(ctx, source, models.ActionUpdate, func(tx store.Tx) (*models.Task, *models.Task, error) {
+		return txUpdate(tx, id, updated, check)
+	})
+	if err != nil {
+		return updated, err
+	}
+	return *after, nil
+}
+
+// deleteTask mueve una tarea a la papelera
+func (h *TaskHandler) deleteTask(ctx context.Context, source changeSource, id int, check taskCheck) error {
+	_, err := h.mutate(ctx, source, models.ActionDelete, func(tx store.Tx) (*models.Task, *models.Task, error) {
+		return txDelete(tx, id, check)
+	})
+	return err
+}
+
+// addComment agrega un comentario de source a una tarea que no está en la
+// papelera y es visible para el usuario
+func (h *TaskHandler) addComment(ctx context.Context, source changeSource, taskID int, body string) (models.Comment, error) {
+	task, err := h.store.Get(ctx, taskID)
+	if err == store.ErrNotFound || (err == nil && (task.DeletedAt != nil || !task.VisibleTo(source.userID))) {
+		return models.Comment{}, errTaskNotFound
+	}
+	if err != nil {
+		return models.Comment{}, err
+	}
+	return h.comments.Add(ctx, models.Comment{TaskID: taskID, Author: source.actor, Body: body})
+}
+
+// listVisible devuelve, ordenadas por ID, las tareas que no están en la
+// papelera, son visibles para userID y cumplen la expresión de filtro, si se
+// indica. Una expresión inválida devuelve un *filter.Error.
+func (h *TaskHandler) listVisible(ctx context.Context, userID, expression string) ([]models.Task, error) {
+	return h.listTasks(ctx, userID, expression, false)
+}
+
+// listTasks es como listVisible, pero con includeDeleted también devuelve
+// las tareas visibles que están en la papelera
+func (h *TaskHandler) listTasks(ctx context.Context, userID, expression string, includeDeleted bool) ([]models.Task, error) {
+	var all []models.Task
+	var err error
+	if expression != "" {
+		expr, parseErr := filter.Parse(expression, time.Now())
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		all, err = h.store.Find(ctx, expr)
+	} else {
+		all, err = h.store.List(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []models.Task{}
+	for _, task := range all {
+		if (task.DeletedAt == nil || includeDeleted) && task.VisibleTo(userID) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+// checkVisible devuelve errTaskNotFound si la tarea no es visible para
+// userID. Las tareas purgadas se juzgan por el propietario de su última
+// revisión, para que su historial siga siendo privado.
+func (h *TaskHandler) checkVisible(ctx context.Context, id int, userID string) error {
+	task, err := h.store.Get(ctx, id)
+	if err == store.ErrNotFound {
+		revisions, histErr := h.history.List(ctx, id)
+		if histErr != nil {
+			return histErr
+		}
+		if len(revisions) == 0 {
+			return errTaskNotFound
+		}
+		task, err = revisions[len(revisions)-1].Task, nil
+	}
+	if err != nil {
+		return err
+	}
+	if !task.VisibleTo(userID) {
+		return errTaskNotFound
+	}
+	return nil
+}
+
+// txCreate asigna fechas y propietario a la tarea y la inserta; el ID lo
+// asigna el almacén
+func txCreate(tx store.Tx, source changeSource, task models.Task) (*models.Task, *models.Task, error) {
+	if err := validateTask(task); err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	task.Tags = models.NormalizeTags(task.Tags)
+	task.CreatedAt = now
+	task.UpdatedAt = now
+	task.DeletedAt = nil
+	task.OwnerID = source.userID
+	stampCompletion(&task, nil, now)
+	if err := tx.Insert(&task); err != nil {
+		return nil, nil, err
+	}
+	return nil, &task, nil
+}
+
+// txUpdate reemplaza una tarea que no está en la papelera
+func txUpdate(tx store.Tx, id int, updated models.Task, check taskCheck) (*models.Task, *models.Task, error) {
+	if err := validateTask(updated); err != nil {
+		return nil, nil, err
+	}
+	before, err := txGetActive(tx, id, check)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Mantener el ID original, la fecha de creación y el propietario
+	updated.ID = id
+	updated.Tags = models.NormalizeTags(updated.Tags)
+	updated.CreatedAt = before.CreatedAt
+	updated.OwnerID = before.OwnerID
+	updated.UpdatedAt = time.Now()
+	updated.DeletedAt = nil
+	stampCompletion(&updated, &before, updated.UpdatedAt)
+	if err := tx.Save(&updated); err != nil {
+		return nil, nil, err
+	}
+	return &before, &updated, nil
+}
+
+// txDelete mueve una tarea a la papelera
+func txDelete(tx store.Tx, id int, check taskCheck) (*models.Task, *models.Task, error) {
+	before, err := txGetActive(tx, id, check)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	task := before
+	now := time.Now()
+	task.DeletedAt = &now
+	task.UpdatedAt = now
+	if err := tx.Save(&task); err != nil {
+		return nil, nil, err
+	}
+	return &before, &task, nil
+}
+
+// stampCompletion asigna CompletedAt cuando la tarea pasa a completada y lo
+// borra cuando vuelve a estar pendiente; before es nil en las creaciones
+func stampCompletion(task *models.Task, before *models.Task, now time.Time) {
+	switch {
+	case !task.Completed:
+		task.CompletedAt = nil
+	case before != nil && before.Completed:
+		task.CompletedAt = before.CompletedAt
+	default:
+		task.CompletedAt = &now
+	}
+}
+
+// txGetActive obtiene una tarea que no está en la papelera y aplica check
+func txGetActive(tx store.Tx, id int, check taskCheck) (models.Task, error) {
+	task, err := tx.Get(id)
+	if err != nil {
+		return task, err
+	}
+	if task.DeletedAt != nil {
+		return task, errTaskNotFound
+	}
+	if check != nil {
+		if err := check(task); err != nil {
+			return task, err
+		}
+	}
+	return task, nil
+}
@@ -1,62 +1,115 @@
 package handlers
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
-	"io"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/claudio/todo-api/internal/apiversion"
+	"github.com/claudio/todo-api/internal/audit"
+	"github.com/claudio/todo-api/internal/comments"
+	"github.com/claudio/todo-api/internal/filter"
+	"github.com/claudio/todo-api/internal/history"
 	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/render"
+	"github.com/claudio/todo-api/internal/store"
 )
 
 // TaskHandler maneja las solicitudes relacionadas con tareas
 type TaskHandler struct {
-	tasks  []models.Task
-	nextID int
+	store    store.TaskStore
+	audit    audit.Store
+	history  history.Store
+	comments comments.Store
+}
+
+// TaskHandlerOption configura dependencias opcionales de TaskHandler
+type TaskHandlerOption func(*TaskHandler)
+
+// WithTaskStore indica dónde se guardan las tareas. Sin esta opción se usa
+// un almacén en memoria con tareas de ejemplo.
+func WithTaskStore(s store.TaskStore) TaskHandlerOption {
+	return func(h *TaskHandler) {
+		h.store = s
+	}
+}
+
+// WithAuditStore indica dónde se registran las modificaciones de tareas
+func WithAuditStore(store audit.Store) TaskHandlerOption {
+	return func(h *TaskHandler) {
+		h.audit = store
+	}
+}
+
+// WithHistoryStore indica dónde se guardan las revisiones de las tareas
+func WithHistoryStore(store history.Store) TaskHandlerOption {
+	return func(h *TaskHandler) {
+		h.history = store
+	}
+}
+
+// WithCommentStore indica dónde se guardan los comentarios de las tareas
+func WithCommentStore(store comments.Store) TaskHandlerOption {
+	return func(h *TaskHandler) {
+		h.comments = store
+	}
 }
 
 // NewTaskHandler crea una nueva instancia de TaskHandler
-func NewTaskHandler() *TaskHandler {
+func NewTaskHandler(opts ...TaskHandlerOption) *TaskHandler {
 	handler := &TaskHandler{
-		tasks:  []models.Task{},
-		nextID: 1,
+		audit:    audit.NewMemoryStore(),
+		history:  history.NewMemoryStore(),
+		comments: comments.NewMemoryStore(),
+	}
+	for _, opt := range opts {
+		opt(handler)
+	}
+	if handler.store == nil {
+		handler.store = NewExampleTaskStore()
 	}
 	
+	return handler
+}
+
+// NewExampleTaskStore crea un almacén en memoria con algunas tareas de ejemplo
+func NewExampleTaskStore() *store.MemoryTaskStore {
+	memory := store.NewMemoryTaskStore()
+	
 	// Obtener la fecha y hora actual
 	now := time.Now()
 	
 	// Agregar algunas tareas de ejemplo
-	handler.tasks = append(handler.tasks, models.Task{
-		ID:          handler.nextID,
-		Title:       "Ejemplo de tarea 1",
-		Description: "Esta es una tarea de ejemplo predefinida",
-		Completed:   false,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	})
-	handler.nextID++
-	
-	handler.tasks = append(handler.tasks, models.Task{
-		ID:          handler.nextID,
-		Title:       "Ejemplo de tarea 2",
-		Description: "Esta es otra tarea de ejemplo predefinida",
-		Completed:   true,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+	memory.Tx(context.Background(), func(tx store.Tx) error {
+		tx.Insert(&models.Task{
+			Title:       "Ejemplo de tarea 1",
+			Description: "Esta es una tarea de ejemplo predefinida",
+			Completed:   false,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		tx.Insert(&models.Task{
+			Title:       "Ejemplo de tarea 2",
+			Description: "Esta es otra tarea de ejemplo predefinida",
+			Completed:   true,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			CompletedAt: &now,
+		})
+		return nil
 	})
-	handler.nextID++
 	
-	return handler
+	return memory
 }
 
 // HealthCheck proporciona un endpoint simple para verificar que la API está funcionando
 func (h *TaskHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 	
 	response := map[string]string{
 		"status": "ok",
@@ -69,18 +122,32 @@ func (h *TaskHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 // GetTasks devuelve todas las tareas
 func (h *TaskHandler) GetTasks(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
-	json.NewEncoder(w).Encode(h.tasks)
+	
+	format, ok := negotiate(w, r)
+	if !ok {
+		return
+	}
+	
+	// Filtrar con el lenguaje de expresiones si se indica ?filter=. Solo se
+	// devuelven las tareas visibles para el usuario, y las tareas en la
+	// papelera se excluyen salvo que se pidan explícitamente
+	includeDeleted := r.URL.Query().Get("include_deleted") == "true"
+	tasks, err := h.listTasks(r.Context(), sourceFromRequest(r).userID, r.URL.Query().Get("filter"), includeDeleted)
+	if filterErr, ok := err.(*filter.Error); ok {
+		http.Error(w, filterErr.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error al listar las tareas: %v", err)
+		http.Error(w, "Error al listar las tareas", http.StatusInternalServerError)
+		return
+	}
+	respond(w, format, http.StatusOK, apiversion.EncodeTasks(taskCodec(r), tasks))
 }
 
 // GetTask devuelve una tarea específica por ID
 func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
 	
 	// Obtener el ID de la URL
 	params := mux.Vars(r)
@@ -90,77 +157,74 @@ func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// Buscar la tarea
-	for _, task := range h.tasks {
-		if task.ID == id {
-			json.NewEncoder(w).Encode(task)
-			return
-		}
+	format, ok := negotiate(w, r)
+	if !ok {
+		return
+	}
+	
+	// Devolver el estado histórico si se solicita un instante concreto
+	if asOf := r.URL.Query().Get("as_of"); asOf != "" {
+		h.getTaskAsOf(w, r, format, id, asOf)
+		return
 	}
 	
-	// Si no se encuentra la tarea
-	http.Error(w, "Tarea no encontrada", http.StatusNotFound)
+	// Buscar la tarea, ignorando las que están en la papelera y las de otros usuarios
+	task, err := h.store.Get(r.Context(), id)
+	if err == store.ErrNotFound || (err == nil && (task.DeletedAt != nil || !task.VisibleTo(sourceFromRequest(r).userID))) {
+		// Si no se encuentra la tarea
+		http.Error(w, "Tarea no encontrada", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error al obtener la tarea %d: %v", id, err)
+		http.Error(w, "Error al obtener la tarea", http.StatusInternalServerError)
+		return
+	}
+	
+	respond(w, format, http.StatusOK, taskCodec(r).EncodeTask(task))
 }
 
 // CreateTask crea una nueva tarea
 func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
 	
-	// Leer y registrar el cuerpo de la solicitud para depuración
-	bodyBytes, err := io.ReadAll(r.Body)
-	if err != nil {
-		log.Printf("Error al leer el cuerpo de la solicitud: %v", err)
-		http.Error(w, "Error al leer la solicitud", http.StatusBadRequest)
+	format, ok := negotiate(w, r)
+	if !ok {
 		return
 	}
 	
-	// Restaurar el cuerpo para que pueda ser leído por json.NewDecoder
-	r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-	
-	// Registrar el cuerpo para depuración
-	log.Printf("Cuerpo de la solicitud CreateTask: %s", string(bodyBytes))
-	
-	var task models.Task
-	err = json.NewDecoder(r.Body).Decode(&task)
-	if err != nil {
-		log.Printf("Error al decodificar JSON: %v", err)
-		http.Error(w, "Error al decodificar JSON: "+err.Error(), http.StatusBadRequest)
+	// Leer el cuerpo en el formato de Content-Type
+	task, ok := readTask(w, r)
+	if !ok {
 		return
 	}
 	
-	// Validar los campos requeridos
-	if task.Title == "" {
+	// Validar los campos requeridos y guardar la tarea
+	task, err := h.createTask(r.Context(), sourceFromRequest(r), task)
+	if err == errTitleRequired {
 		log.Printf("Error: Título vacío")
 		http.Error(w, "El título es obligatorio", http.StatusBadRequest)
 		return
 	}
-	
-	// Asignar un ID a la tarea y fechas
-	task.ID = h.nextID
-	now := time.Now()
-	task.CreatedAt = now
-	task.UpdatedAt = now
-	h.nextID++
-	
-	// Agregar la tarea a la lista
-	h.tasks = append(h.tasks, task)
+	if invalid, ok := err.(*invalidTaskError); ok {
+		http.Error(w, invalid.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error al crear la tarea: %v", err)
+		http.Error(w, "Error al crear la tarea", http.StatusInternalServerError)
+		return
+	}
 	
 	// Registrar la tarea creada
 	log.Printf("Tarea creada: %+v", task)
 	
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(task)
+	respond(w, format, http.StatusCreated, taskCodec(r).EncodeTask(task))
 }
 
 // UpdateTask actualiza una tarea existente
 func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
 	
 	// Obtener el ID de la URL
 	params := mux.Vars(r)
@@ -170,37 +234,44 @@ func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	format, ok := negotiate(w, r)
+	if !ok {
+		return
+	}
+	
 	// Decodificar la tarea actualizada
-	var updatedTask models.Task
-	err = json.NewDecoder(r.Body).Decode(&updatedTask)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	updatedTask, ok := readTask(w, r)
+	if !ok {
 		return
 	}
 	
-	// Buscar y actualizar la tarea
-	for i, task := range h.tasks {
-		if task.ID == id {
-			// Mantener el ID original y la fecha de creación
-			updatedTask.ID = id
-			updatedTask.CreatedAt = task.CreatedAt
-			updatedTask.UpdatedAt = time.Now()
-			h.tasks[i] = updatedTask
-			json.NewEncoder(w).Encode(updatedTask)
-			return
-		}
+	// Buscar y actualizar la tarea si es visible para el usuario
+	source := sourceFromRequest(r)
+	updatedTask, err = h.updateTask(r.Context(), source, id, updatedTask, visibleCheck(source))
+	if err == errTaskNotFound {
+		http.Error(w, "Tarea no encontrada", http.StatusNotFound)
+		return
+	}
+	if err == errTitleRequired {
+		http.Error(w, "El título es obligatorio", http.StatusBadRequest)
+		return
+	}
+	if invalid, ok := err.(*invalidTaskError); ok {
+		http.Error(w, invalid.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error al actualizar la tarea %d: %v", id, err)
+		http.Error(w, "Error al actualizar la tarea", http.StatusInternalServerError)
+		return
 	}
 	
-	// Si no se encuentra la tarea
-	http.Error(w, "Tarea no encontrada", http.StatusNotFound)
+	respond(w, format, http.StatusOK, taskCodec(r).EncodeTask(updatedTask))
 }
 
-// DeleteTask elimina una tarea
+// DeleteTask mueve una tarea a la papelera
 func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
 	
 	// Obtener el ID de la URL
 	params := mux.Vars(r)
@@ -210,25 +281,150 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// Buscar y eliminar la tarea
-	for i, task := range h.tasks {
-		if task.ID == id {
-			// Eliminar la tarea del slice
-			h.tasks = append(h.tasks[:i], h.tasks[i+1:]...)
-			w.WriteHeader(http.StatusNoContent)
-			return
-		}
+	// Buscar la tarea y moverla a la papelera si es visible para el usuario
+	source := sourceFromRequest(r)
+	err = h.deleteTask(r.Context(), source, id, visibleCheck(source))
+	if err == errTaskNotFound {
+		http.Error(w, "Tarea no encontrada", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error al eliminar la tarea %d: %v", id, err)
+		http.Error(w, "Error al eliminar la tarea", http.StatusInternalServerError)
+		return
 	}
 	
-	// Si no se encuentra la tarea
-	http.Error(w, "Tarea no encontrada", http.StatusNotFound)
+	w.WriteHeader(http.StatusNoContent)
 }
 
 // HandlePreflight maneja las solicitudes OPTIONS para CORS
 func (h *TaskHandler) HandlePreflight(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
 	w.Header().Set("Access-Control-Max-Age", "3600")
 	w.WriteHeader(http.StatusOK)
-}
\ No newline at end of file
+}
+
+// writeChange guarda el registro de auditoría y la revisión de una
+// modificación dentro de su transacción. Las tareas se copian para que los
+// registros no compartan memoria con el llamador.
+func (h *TaskHandler) writeChange(tx store.Tx, source changeSource, action string, before, after *models.Task) error {
+	before, after = copyTask(before), copyTask(after)
+	snapshot := changeSnapshot(before, after)
+	changes := models.DiffTasks(before, after)
+
+	record := &models.AuditRecord{
+		Actor:     source.actor,
+		Action:    action,
+		TaskID:    snapshot.ID,
+		Before:    before,
+		After:     after,
+		Changes:   changes,
+		RequestID: source.requestID,
+		IP:        source.ip,
+	}
+	if err := h.audit.Append(tx, record); err != nil {
+		return fmt.Errorf("registrar la auditoría de la tarea %d: %w", snapshot.ID, err)
+	}
+
+	revision := &models.TaskRevision{
+		TaskID:  snapshot.ID,
+		Action:  action,
+		Task:    *snapshot,
+		Changes: changes,
+		Actor:   source.actor,
+	}
+	if err := h.history.Append(tx, revision); err != nil {
+		return fmt.Errorf("guardar la revisión de la tarea %d: %w", snapshot.ID, err)
+	}
+	return nil
+}
+
+// changeEvent construye el evento que se guarda en el outbox para una modificación
+func changeEvent(source changeSource, action string, before, after *models.Task) models.TaskEvent {
+	snapshot := copyTask(changeSnapshot(before, after))
+	return models.TaskEvent{
+		Type:       eventType(action),
+		TaskID:     snapshot.ID,
+		Task:       snapshot,
+		Actor:      source.actor,
+		OccurredAt: time.Now(),
+	}
+}
+
+// changeSnapshot devuelve el estado de la tarea después del cambio; en una
+// eliminación definitiva es el último estado conocido
+func changeSnapshot(before, after *models.Task) *models.Task {
+	if after != nil {
+		return after
+	}
+	return before
+}
+
+// copyTask devuelve una copia de la tarea, o nil si la tarea es nil
+func copyTask(task *models.Task) *models.Task {
+	if task == nil {
+		return nil
+	}
+	copied := *task
+	return &copied
+}
+
+// eventType traduce una acción de auditoría al tipo de evento publicado
+func eventType(action string) string {
+	switch action {
+	case models.ActionCreate:
+		return models.EventTaskCreated
+	case models.ActionDelete, models.ActionPurge:
+		return models.EventTaskDeleted
+	default:
+		return models.EventTaskUpdated
+	}
+}
+
+// negotiate elige el formato de la respuesta según Accept; si el cliente no
+// acepta ninguno responde 406 y devuelve false
+func negotiate(w http.ResponseWriter, r *http.Request) (string, bool) {
+	format, err := render.Negotiate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotAcceptable)
+		return "", false
+	}
+	return format, true
+}
+
+// respond escribe la respuesta en el formato negociado
+func respond(w http.ResponseWriter, format string, status int, value interface{}) {
+	err := render.Write(w, format, status, value)
+	if err == render.ErrNotTabular {
+		http.Error(w, "La respuesta no se puede representar como CSV", http.StatusNotAcceptable)
+		return
+	}
+	if err != nil {
+		log.Printf("Error al escribir la respuesta en %s: %v", format, err)
+	}
+}
+
+// readTask lee una tarea en el formato de Content-Type con la representación
+// de la versión de la solicitud. Responde 415 si el formato no se admite o
+// 400 si el cuerpo no es válido y devuelve false.
+func readTask(w http.ResponseWriter, r *http.Request) (models.Task, bool) {
+	body, err := render.ReadJSON(r, models.Task{})
+	if err == render.ErrUnsupportedMediaType {
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return models.Task{}, false
+	}
+	if err == nil {
+		var task models.Task
+		if task, err = taskCodec(r).DecodeTask(body); err == nil {
+			return task, true
+		}
+	}
+	log.Printf("Error al decodificar la tarea: %v", err)
+	http.Error(w, "Error al decodificar la tarea: "+err.Error(), http.StatusBadRequest)
+	return models.Task{}, false
+}
+
+// taskCodec devuelve la representación de las tareas para la versión de la
+// API de la solicitud
+func taskCodec(r *http.Request) apiversion.TaskCodec {
+	return apiversion.Codec(apiversion.FromContext(r.Context()))
+}
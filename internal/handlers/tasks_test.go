@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	
 	"github.com/gorilla/mux"
@@ -104,4 +105,92 @@ func TestGetTasks(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-}
\ No newline at end of file
+}
+
+func TestGetTasksWithFilter(t *testing.T) {
+	router := mux.NewRouter()
+	taskHandler := NewTaskHandler()
+	router.HandleFunc("/api/tasks", taskHandler.GetTasks).Methods("GET")
+
+	// Solo la segunda tarea de ejemplo está completada
+	req, _ := http.NewRequest("GET", "/api/tasks?filter="+url.QueryEscape(`completed AND title:"tarea 2"`), nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var tasks []models.Task
+	json.Unmarshal(rr.Body.Bytes(), &tasks)
+	if rr.Code != http.StatusOK || len(tasks) != 1 || tasks[0].ID != 2 {
+		t.Errorf("Filtro incorrecto: %v %+v", rr.Code, tasks)
+	}
+
+	// Las etiquetas se guardan normalizadas y se pueden filtrar
+	router.HandleFunc("/api/tasks", taskHandler.CreateTask).Methods("POST")
+	for _, body := range []string{
+		`{"title": "Informe", "tags": [" Work ", "work"]}`,
+		`{"title": "Llamar", "tags": ["urgent"], "completed": true}`,
+		`{"title": "Regar", "tags": ["home"]}`,
+	} {
+		req, _ = http.NewRequest("POST", "/api/tasks", bytes.NewBufferString(body))
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusCreated)
+		}
+	}
+	req, _ = http.NewRequest("GET", "/api/tasks?filter="+url.QueryEscape("(tag:work OR tag:urgent) AND NOT completed"), nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	json.Unmarshal(rr.Body.Bytes(), &tasks)
+	if rr.Code != http.StatusOK || len(tasks) != 1 || tasks[0].Title != "Informe" || len(tasks[0].Tags) != 1 || tasks[0].Tags[0] != "work" {
+		t.Errorf("Filtro por etiqueta incorrecto: %v %+v", rr.Code, tasks)
+	}
+
+	// Un campo desconocido es un error de validación
+	req, _ = http.NewRequest("GET", "/api/tasks?filter="+url.QueryEscape("label:work"), nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestTaskContentNegotiation(t *testing.T) {
+	router := mux.NewRouter()
+	taskHandler := NewTaskHandler()
+	router.HandleFunc("/api/tasks", taskHandler.GetTasks).Methods("GET")
+	router.HandleFunc("/api/tasks", taskHandler.CreateTask).Methods("POST")
+
+	do := func(method, body, contentType, accept string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, "/api/tasks", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Accept", accept)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Crear una tarea en YAML y recibirla en CSV
+	rr := do("POST", "title: Desde YAML\ncompleted: true\n", "application/yaml", "text/csv")
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusCreated)
+	}
+	if !bytes.HasPrefix(rr.Body.Bytes(), []byte("id,title,description,completed")) || !bytes.Contains(rr.Body.Bytes(), []byte("3,Desde YAML,,true")) {
+		t.Errorf("CSV incorrecto: %s", rr.Body.String())
+	}
+
+	// Los formatos no admitidos responden 406 y 415 sin crear la tarea
+	if rr := do("GET", "", "", "application/xml"); rr.Code != http.StatusNotAcceptable {
+		t.Errorf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusNotAcceptable)
+	}
+	if rr := do("POST", "<task/>", "application/xml", ""); rr.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusUnsupportedMediaType)
+	}
+	if rr := do("POST", `{"title": "x"}`, "application/json", "application/xml"); rr.Code != http.StatusNotAcceptable {
+		t.Errorf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusNotAcceptable)
+	}
+
+	rr = do("GET", "", "", "application/yaml")
+	if rr.Header().Get("Content-Type") != "application/yaml; charset=utf-8" || !bytes.Contains(rr.Body.Bytes(), []byte("title: Desde YAML")) {
+		t.Errorf("YAML incorrecto: %s", rr.Body.String())
+	}
+}
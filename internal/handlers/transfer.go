@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/filter"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/transfer"
+)
+
+const (
+	// maxImportBody es el tamaño máximo de un archivo de importación
+	maxImportBody = 10 << 20
+	// maxImportRows es el máximo de filas de un archivo de importación
+	maxImportRows = 5000
+	// exportFlushEvery es cada cuántas tareas se envía la exportación al cliente
+	exportFlushEvery = 100
+)
+
+// ExportTasks descarga las tareas visibles del usuario en JSON Lines, CSV o
+// todo.txt según ?format= o el encabezado Accept. Las tareas se envían a
+// medida que se escriben; ?filter= limita la exportación como en GetTasks.
+func (h *TaskHandler) ExportTasks(w http.ResponseWriter, r *http.Request) {
+	format, err := transfer.ParseFormat(r.URL.Query().Get("format"), transfer.FormatFromMediaType(r.Header.Get("Accept")))
+	if err != nil {
+		http.Error(w, "Formato inválido: use jsonl, csv o todotxt", http.StatusBadRequest)
+		return
+	}
+	if format == "" {
+		format = transfer.JSONLines
+	}
+
+	identity, _ := auth.FromContext(r.Context())
+	tasks, err := h.listVisible(r.Context(), identity.UserID, r.URL.Query().Get("filter"))
+	if _, invalid := err.(*filter.Error); invalid {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error al listar las tareas para exportar: %v", err)
+		http.Error(w, "Error al exportar las tareas", http.StatusInternalServerError)
+		return
+	}
+
+	filename := "tasks-" + time.Now().UTC().Format("2006-01-02") + transfer.Extension(format)
+	w.Header().Set("Content-Type", transfer.ContentType(format))
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	writer, _ := transfer.NewWriter(w, format, taskCodec(r).EncodeTask)
+	flusher, _ := w.(http.Flusher)
+	for i, task := range tasks {
+		if err := writer.Write(task); err != nil {
+			// El cliente cerró la conexión; la respuesta ya empezó
+			log.Printf("Error al escribir la exportación: %v", err)
+			return
+		}
+		if (i+1)%exportFlushEvery == 0 && flusher != nil {
+			writer.Flush()
+			flusher.Flush()
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		log.Printf("Error al escribir la exportación: %v", err)
+	}
+}
+
+// ImportTasks crea tareas a partir de un archivo JSON Lines, CSV o todo.txt.
+// El formato se toma de ?format= o de Content-Type. ?map=columna:campo
+// renombra columnas, ?dry_run=true solo valida y ?duplicates=allow crea
+// también las filas con el mismo título y proyecto que una tarea existente.
+// La respuesta informa el resultado de cada fila.
+func (h *TaskHandler) ImportTasks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	format, err := transfer.ParseFormat(query.Get("format"), transfer.FormatFromMediaType(r.Header.Get("Content-Type")))
+	if err != nil {
+		http.Error(w, "Formato inválido: use jsonl, csv o todotxt", http.StatusBadRequest)
+		return
+	}
+	if format == "" {
+		http.Error(w, "Tipo de contenido no admitido: use application/x-ndjson, text/csv o text/plain, o indique ?format=", http.StatusUnsupportedMediaType)
+		return
+	}
+	mapping, err := transfer.ParseMapping(query.Get("map"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	dryRun := query.Get("dry_run") == "true"
+	allowDuplicates := query.Get("duplicates") == "allow"
+
+	rows, err := transfer.ReadAll(http.MaxBytesReader(w, r.Body, maxImportBody), format, mapping, maxImportRows)
+	if err != nil {
+		http.Error(w, "Error al leer el archivo: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Las tareas visibles y las filas ya importadas cuentan como duplicados
+	source := sourceFromRequest(r)
+	existing, err := h.listVisible(r.Context(), source.userID, "")
+	if err != nil {
+		log.Printf("Error al listar las tareas para importar: %v", err)
+		http.Error(w, "Error al importar las tareas", http.StatusInternalServerError)
+		return
+	}
+	known := make(map[string]int, len(existing))
+	for _, task := range existing {
+		known[transfer.DuplicateKey(task)] = task.ID
+	}
+
+	report := models.ImportReport{Format: format, DryRun: dryRun, Total: len(rows), Rows: []models.ImportRow{}}
+	for _, row := range rows {
+		result := models.ImportRow{Line: row.Line, Status: models.ImportError, Title: row.Task.Title}
+		key := transfer.DuplicateKey(row.Task)
+		existingID, duplicate := known[key]
+		invalid := validateTask(row.Task)
+		switch {
+		case row.Err != nil:
+			result.Error = row.Err.Error()
+		case invalid != nil:
+			result.Error = invalid.Error()
+		case duplicate && !allowDuplicates:
+			result.Status = models.ImportDuplicate
+			result.TaskID = existingID
+		case dryRun:
+			result.Status = models.ImportValid
+			known[key] = 0
+		default:
+			task, err := h.createTask(r.Context(), source, row.Task)
+			if err != nil {
+				log.Printf("Error al importar la fila %d: %v", row.Line, err)
+				result.Error = "error al guardar la tarea"
+				break
+			}
+			result.Status = models.ImportCreated
+			result.TaskID = task.ID
+			known[key] = task.ID
+		}
+
+		switch result.Status {
+		case models.ImportCreated:
+			report.Created++
+		case models.ImportValid:
+			report.Valid++
+		case models.ImportDuplicate:
+			report.Duplicates++
+		default:
+			report.Failed++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/gorilla/mux"
+)
+
+func TestImportAndExportTasks(t *testing.T) {
+	taskHandler := NewTaskHandler()
+	router := mux.NewRouter()
+	router.HandleFunc("/api/import", taskHandler.ImportTasks).Methods("POST")
+	router.HandleFunc("/api/export", taskHandler.ExportTasks).Methods("GET")
+
+	importCSV := func(query string) (*httptest.ResponseRecorder, models.ImportReport) {
+		body := "Nombre,Hecho\nEjemplo de tarea 1,false\nNueva,true\n,false\nnueva ,false\n"
+		req, _ := http.NewRequest("POST", "/api/import"+query, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "text/csv")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		var report models.ImportReport
+		json.Unmarshal(rr.Body.Bytes(), &report)
+		return rr, report
+	}
+
+	// El dry run informa cada fila sin crear tareas
+	rr, report := importCSV("?map=Nombre:title,Hecho:completed&dry_run=true")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusOK)
+	}
+	if report.Valid != 1 || report.Duplicates != 2 || report.Failed != 1 || report.Created != 0 {
+		t.Errorf("Resumen del dry run incorrecto: %+v", report)
+	}
+	if row := report.Rows[0]; row.Status != models.ImportDuplicate || row.TaskID != 1 || row.Line != 2 {
+		t.Errorf("La fila 2 debió ser un duplicado de la tarea 1: %+v", row)
+	}
+	if row := report.Rows[2]; row.Status != models.ImportError || row.Line != 4 {
+		t.Errorf("La fila 4 debió fallar por no tener título: %+v", row)
+	}
+
+	_, report = importCSV("?map=Nombre:title,Hecho:completed")
+	if report.Created != 1 || report.Rows[1].TaskID != 3 {
+		t.Errorf("Importación incorrecta: %+v", report)
+	}
+
+	// Las tareas importadas aparecen en la exportación
+	req, _ := http.NewRequest("GET", "/api/export?format=csv", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || !strings.HasPrefix(rr.Header().Get("Content-Disposition"), `attachment; filename="tasks-`) {
+		t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusOK)
+	}
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[3], "3,Nueva,,true,") {
+		t.Errorf("Exportación CSV incorrecta: %q", lines)
+	}
+
+	req, _ = http.NewRequest("POST", "/api/import", strings.NewReader("<tasks/>"))
+	req.Header.Set("Content-Type", "application/xml")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusUnsupportedMediaType)
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/claudio/todo-api/internal/apiversion"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/store"
+	"github.com/gorilla/mux"
+)
+
+// errNotInTrash indica que la tarea no existe o no está en la papelera
+var errNotInTrash = errors.New("la tarea no está en la papelera")
+
+// GetTrash devuelve las tareas en la papelera visibles para el usuario, la
+// eliminada más recientemente primero
+func (h *TaskHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tasks, err := h.store.List(r.Context())
+	if err != nil {
+		log.Printf("Error al listar la papelera: %v", err)
+		http.Error(w, "Error al listar la papelera", http.StatusInternalServerError)
+		return
+	}
+
+	userID := sourceFromRequest(r).userID
+	trash := []models.Task{}
+	for _, task := range tasks {
+		if task.DeletedAt != nil && task.VisibleTo(userID) {
+			trash = append(trash, task)
+		}
+	}
+	sort.Slice(trash, func(i, j int) bool {
+		return trash[i].DeletedAt.After(*trash[j].DeletedAt)
+	})
+
+	json.NewEncoder(w).Encode(apiversion.EncodeTasks(taskCodec(r), trash))
+}
+
+// RestoreTask saca una tarea de la papelera
+func (h *TaskHandler) RestoreTask(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	source := sourceFromRequest(r)
+	task, err := h.restoreTask(r.Context(), source, id, visibleCheck(source))
+	if err == errNotInTrash || err == errTaskNotFound {
+		http.Error(w, "La tarea no está en la papelera", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error al restaurar la tarea %d: %v", id, err)
+		http.Error(w, "Error al restaurar la tarea", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(taskCodec(r).EncodeTask(*task))
+}
+
+// restoreTask saca una tarea de la papelera; devuelve errNotInTrash si la
+// tarea no existe o no está eliminada, y el error de check si no la cumple
+func (h *TaskHandler) restoreTask(ctx context.Context, source changeSource, id int, check taskCheck) (*models.Task, error) {
+	return h.mutate(ctx, source, models.ActionRestore, func(tx store.Tx) (*models.Task, *models.Task, error) {
+		before, err := tx.Get(id)
+		if err == store.ErrNotFound || (err == nil && before.DeletedAt == nil) {
+			return nil, nil, errNotInTrash
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if check != nil {
+			if err := check(before); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		task := before
+		task.DeletedAt = nil
+		task.UpdatedAt = time.Now()
+		if err := tx.Save(&task); err != nil {
+			return nil, nil, err
+		}
+		return &before, &task, nil
+	})
+}
+
+// PurgeTrash elimina definitivamente las tareas que llevan en la papelera más
+// tiempo que retention y devuelve cuántas se eliminaron
+func (h *TaskHandler) PurgeTrash(ctx context.Context, retention time.Duration) int {
+	tasks, err := h.store.List(ctx)
+	if err != nil {
+		log.Printf("Error al listar la papelera: %v", err)
+		return 0
+	}
+
+	cutoff := time.Now().Add(-retention)
+	purged := 0
+	for _, task := range tasks {
+		if task.DeletedAt == nil || !task.DeletedAt.Before(cutoff) {
+			continue
+		}
+
+		// Cada tarea se purga en su propia transacción, comprobando que siga
+		// en la papelera por si fue restaurada mientras tanto
+		id := task.ID
+		_, err := h.mutate(ctx, systemSource, models.ActionPurge, func(tx store.Tx) (*models.Task, *models.Task, error) {
+			current, err := tx.Get(id)
+			if err != nil {
+				return nil, nil, err
+			}
+			if current.DeletedAt == nil || !current.DeletedAt.Before(cutoff) {
+				return nil, nil, errNotInTrash
+			}
+			if err := tx.Delete(id); err != nil {
+				return nil, nil, err
+			}
+			return &current, nil, nil
+		})
+		if err == nil {
+			purged++
+		} else if err != errNotInTrash && err != store.ErrNotFound {
+			log.Printf("Error al purgar la tarea %d: %v", id, err)
+		}
+	}
+
+	return purged
+}
+
+// StartTrashPurger ejecuta PurgeTrash periódicamente hasta que se cancele el contexto
+func (h *TaskHandler) StartTrashPurger(ctx context.Context, retention, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if purged := h.PurgeTrash(ctx, retention); purged > 0 {
+					log.Printf("Papelera: %d tareas eliminadas definitivamente", purged)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/store"
+	"github.com/gorilla/mux"
+)
+
+func TestDeleteMovesTaskToTrash(t *testing.T) {
+	taskHandler := NewTaskHandler()
+	router := mux.NewRouter()
+	router.HandleFunc("/api/tasks", taskHandler.GetTasks).Methods("GET")
+	router.HandleFunc("/api/tasks/{id:[0-9]+}", taskHandler.DeleteTask).Methods("DELETE")
+	router.HandleFunc("/api/tasks/{id:[0-9]+}/restore", taskHandler.RestoreTask).Methods("POST")
+	router.HandleFunc("/api/trash", taskHandler.GetTrash).Methods("GET")
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := do("DELETE", "/api/tasks/1"); rr.Code != http.StatusNoContent {
+		t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusNoContent)
+	}
+
+	// La tarea ya no aparece en el listado, pero sí en la papelera
+	var tasks, trash []models.Task
+	json.Unmarshal(do("GET", "/api/tasks").Body.Bytes(), &tasks)
+	json.Unmarshal(do("GET", "/api/trash").Body.Bytes(), &trash)
+	if len(tasks) != 1 || len(trash) != 1 || trash[0].ID != 1 {
+		t.Fatalf("Listados incorrectos: tareas %+v, papelera %+v", tasks, trash)
+	}
+
+	// Restaurar la tarea
+	if rr := do("POST", "/api/tasks/1/restore"); rr.Code != http.StatusOK {
+		t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusOK)
+	}
+	json.Unmarshal(do("GET", "/api/tasks").Body.Bytes(), &tasks)
+	if len(tasks) != 2 {
+		t.Errorf("Se esperaban 2 tareas, obtuvo %d", len(tasks))
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	taskHandler := NewTaskHandler()
+	router := mux.NewRouter()
+	router.HandleFunc("/api/tasks/{id:[0-9]+}", taskHandler.DeleteTask).Methods("DELETE")
+
+	req, _ := http.NewRequest("DELETE", "/api/tasks/2", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Con una retención larga no se purga nada
+	if purged := taskHandler.PurgeTrash(context.Background(), time.Hour); purged != 0 {
+		t.Errorf("Se esperaban 0 tareas purgadas, obtuvo %d", purged)
+	}
+
+	// Con retención cero se elimina definitivamente
+	if purged := taskHandler.PurgeTrash(context.Background(), 0); purged != 1 {
+		t.Errorf("Se esperaba 1 tarea purgada, obtuvo %d", purged)
+	}
+	if _, err := taskHandler.store.Get(context.Background(), 2); err != store.ErrNotFound {
+		t.Error("Se esperaba que la tarea fuera eliminada definitivamente")
+	}
+}
+
+func TestTasksHiddenFromOtherUsers(t *testing.T) {
+	taskHandler := NewTaskHandler()
+	router := mux.NewRouter()
+	router.HandleFunc("/api/tasks", taskHandler.GetTasks).Methods("GET")
+	router.HandleFunc("/api/tasks", taskHandler.CreateTask).Methods("POST")
+	router.HandleFunc("/api/tasks/{id:[0-9]+}", taskHandler.GetTask).Methods("GET")
+	router.HandleFunc("/api/tasks/{id:[0-9]+}", taskHandler.UpdateTask).Methods("PUT")
+	router.HandleFunc("/api/tasks/{id:[0-9]+}", taskHandler.DeleteTask).Methods("DELETE")
+	router.HandleFunc("/api/tasks/{id:[0-9]+}/history", taskHandler.GetTaskHistory).Methods("GET")
+	router.HandleFunc("/api/tasks/{id:[0-9]+}/restore", taskHandler.RestoreTask).Methods("POST")
+	router.HandleFunc("/api/trash", taskHandler.GetTrash).Methods("GET")
+
+	do := func(user, method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req = req.WithContext(auth.WithIdentity(req.Context(), auth.Identity{UserID: user}))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := do("ana", "POST", "/api/tasks", `{"title": "Privada"}`); rr.Code != http.StatusCreated {
+		t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusCreated)
+	}
+
+	// Luis no ve ni puede modificar la tarea de Ana
+	var tasks []models.Task
+	json.Unmarshal(do("luis", "GET", "/api/tasks", "").Body.Bytes(), &tasks)
+	if len(tasks) != 2 {
+		t.Errorf("Se esperaban 2 tareas visibles, obtuvo %d", len(tasks))
+	}
+	for _, request := range [][3]string{
+		{"GET", "/api/tasks/3", ""},
+		{"PUT", "/api/tasks/3", `{"title": "Ajena"}`},
+		{"DELETE", "/api/tasks/3", ""},
+		{"GET", "/api/tasks/3/history", ""},
+	} {
+		if rr := do("luis", request[0], request[1], request[2]); rr.Code != http.StatusNotFound {
+			t.Errorf("%s %s: obtuvo %v, esperaba %v", request[0], request[1], rr.Code, http.StatusNotFound)
+		}
+	}
+
+	// Tampoco la ve en la papelera ni puede restaurarla
+	if rr := do("ana", "DELETE", "/api/tasks/3", ""); rr.Code != http.StatusNoContent {
+		t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusNoContent)
+	}
+	var trash []models.Task
+	json.Unmarshal(do("luis", "GET", "/api/trash", "").Body.Bytes(), &trash)
+	if len(trash) != 0 {
+		t.Errorf("Se esperaba la papelera vacía, obtuvo %+v", trash)
+	}
+	if rr := do("luis", "POST", "/api/tasks/3/restore", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusNotFound)
+	}
+	if rr := do("ana", "POST", "/api/tasks/3/restore", ""); rr.Code != http.StatusOK {
+		t.Errorf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusOK)
+	}
+}
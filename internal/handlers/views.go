@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/claudio/todo-api/internal/apiversion"
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/filter"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/store"
+	"github.com/claudio/todo-api/internal/views"
+	"github.com/gorilla/mux"
+)
+
+// ViewHandler administra las vistas guardadas del usuario y devuelve las
+// tareas que coinciden con cada una
+type ViewHandler struct {
+	views views.Store
+	tasks store.TaskStore
+}
+
+// NewViewHandler crea una nueva instancia de ViewHandler
+func NewViewHandler(viewStore views.Store, taskStore store.TaskStore) *ViewHandler {
+	return &ViewHandler{
+		views: viewStore,
+		tasks: taskStore,
+	}
+}
+
+// viewTasksResponse es la respuesta de GET /api/views/{id}/tasks: la lista
+// de tareas o, si la vista agrupa, los grupos, con las tareas en la
+// representación de la versión de la API
+type viewTasksResponse struct {
+	View   models.View   `json:"view"`
+	Tasks  []interface{} `json:"tasks,omitempty"`
+	Groups []interface{} `json:"groups,omitempty"`
+}
+
+// GetViews devuelve las vistas predefinidas seguidas de las propias y las
+// compartidas con el usuario
+func (h *ViewHandler) GetViews(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	identity, _ := auth.FromContext(r.Context())
+
+	saved, err := h.views.ListFor(r.Context(), identity.UserID)
+	if err != nil {
+		log.Printf("Error al listar las vistas: %v", err)
+		http.Error(w, "Error al listar las vistas", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(append(views.Builtins(time.Now()), saved...))
+}
+
+// GetView devuelve una vista visible para el usuario
+func (h *ViewHandler) GetView(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	view, ok := h.visibleView(w, r)
+	if !ok {
+		return
+	}
+
+	json.NewEncoder(w).Encode(view)
+}
+
+// CreateView guarda una vista del usuario autenticado
+func (h *ViewHandler) CreateView(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	identity, _ := auth.FromContext(r.Context())
+
+	view, ok := decodeView(w, r)
+	if !ok {
+		return
+	}
+
+	view.OwnerID = identity.UserID
+	if err := h.views.Create(r.Context(), &view); err != nil {
+		log.Printf("Error al crear la vista: %v", err)
+		http.Error(w, "Error al crear la vista", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(view)
+}
+
+// UpdateView reemplaza una vista. Solo el propietario puede modificarla o
+// cambiar con quién se comparte.
+func (h *ViewHandler) UpdateView(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	current, ok := h.ownedView(w, r)
+	if !ok {
+		return
+	}
+
+	view, ok := decodeView(w, r)
+	if !ok {
+		return
+	}
+
+	view.ID = current.ID
+	if err := h.views.Update(r.Context(), &view); err != nil {
+		log.Printf("Error al actualizar la vista %s: %v", current.ID, err)
+		http.Error(w, "Error al actualizar la vista", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(view)
+}
+
+// DeleteView elimina una vista del usuario
+func (h *ViewHandler) DeleteView(w http.ResponseWriter, r *http.Request) {
+	view, ok := h.ownedView(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.views.Delete(r.Context(), view.ID); err != nil && err != views.ErrNotFound {
+		log.Printf("Error al eliminar la vista %s: %v", view.ID, err)
+		http.Error(w, "Error al eliminar la vista", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetViewTasks devuelve las tareas visibles para el usuario que cumplen el
+// filtro de la vista, ordenadas y agrupadas según la vista
+func (h *ViewHandler) GetViewTasks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	identity, _ := auth.FromContext(r.Context())
+
+	view, ok := h.visibleView(w, r)
+	if !ok {
+		return
+	}
+
+	// El filtro se vuelve a interpretar para resolver las fechas relativas
+	var all []models.Task
+	var err error
+	if strings.TrimSpace(view.Filter) == "" {
+		all, err = h.tasks.List(r.Context())
+	} else {
+		expr, parseErr := filter.Parse(view.Filter, time.Now())
+		if parseErr != nil {
+			http.Error(w, "El filtro de la vista ya no es válido: "+parseErr.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		all, err = h.tasks.Find(r.Context(), expr)
+	}
+	if err != nil {
+		log.Printf("Error al listar las tareas de la vista %s: %v", view.ID, err)
+		http.Error(w, "Error al listar las tareas", http.StatusInternalServerError)
+		return
+	}
+
+	tasks := []models.Task{}
+	for _, task := range all {
+		if task.DeletedAt == nil && task.VisibleTo(identity.UserID) {
+			tasks = append(tasks, task)
+		}
+	}
+	views.Sort(tasks, view.Sort)
+
+	codec := taskCodec(r)
+	response := viewTasksResponse{View: view}
+	if view.GroupBy != "" {
+		response.Groups = apiversion.EncodeGroups(codec, views.Group(tasks, view.GroupBy))
+	} else {
+		response.Tasks = apiversion.EncodeTasks(codec, tasks)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// decodeView lee y valida la vista del cuerpo de la solicitud
+func decodeView(w http.ResponseWriter, r *http.Request) (models.View, bool) {
+	var view models.View
+	if err := json.NewDecoder(r.Body).Decode(&view); err != nil {
+		http.Error(w, "Error al decodificar JSON: "+err.Error(), http.StatusBadRequest)
+		return view, false
+	}
+
+	if err := views.Validate(view); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return view, false
+	}
+	if strings.TrimSpace(view.Filter) != "" {
+		if _, err := filter.Parse(view.Filter, time.Now()); err != nil {
+			http.Error(w, "Filtro inválido: "+err.Error(), http.StatusBadRequest)
+			return view, false
+		}
+	}
+	if view.SharedWith == nil {
+		view.SharedWith = []string{}
+	}
+	view.Builtin = false
+	return view, true
+}
+
+// visibleView obtiene la vista de la URL si el usuario puede verla
+func (h *ViewHandler) visibleView(w http.ResponseWriter, r *http.Request) (models.View, bool) {
+	identity, _ := auth.FromContext(r.Context())
+	id := mux.Vars(r)["id"]
+
+	if view, ok := views.FindBuiltin(id, time.Now()); ok {
+		return view, true
+	}
+
+	view, err := h.views.Get(r.Context(), id)
+	if err != nil && err != views.ErrNotFound {
+		log.Printf("Error al obtener la vista %s: %v", id, err)
+		http.Error(w, "Error al obtener la vista", http.StatusInternalServerError)
+		return view, false
+	}
+	if err != nil || !view.VisibleTo(identity.UserID) {
+		http.Error(w, "Vista no encontrada", http.StatusNotFound)
+		return view, false
+	}
+	return view, true
+}
+
+// ownedView obtiene la vista de la URL si pertenece al usuario. Las vistas
+// compartidas solo se pueden leer.
+func (h *ViewHandler) ownedView(w http.ResponseWriter, r *http.Request) (models.View, bool) {
+	identity, _ := auth.FromContext(r.Context())
+
+	view, ok := h.visibleView(w, r)
+	if !ok {
+		return view, false
+	}
+	if view.Builtin || view.OwnerID != identity.UserID {
+		http.Error(w, "Solo el propietario puede modificar la vista", http.StatusForbidden)
+		return view, false
+	}
+	return view, true
+}
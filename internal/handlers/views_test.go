@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/store"
+	"github.com/claudio/todo-api/internal/views"
+	"github.com/gorilla/mux"
+)
+
+func TestSavedViewsSharingAndTasks(t *testing.T) {
+	viewHandler := NewViewHandler(views.NewMemoryStore(), NewExampleTaskStore())
+	router := mux.NewRouter()
+	router.HandleFunc("/api/views", viewHandler.CreateView).Methods("POST")
+	router.HandleFunc("/api/views/{id}", viewHandler.UpdateView).Methods("PUT")
+	router.HandleFunc("/api/views/{id}/tasks", viewHandler.GetViewTasks).Methods("GET")
+
+	do := func(user, method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req = req.WithContext(auth.WithIdentity(req.Context(), auth.Identity{UserID: user}))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Un filtro inválido se rechaza al guardar
+	if rr := do("ana", "POST", "/api/views", `{"name": "Rota", "filter": "prioridad = 1"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Se esperaba 400 con un filtro inválido, obtuvo %v", rr.Code)
+	}
+
+	rr := do("ana", "POST", "/api/views", `{"name": "Ejemplos", "filter": "title:ejemplo", "sort": "-id", "shared_with": ["luis"]}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusCreated)
+	}
+	var view models.View
+	json.Unmarshal(rr.Body.Bytes(), &view)
+
+	// El usuario con quien se compartió puede ver las tareas de la vista
+	var result struct {
+		Tasks []models.Task `json:"tasks"`
+	}
+	rr = do("luis", "GET", "/api/views/"+view.ID+"/tasks", "")
+	json.Unmarshal(rr.Body.Bytes(), &result)
+	if rr.Code != http.StatusOK || len(result.Tasks) != 2 || result.Tasks[0].ID != 2 {
+		t.Errorf("Tareas de la vista incorrectas: %v %+v", rr.Code, result.Tasks)
+	}
+
+	// Pero no modificarla, y un tercero no la ve
+	if rr := do("luis", "PUT", "/api/views/"+view.ID, `{"name": "Mía"}`); rr.Code != http.StatusForbidden {
+		t.Errorf("Se esperaba 403 al modificar una vista compartida, obtuvo %v", rr.Code)
+	}
+	if rr := do("eva", "GET", "/api/views/"+view.ID+"/tasks", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("Se esperaba 404 para un usuario sin acceso, obtuvo %v", rr.Code)
+	}
+
+	// Las vistas predefinidas están disponibles para todos
+	var grouped struct {
+		Groups []models.TaskGroup `json:"groups"`
+	}
+	do("ana", "PUT", "/api/views/"+view.ID, `{"name": "Todas", "group_by": "completed"}`)
+	json.Unmarshal(do("ana", "GET", "/api/views/"+view.ID+"/tasks", "").Body.Bytes(), &grouped)
+	if len(grouped.Groups) != 2 || grouped.Groups[0].Key != false || len(grouped.Groups[0].Tasks) != 1 {
+		t.Errorf("Grupos incorrectos: %+v", grouped.Groups)
+	}
+	if rr := do("eva", "GET", "/api/views/builtin:overdue/tasks", ""); rr.Code != http.StatusOK {
+		t.Errorf("Se esperaba 200 en una vista predefinida, obtuvo %v", rr.Code)
+	}
+}
+
+func TestBuiltinViewsUseDueDates(t *testing.T) {
+	taskStore := NewExampleTaskStore()
+	today := time.Now().UTC().Truncate(24 * time.Hour).Add(12 * time.Hour)
+	yesterday, tomorrow := today.AddDate(0, 0, -1), today.AddDate(0, 0, 1)
+	taskStore.Tx(context.Background(), func(tx store.Tx) error {
+		for _, task := range []models.Task{
+			{Title: "Vencida", DueDate: &yesterday},
+			{Title: "Para hoy", DueDate: &today},
+			{Title: "Para mañana", DueDate: &tomorrow},
+			{Title: "Vencida y hecha", DueDate: &yesterday, Completed: true, CompletedAt: &today},
+		} {
+			if err := tx.Insert(&task); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	viewHandler := NewViewHandler(views.NewMemoryStore(), taskStore)
+	router := mux.NewRouter()
+	router.HandleFunc("/api/views/{id}/tasks", viewHandler.GetViewTasks).Methods("GET")
+
+	for view, want := range map[string]string{"today": "Para hoy", "upcoming": "Para mañana", "overdue": "Vencida"} {
+		req, _ := http.NewRequest("GET", "/api/views/builtin:"+view+"/tasks", nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		var response struct {
+			Tasks []models.Task `json:"tasks"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		if rr.Code != http.StatusOK || len(response.Tasks) != 1 || response.Tasks[0].Title != want {
+			t.Errorf("%s: obtuvo %v %+v, esperaba %q", view, rr.Code, response.Tasks, want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/webhooks"
+	"github.com/gorilla/mux"
+)
+
+// WebhookHandler administra las suscripciones de webhooks del usuario
+type WebhookHandler struct {
+	store      webhooks.Store
+	dispatcher *webhooks.Dispatcher
+}
+
+// NewWebhookHandler crea una nueva instancia de WebhookHandler
+func NewWebhookHandler(store webhooks.Store, dispatcher *webhooks.Dispatcher) *WebhookHandler {
+	return &WebhookHandler{
+		store:      store,
+		dispatcher: dispatcher,
+	}
+}
+
+// GetWebhooks devuelve los webhooks del usuario autenticado, sin sus secretos
+func (h *WebhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	identity, _ := auth.FromContext(r.Context())
+
+	hooks, err := h.store.List(r.Context(), identity.UserID)
+	if err != nil {
+		log.Printf("Error al obtener los webhooks: %v", err)
+		http.Error(w, "Error al obtener los webhooks", http.StatusInternalServerError)
+		return
+	}
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+	json.NewEncoder(w).Encode(hooks)
+}
+
+// CreateWebhook registra un webhook. El secreto solo se devuelve en esta respuesta.
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	identity, _ := auth.FromContext(r.Context())
+
+	var hook models.Webhook
+	if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+		http.Error(w, "Error al decodificar JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Rechazar las URL internas para que los webhooks no sirvan de puente
+	// hacia la red privada del servidor
+	if err := webhooks.ValidateURL(r.Context(), hook.URL); err != nil {
+		switch err {
+		case webhooks.ErrInvalidURL:
+			http.Error(w, "La URL debe ser http o https absoluta", http.StatusBadRequest)
+		case webhooks.ErrForbiddenTarget:
+			http.Error(w, "La URL no puede apuntar a una dirección privada, de loopback o de enlace local", http.StatusBadRequest)
+		default:
+			http.Error(w, "No se pudo resolver el host de la URL", http.StatusBadRequest)
+		}
+		return
+	}
+	for _, event := range hook.Events {
+		if event != "*" && event != models.EventTaskCreated && event != models.EventTaskUpdated && event != models.EventTaskDeleted {
+			http.Error(w, "Tipo de evento desconocido: "+event, http.StatusBadRequest)
+			return
+		}
+	}
+
+	hook.OwnerID = identity.UserID
+	hook, err := h.store.Create(r.Context(), hook)
+	if err != nil {
+		log.Printf("Error al crear el webhook: %v", err)
+		http.Error(w, "Error al crear el webhook", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(hook)
+}
+
+// DeleteWebhook elimina un webhook del usuario
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	hook, ok := h.ownedWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.Delete(r.Context(), hook.ID); err != nil && err != webhooks.ErrNotFound {
+		log.Printf("Error al eliminar el webhook %s: %v", hook.ID, err)
+		http.Error(w, "Error al eliminar el webhook", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeliveries devuelve el registro de entregas de un webhook
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	hook, ok := h.ownedWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.store.Deliveries(r.Context(), hook.ID)
+	if err != nil {
+		log.Printf("Error al obtener las entregas del webhook %s: %v", hook.ID, err)
+		http.Error(w, "Error al obtener las entregas", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// GetDeadLetters devuelve las entregas que agotaron sus reintentos
+func (h *WebhookHandler) GetDeadLetters(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	identity, _ := auth.FromContext(r.Context())
+
+	letters, err := h.store.DeadLetters(r.Context(), identity.UserID)
+	if err != nil {
+		log.Printf("Error al obtener las entregas fallidas: %v", err)
+		http.Error(w, "Error al obtener las entregas fallidas", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(letters)
+}
+
+// RetryDeadLetter vuelve a intentar una entrega fallida
+func (h *WebhookHandler) RetryDeadLetter(w http.ResponseWriter, r *http.Request) {
+	identity, _ := auth.FromContext(r.Context())
+	deliveryID := mux.Vars(r)["id"]
+
+	// Comprobar que la entrega pertenece a un webhook del usuario
+	letters, err := h.store.DeadLetters(r.Context(), identity.UserID)
+	if err != nil {
+		log.Printf("Error al obtener las entregas fallidas: %v", err)
+		http.Error(w, "Error al obtener las entregas fallidas", http.StatusInternalServerError)
+		return
+	}
+	found := false
+	for _, letter := range letters {
+		found = found || letter.DeliveryID == deliveryID
+	}
+	if !found {
+		http.Error(w, "Entrega no encontrada", http.StatusNotFound)
+		return
+	}
+
+	letter, err := h.store.TakeDeadLetter(r.Context(), deliveryID)
+	if err != nil {
+		http.Error(w, "Entrega no encontrada", http.StatusNotFound)
+		return
+	}
+
+	if err := h.dispatcher.Redeliver(r.Context(), letter); err != nil {
+		// Devolver la entrega a la lista de fallidas para no perderla
+		if err := h.store.AddDeadLetter(context.Background(), letter); err != nil {
+			log.Printf("Error al restaurar la entrega fallida %s: %v", letter.DeliveryID, err)
+		}
+		log.Printf("Error al reencolar la entrega %s: %v", letter.DeliveryID, err)
+		http.Error(w, "Error al reintentar la entrega", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ownedWebhook obtiene el webhook de la URL si pertenece al usuario autenticado
+func (h *WebhookHandler) ownedWebhook(w http.ResponseWriter, r *http.Request) (models.Webhook, bool) {
+	identity, _ := auth.FromContext(r.Context())
+
+	hook, err := h.store.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil && err != webhooks.ErrNotFound {
+		log.Printf("Error al obtener el webhook: %v", err)
+		http.Error(w, "Error al obtener el webhook", http.StatusInternalServerError)
+		return hook, false
+	}
+	if err != nil || hook.OwnerID != identity.UserID {
+		http.Error(w, "Webhook no encontrado", http.StatusNotFound)
+		return hook, false
+	}
+	return hook, true
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/claudio/todo-api/internal/apiversion"
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/events"
+	"github.com/claudio/todo-api/internal/middleware"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsSendBuffer es la cantidad de mensajes pendientes por conexión antes
+	// de considerar que el cliente es demasiado lento
+	wsSendBuffer = 64
+	// wsMaxMessageSize limita el tamaño de los mensajes del cliente
+	wsMaxMessageSize = 64 * 1024
+	wsWriteWait      = 10 * time.Second
+	wsPongWait       = 60 * time.Second
+	wsPingPeriod     = 50 * time.Second
+)
+
+// Tipos de mensajes del protocolo WebSocket
+const (
+	wsSubscribe   = "subscribe"
+	wsUnsubscribe = "unsubscribe"
+	wsCreate      = "create"
+	wsUpdate      = "update"
+	wsDelete      = "delete"
+	wsAck         = "ack"
+	wsError       = "error"
+	wsEvent       = "event"
+)
+
+// wsMessage es el formato de todos los mensajes intercambiados por WebSocket.
+// ID es el identificador de correlación que el servidor repite en el ack.
+// Sin ProjectID, subscribe y unsubscribe se aplican a todas las tareas.
+// Task y Event están en la representación de la versión de la API.
+type wsMessage struct {
+	Type      string          `json:"type"`
+	ID        string          `json:"id,omitempty"`
+	ProjectID *int            `json:"project_id,omitempty"`
+	TaskID    int             `json:"task_id,omitempty"`
+	Task      json.RawMessage `json:"task,omitempty"`
+	Event     json.RawMessage `json:"event,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// WebSocketHandler permite suscribirse a proyectos y modificar tareas por
+// una única conexión WebSocket
+type WebSocketHandler struct {
+	tasks    *TaskHandler
+	bus      *events.Bus
+	upgrader websocket.Upgrader
+}
+
+// NewWebSocketHandler crea una nueva instancia de WebSocketHandler
+func NewWebSocketHandler(tasks *TaskHandler, bus *events.Bus) *WebSocketHandler {
+	return &WebSocketHandler{
+		tasks: tasks,
+		bus:   bus,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  4096,
+			WriteBufferSize: 4096,
+			// Los navegadores no aplican CORS a WebSocket: se comprueba
+			// el origen contra los orígenes de CORS configurados
+			CheckOrigin: middleware.CheckOrigin,
+		},
+	}
+}
+
+// wsConn guarda el estado de una conexión
+type wsConn struct {
+	conn   *websocket.Conn
+	send   chan wsMessage
+	source changeSource
+	userID string
+	codec  apiversion.TaskCodec
+
+	mu       sync.Mutex
+	all      bool
+	projects map[int]bool
+	closed   bool
+}
+
+// Serve actualiza la conexión a WebSocket y atiende el protocolo
+func (h *WebSocketHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Error al actualizar la conexión WebSocket: %v", err)
+		return
+	}
+
+	identity, _ := auth.FromContext(r.Context())
+	c := &wsConn{
+		conn:     conn,
+		send:     make(chan wsMessage, wsSendBuffer),
+		source:   sourceFromRequest(r),
+		userID:   identity.UserID,
+		codec:    taskCodec(r),
+		projects: make(map[int]bool),
+	}
+
+	sub, _, _ := h.bus.Subscribe(0, c.wants)
+	// El contexto vive mientras la conexión esté abierta y conserva los
+	// valores de la solicitud, como el identificador de solicitud
+	ctx, cancel := context.WithCancel(r.Context())
+
+	go c.writeLoop(ctx)
+	go c.forwardEvents(ctx, sub)
+
+	c.readLoop(ctx, h)
+
+	cancel()
+	h.bus.Unsubscribe(sub)
+	c.close()
+}
+
+// readLoop lee y procesa los mensajes del cliente hasta que se cierre la conexión
+func (c *wsConn) readLoop(ctx context.Context, h *WebSocketHandler) {
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		// Un mensaje mal formado o con tipos incorrectos no cierra la conexión
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.enqueue(wsMessage{Type: wsError, Error: "JSON inválido"})
+			continue
+		}
+		c.enqueue(h.handle(ctx, c, msg))
+	}
+}
+
+// handle procesa un mensaje del cliente y devuelve la respuesta
+func (h *WebSocketHandler) handle(ctx context.Context, c *wsConn, msg wsMessage) wsMessage {
+	reply := wsMessage{Type: wsAck, ID: msg.ID}
+
+	switch msg.Type {
+	case wsSubscribe, wsUnsubscribe:
+		c.setSubscription(msg.ProjectID, msg.Type == wsSubscribe)
+		reply.ProjectID = msg.ProjectID
+		return reply
+
+	case wsCreate:
+		input, err := c.decodeTask(msg.Task)
+		if err != nil {
+			return wsMessage{Type: wsError, ID: msg.ID, Error: err.Error()}
+		}
+		task, err := h.tasks.createTask(ctx, c.source, input)
+		if err != nil {
+			return wsMessage{Type: wsError, ID: msg.ID, Error: err.Error()}
+		}
+		reply.Task = c.encode(c.codec.EncodeTask(task))
+		return reply
+
+	case wsUpdate:
+		input, err := c.decodeTask(msg.Task)
+		if err != nil {
+			return wsMessage{Type: wsError, ID: msg.ID, Error: err.Error()}
+		}
+		task, err := h.tasks.updateTask(ctx, c.source, msg.TaskID, input, visibleCheck(c.source))
+		if err != nil {
+			return wsMessage{Type: wsError, ID: msg.ID, Error: err.Error()}
+		}
+		reply.Task = c.encode(c.codec.EncodeTask(task))
+		return reply
+
+	case wsDelete:
+		if err := h.tasks.deleteTask(ctx, c.source, msg.TaskID, visibleCheck(c.source)); err != nil {
+			return wsMessage{Type: wsError, ID: msg.ID, Error: err.Error()}
+		}
+		reply.TaskID = msg.TaskID
+		return reply
+	}
+
+	return wsMessage{Type: wsError, ID: msg.ID, Error: "tipo de mensaje desconocido: " + msg.Type}
+}
+
+// decodeTask interpreta la tarea de un mensaje con la representación de la
+// versión de la conexión
+func (c *wsConn) decodeTask(data json.RawMessage) (models.Task, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return models.Task{}, errors.New("falta la tarea")
+	}
+	return c.codec.DecodeTask(data)
+}
+
+// encode serializa un valor para un campo del mensaje
+func (c *wsConn) encode(value interface{}) json.RawMessage {
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Error al serializar el mensaje WebSocket: %v", err)
+	}
+	return data
+}
+
+// setSubscription agrega o quita un proyecto (o todas las tareas) de la suscripción
+func (c *wsConn) setSubscription(projectID *int, subscribe bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if projectID == nil {
+		c.all = subscribe
+		if !subscribe {
+			c.projects = make(map[int]bool)
+		}
+		return
+	}
+	if subscribe {
+		c.projects[*projectID] = true
+	} else {
+		delete(c.projects, *projectID)
+	}
+}
+
+// wants indica si el evento corresponde a una suscripción de la conexión
+func (c *wsConn) wants(event models.TaskEvent) bool {
+	if !event.VisibleTo(c.userID) {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.all || c.projects[event.Task.ProjectID]
+}
+
+// forwardEvents envía al cliente los eventos de sus suscripciones
+func (c *wsConn) forwardEvents(ctx context.Context, sub *events.Subscription) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				// El bus descartó la suscripción por lentitud
+				c.closeWith(websocket.CloseTryAgainLater, "cliente demasiado lento")
+				return
+			}
+			c.enqueue(wsMessage{Type: wsEvent, Event: c.encode(apiversion.EncodeEvent(c.codec, event))})
+		}
+	}
+}
+
+// enqueue agrega un mensaje a la cola de salida. Si la cola está llena el
+// cliente no lee a tiempo y se cierra la conexión para no bloquear al servidor.
+func (c *wsConn) enqueue(msg wsMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	select {
+	case c.send <- msg:
+	default:
+		go c.closeWith(websocket.CloseTryAgainLater, "cliente demasiado lento")
+	}
+}
+
+// writeLoop escribe los mensajes pendientes y envía pings periódicos
+func (c *wsConn) writeLoop(ctx context.Context) {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-c.send:
+			if !ok {
+				return
+			}
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteJSON(msg); err != nil {
+				c.conn.Close()
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// closeWith envía un mensaje de cierre y cierra la conexión
+func (c *wsConn) closeWith(code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+	c.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(wsWriteWait))
+	c.conn.Close()
+}
+
+// close marca la conexión como cerrada y libera la cola de salida
+func (c *wsConn) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+	c.conn.Close()
+}
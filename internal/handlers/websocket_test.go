@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/claudio/todo-api/internal/events"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/outbox"
+	"github.com/gorilla/websocket"
+)
+
+func TestWebSocketCreateAndSubscribe(t *testing.T) {
+	bus := events.NewBus(100)
+	taskStore := NewExampleTaskStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	outbox.NewRelay(taskStore, outbox.NewBusSink(bus)).Start(ctx)
+
+	taskHandler := NewTaskHandler(WithTaskStore(taskStore))
+	server := httptest.NewServer(http.HandlerFunc(NewWebSocketHandler(taskHandler, bus).Serve))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	project := 7
+	conn.WriteJSON(wsMessage{Type: wsSubscribe, ID: "s1", ProjectID: &project})
+
+	var reply wsMessage
+	if err := conn.ReadJSON(&reply); err != nil || reply.Type != wsAck || reply.ID != "s1" {
+		t.Fatalf("Respuesta incorrecta a subscribe: %+v (%v)", reply, err)
+	}
+
+	// Crear una tarea en el proyecto suscrito
+	conn.WriteJSON(wsMessage{Type: wsCreate, ID: "c1", Task: json.RawMessage(`{"title": "Desde WebSocket", "project_id": 7}`)})
+
+	// Se espera el ack con correlación y el evento, en cualquier orden
+	var gotAck, gotEvent bool
+	for i := 0; i < 2; i++ {
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatal(err)
+		}
+		var task models.Task
+		var event models.TaskEvent
+		json.Unmarshal(msg.Task, &task)
+		json.Unmarshal(msg.Event, &event)
+		switch {
+		case msg.Type == wsAck && msg.ID == "c1" && task.ID == 3:
+			gotAck = true
+		case msg.Type == wsEvent && event.Type == models.EventTaskCreated && event.Task != nil:
+			gotEvent = true
+		}
+	}
+	if !gotAck || !gotEvent {
+		t.Errorf("Se esperaban ack y evento: ack=%v evento=%v", gotAck, gotEvent)
+	}
+
+	// Un mensaje desconocido devuelve un error con la misma correlación
+	conn.WriteJSON(wsMessage{Type: "rename", ID: "x1"})
+	if err := conn.ReadJSON(&reply); err != nil || reply.Type != wsError || reply.ID != "x1" {
+		t.Errorf("Respuesta incorrecta a un mensaje desconocido: %+v (%v)", reply, err)
+	}
+
+	// Un campo con un tipo incorrecto se responde sin cerrar la conexión
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"type": "update", "task_id": "1"}`))
+	if err := conn.ReadJSON(&reply); err != nil || reply.Type != wsError {
+		t.Errorf("Respuesta incorrecta a un tipo incorrecto: %+v (%v)", reply, err)
+	}
+	conn.WriteJSON(wsMessage{Type: wsUnsubscribe, ID: "u1"})
+	if err := conn.ReadJSON(&reply); err != nil || reply.Type != wsAck || reply.ID != "u1" {
+		t.Errorf("La conexión debería seguir abierta: %+v (%v)", reply, err)
+	}
+
+	// Las conexiones desde otro origen se rechazan
+	header := http.Header{"Origin": {"https://evil.example"}}
+	if _, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), header); err == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Se esperaba rechazar el origen ajeno: %v", err)
+	}
+}
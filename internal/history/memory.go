@@ -0,0 +1,114 @@
+package history
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/store"
+)
+
+// MemoryStore guarda las revisiones en memoria
+type MemoryStore struct {
+	mu        sync.RWMutex
+	revisions map[int][]models.TaskRevision
+}
+
+// NewMemoryStore crea un almacén de revisiones en memoria
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{revisions: make(map[int][]models.TaskRevision)}
+}
+
+// Append agrega una revisión al final del historial de la tarea. Las
+// transacciones en memoria no pueden fallar después de fn, así que la
+// revisión se agrega directamente.
+func (s *MemoryStore) Append(tx store.Tx, revision *models.TaskRevision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revision.Revision = len(s.revisions[revision.TaskID]) + 1
+	revision.CreatedAt = time.Now()
+	s.revisions[revision.TaskID] = append(s.revisions[revision.TaskID], *revision)
+	return nil
+}
+
+// List devuelve el historial de la tarea, de la revisión más antigua a la más reciente
+func (s *MemoryStore) List(ctx context.Context, taskID int) ([]models.TaskRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revisions := make([]models.TaskRevision, len(s.revisions[taskID]))
+	copy(revisions, s.revisions[taskID])
+	return revisions, nil
+}
+
+// ListMany devuelve el historial de varias tareas agrupado por tarea
+func (s *MemoryStore) ListMany(ctx context.Context, taskIDs []int) (map[int][]models.TaskRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make(map[int][]models.TaskRevision, len(taskIDs))
+	for _, taskID := range taskIDs {
+		revisions := make([]models.TaskRevision, len(s.revisions[taskID]))
+		copy(revisions, s.revisions[taskID])
+		result[taskID] = revisions
+	}
+	return result, nil
+}
+
+// Get devuelve una revisión concreta de la tarea
+func (s *MemoryStore) Get(ctx context.Context, taskID, revision int) (*models.TaskRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revisions := s.revisions[taskID]
+	if revision < 1 || revision > len(revisions) {
+		return nil, ErrNotFound
+	}
+	rev := revisions[revision-1]
+	return &rev, nil
+}
+
+// ForProject recorre el historial de todas las tareas
+func (s *MemoryStore) ForProject(ctx context.Context, projectID int, until time.Time) ([]models.TaskRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	taskIDs := []int{}
+	for taskID, revisions := range s.revisions {
+		for _, revision := range revisions {
+			if revision.CreatedAt.Before(until) && revision.Task.ProjectID == projectID {
+				taskIDs = append(taskIDs, taskID)
+				break
+			}
+		}
+	}
+	sort.Ints(taskIDs)
+
+	result := []models.TaskRevision{}
+	for _, taskID := range taskIDs {
+		for _, revision := range s.revisions[taskID] {
+			if revision.CreatedAt.Before(until) {
+				result = append(result, revision)
+			}
+		}
+	}
+	return result, nil
+}
+
+// AsOf devuelve la última revisión creada hasta el instante indicado
+func (s *MemoryStore) AsOf(ctx context.Context, taskID int, at time.Time) (*models.TaskRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revisions := s.revisions[taskID]
+	for i := len(revisions) - 1; i >= 0; i-- {
+		if !revisions[i].CreatedAt.After(at) {
+			rev := revisions[i]
+			return &rev, nil
+		}
+	}
+	return nil, ErrNotFound
+}
@@ -0,0 +1,164 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/store"
+	"github.com/lib/pq"
+)
+
+// PostgresStore guarda las revisiones en la tabla task_revisions
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore crea un almacén de revisiones respaldado por PostgreSQL
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+const selectRevision = `SELECT task_id, revision, action, snapshot, changes, actor, created_at FROM task_revisions`
+
+// errNotSQLTx indica que la transacción no es de PostgresTaskStore
+var errNotSQLTx = errors.New("el historial en PostgreSQL requiere una transacción de PostgreSQL")
+
+// Append inserta la siguiente revisión de la tarea dentro de la transacción
+// de la tarea. Bloquea la fila de la tarea para que dos transacciones no
+// calculen el mismo número de revisión; si la tarea se acaba de purgar, el
+// DELETE de la misma transacción ya tiene el bloqueo.
+func (s *PostgresStore) Append(tx store.Tx, revision *models.TaskRevision) error {
+	sqlTx, ok := tx.(store.SQLTx)
+	if !ok {
+		return errNotSQLTx
+	}
+	ctx, q := sqlTx.SQL()
+
+	if _, err := q.ExecContext(ctx, `SELECT 1 FROM tasks WHERE id = $1 FOR UPDATE`, revision.TaskID); err != nil {
+		return err
+	}
+
+	snapshot, err := json.Marshal(revision.Task)
+	if err != nil {
+		return err
+	}
+	changes, err := json.Marshal(revision.Changes)
+	if err != nil {
+		return err
+	}
+
+	return q.QueryRowContext(ctx,
+		`INSERT INTO task_revisions (task_id, revision, action, snapshot, changes, actor, created_at)
+		 SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4, $5, NOW()
+		 FROM task_revisions WHERE task_id = $1
+		 RETURNING revision, created_at`,
+		revision.TaskID, revision.Action, snapshot, changes, revision.Actor,
+	).Scan(&revision.Revision, &revision.CreatedAt)
+}
+
+// List devuelve el historial de la tarea, de la revisión más antigua a la más reciente
+func (s *PostgresStore) List(ctx context.Context, taskID int) ([]models.TaskRevision, error) {
+	rows, err := s.db.QueryContext(ctx, selectRevision+` WHERE task_id = $1 ORDER BY revision`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.TaskRevision{}
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *revision)
+	}
+	return revisions, rows.Err()
+}
+
+// ListMany devuelve el historial de varias tareas agrupado por tarea
+func (s *PostgresStore) ListMany(ctx context.Context, taskIDs []int) (map[int][]models.TaskRevision, error) {
+	ids := make([]int64, len(taskIDs))
+	result := make(map[int][]models.TaskRevision, len(taskIDs))
+	for i, taskID := range taskIDs {
+		ids[i] = int64(taskID)
+		result[taskID] = []models.TaskRevision{}
+	}
+
+	rows, err := s.db.QueryContext(ctx, selectRevision+` WHERE task_id = ANY($1) ORDER BY task_id, revision`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[revision.TaskID] = append(result[revision.TaskID], *revision)
+	}
+	return result, rows.Err()
+}
+
+// Get devuelve una revisión concreta de la tarea
+func (s *PostgresStore) Get(ctx context.Context, taskID, revision int) (*models.TaskRevision, error) {
+	row := s.db.QueryRowContext(ctx, selectRevision+` WHERE task_id = $1 AND revision = $2`, taskID, revision)
+	return scanRevision(row)
+}
+
+// AsOf devuelve la última revisión creada hasta el instante indicado
+func (s *PostgresStore) AsOf(ctx context.Context, taskID int, at time.Time) (*models.TaskRevision, error) {
+	row := s.db.QueryRowContext(ctx,
+		selectRevision+` WHERE task_id = $1 AND created_at <= $2 ORDER BY revision DESC LIMIT 1`, taskID, at)
+	return scanRevision(row)
+}
+
+// ForProject busca las tareas por el project_id guardado en sus revisiones
+func (s *PostgresStore) ForProject(ctx context.Context, projectID int, until time.Time) ([]models.TaskRevision, error) {
+	rows, err := s.db.QueryContext(ctx,
+		selectRevision+` WHERE created_at < $2 AND task_id IN (
+		   SELECT DISTINCT task_id FROM task_revisions
+		   WHERE (snapshot->>'project_id')::INTEGER = $1 AND created_at < $2)
+		 ORDER BY task_id, revision`, projectID, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.TaskRevision{}
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *revision)
+	}
+	return revisions, rows.Err()
+}
+
+// scanner es implementado por *sql.Row y *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanRevision lee una fila de task_revisions
+func scanRevision(row scanner) (*models.TaskRevision, error) {
+	var revision models.TaskRevision
+	var snapshot, changes []byte
+	err := row.Scan(&revision.TaskID, &revision.Revision, &revision.Action,
+		&snapshot, &changes, &revision.Actor, &revision.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	json.Unmarshal(snapshot, &revision.Task)
+	json.Unmarshal(changes, &revision.Changes)
+	return &revision, nil
+}
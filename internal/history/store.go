@@ -0,0 +1,31 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/store"
+)
+
+// ErrNotFound indica que no existe la revisión solicitada
+var ErrNotFound = errors.New("revisión no encontrada")
+
+// Store guarda las revisiones de cada tarea. Append asigna el número de
+// revisión siguiente dentro de la tarea.
+type Store interface {
+	// Append agrega la revisión dentro de la transacción de la modificación,
+	// para que ambas se confirmen o se descarten juntas
+	Append(tx store.Tx, revision *models.TaskRevision) error
+	List(ctx context.Context, taskID int) ([]models.TaskRevision, error)
+	// ListMany devuelve en una sola consulta el historial de varias tareas,
+	// agrupado por tarea
+	ListMany(ctx context.Context, taskIDs []int) (map[int][]models.TaskRevision, error)
+	Get(ctx context.Context, taskID, revision int) (*models.TaskRevision, error)
+	AsOf(ctx context.Context, taskID int, at time.Time) (*models.TaskRevision, error)
+	// ForProject devuelve, ordenadas por tarea y revisión, las revisiones
+	// anteriores a until de las tareas que pertenecieron al proyecto en
+	// alguna de ellas
+	ForProject(ctx context.Context, projectID int, until time.Time) ([]models.TaskRevision, error)
+}
@@ -0,0 +1,181 @@
+// Package ical escribe tareas como componentes VTODO de iCalendar (RFC 5545)
+// y lee los VTODO que envían los clientes CalDAV.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// ContentType es el tipo de contenido de un documento iCalendar
+const ContentType = "text/calendar; charset=utf-8"
+
+// prodID identifica a la aplicación que generó el calendario
+const prodID = "-//todo-api//Tareas//ES"
+
+// maxLineOctets es la longitud máxima de una línea antes de plegarla
+const maxLineOctets = 75
+
+// UID devuelve el identificador iCalendar de una tarea
+func UID(taskID int) string {
+	return "task-" + strconv.Itoa(taskID) + "@todo-api"
+}
+
+// Calendar devuelve un VCALENDAR con un VTODO por tarea. name se publica en
+// X-WR-CALNAME, que los clientes muestran como nombre del calendario.
+func Calendar(name string, tasks []models.Task) []byte {
+	var b builder
+	b.begin("VCALENDAR")
+	b.property("VERSION", "2.0")
+	b.property("PRODID", prodID)
+	b.property("CALSCALE", "GREGORIAN")
+	if name != "" {
+		b.property("X-WR-CALNAME", escapeText(name))
+	}
+	for _, task := range tasks {
+		b.todo(UID(task.ID), task)
+	}
+	b.end("VCALENDAR")
+	return b.buf.Bytes()
+}
+
+// Resource devuelve un VCALENDAR con el VTODO de una sola tarea, como se
+// publica cada recurso en CalDAV. uid es el que eligió el cliente al crear la
+// tarea o, si la tarea no se creó por CalDAV, UID(task.ID).
+func Resource(uid string, task models.Task) []byte {
+	var b builder
+	b.begin("VCALENDAR")
+	b.property("VERSION", "2.0")
+	b.property("PRODID", prodID)
+	b.todo(uid, task)
+	b.end("VCALENDAR")
+	return b.buf.Bytes()
+}
+
+// builder escribe líneas de contenido terminadas en CRLF y plegadas a 75 octetos
+type builder struct {
+	buf bytes.Buffer
+}
+
+func (b *builder) begin(component string) {
+	b.line("BEGIN:" + component)
+}
+
+func (b *builder) end(component string) {
+	b.line("END:" + component)
+}
+
+func (b *builder) property(name, value string) {
+	b.line(name + ":" + value)
+}
+
+// todo escribe el VTODO de una tarea
+func (b *builder) todo(uid string, task models.Task) {
+	b.begin("VTODO")
+	for _, p := range todoProperties(uid, task) {
+		b.property(p.name, p.value)
+	}
+	b.end("VTODO")
+}
+
+// property es una propiedad de un componente con su valor ya escapado
+type property struct {
+	name  string
+	value string
+}
+
+// todoProperties devuelve, en orden, las propiedades del VTODO de una tarea
+func todoProperties(uid string, task models.Task) []property {
+	props := []property{
+		{"UID", escapeText(uid)},
+		{"DTSTAMP", formatTime(task.UpdatedAt)},
+		{"CREATED", formatTime(task.CreatedAt)},
+		{"LAST-MODIFIED", formatTime(task.UpdatedAt)},
+		{"SUMMARY", escapeText(task.Title)},
+	}
+	if task.Description != "" {
+		props = append(props, property{"DESCRIPTION", escapeText(task.Description)})
+	}
+	if task.Completed {
+		props = append(props, property{"STATUS", "COMPLETED"}, property{"PERCENT-COMPLETE", "100"})
+		if task.CompletedAt != nil {
+			props = append(props, property{"COMPLETED", formatTime(*task.CompletedAt)})
+		}
+	} else {
+		props = append(props, property{"STATUS", "NEEDS-ACTION"})
+	}
+	if task.DueDate != nil {
+		props = append(props, property{"DUE", formatTime(*task.DueDate)})
+	}
+	if task.Priority != 0 {
+		props = append(props, property{"PRIORITY", strconv.Itoa(task.Priority)})
+	}
+	if task.Recurrence != "" {
+		props = append(props, property{"RRULE", task.Recurrence})
+	}
+	var categories []string
+	if task.ProjectID != 0 {
+		categories = append(categories, fmt.Sprintf("project-%d", task.ProjectID))
+	}
+	for _, tag := range task.Tags {
+		categories = append(categories, escapeText(tag))
+	}
+	if len(categories) > 0 {
+		props = append(props, property{"CATEGORIES", strings.Join(categories, ",")})
+	}
+	return props
+}
+
+// Properties devuelve los valores sin escapar de las propiedades del VTODO
+// de una tarea, para evaluar filtros sobre ellas
+func Properties(uid string, task models.Task) map[string]string {
+	values := map[string]string{}
+	for _, p := range todoProperties(uid, task) {
+		values[p.name] = unescapeText(p.value)
+	}
+	return values
+}
+
+// line escribe una línea plegándola en varias si supera maxLineOctets. Las
+// continuaciones empiezan con un espacio y nunca parten un carácter UTF-8.
+func (b *builder) line(text string) {
+	limit := maxLineOctets
+	for len(text) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		b.buf.WriteString(text[:cut])
+		b.buf.WriteString("\r\n ")
+		text = text[cut:]
+		// El espacio inicial cuenta en la longitud de la continuación
+		limit = maxLineOctets - 1
+	}
+	b.buf.WriteString(text)
+	b.buf.WriteString("\r\n")
+}
+
+// formatTime escribe una fecha en UTC con el formato de DATE-TIME
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// textEscaper escapa los caracteres especiales de los valores TEXT
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func escapeText(text string) string {
+	return textEscaper.Replace(text)
+}
+
+// textUnescaper deshace textEscaper; \N también es un salto de línea
+var textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func unescapeText(text string) string {
+	return textUnescaper.Replace(text)
+}
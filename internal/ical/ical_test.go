@@ -0,0 +1,93 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+func TestCalendar(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 10, 30, 0, 0, time.FixedZone("CEST", 2*3600))
+	tasks := []models.Task{
+		{ID: 1, Title: "Comprar pan; leche, huevos", Description: "Línea 1\nLínea 2", Completed: true, ProjectID: 4, CreatedAt: now, UpdatedAt: now, CompletedAt: &now},
+		{ID: 2, Title: strings.Repeat("ñ", 60), CreatedAt: now, UpdatedAt: now},
+	}
+	text := string(Calendar("Tareas", tasks))
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"UID:task-1@todo-api\r\n",
+		"DTSTAMP:20261019T083000Z\r\n",
+		`SUMMARY:Comprar pan\; leche\, huevos` + "\r\n",
+		`DESCRIPTION:Línea 1\nLínea 2` + "\r\n",
+		"STATUS:COMPLETED\r\nPERCENT-COMPLETE:100\r\nCOMPLETED:20261019T083000Z\r\n",
+		"CATEGORIES:project-4\r\n",
+		"STATUS:NEEDS-ACTION\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("El calendario no contiene %q:\n%s", want, text)
+		}
+	}
+
+	// Las líneas largas se pliegan sin partir caracteres UTF-8
+	var unfolded []string
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("Línea de %d octetos: %q", len(line), line)
+		}
+		if strings.HasPrefix(line, " ") {
+			unfolded[len(unfolded)-1] += line[1:]
+			continue
+		}
+		unfolded = append(unfolded, line)
+	}
+	if !strings.Contains(strings.Join(unfolded, "\n"), "SUMMARY:"+tasks[1].Title+"\n") {
+		t.Errorf("El resumen plegado no se reconstruye: %q", unfolded)
+	}
+}
+
+func TestParseTodo(t *testing.T) {
+	data := "BEGIN:VCALENDAR\nVERSION:2.0\nBEGIN:VTODO\nUID:abc\nSUMMARY;LANGUAGE=es:Comprar pan\\, leche y a\n lgo más\nDESCRIPTION;ALTREP=\"cid:x:y\":Dos\\nlíneas\nCOMPLETED:20261019T100000Z\n" +
+		"BEGIN:VALARM\nSUMMARY:Alarma\nEND:VALARM\nEND:VTODO\nBEGIN:VTODO\nSUMMARY:Segunda\nEND:VTODO\nEND:VCALENDAR\n"
+	todo, err := ParseTodo([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if todo.UID != "abc" || todo.Summary != "Comprar pan, leche y algo más" || todo.Description != "Dos\nlíneas" || !todo.Completed {
+		t.Errorf("VTODO incorrecto: %+v", todo)
+	}
+
+	if _, err := ParseTodo([]byte("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n")); err != ErrNoTodo {
+		t.Errorf("Se esperaba ErrNoTodo, obtuvo %v", err)
+	}
+	if _, err := ParseTodo([]byte("BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nEND:VCALENDAR\r\n")); err == nil {
+		t.Error("Se esperaba un error con componentes sin cerrar")
+	}
+}
+
+func TestParseTodoDuePriorityAndRecurrence(t *testing.T) {
+	data := "BEGIN:VCALENDAR\nBEGIN:VTODO\nSUMMARY:Regar\nDUE;TZID=Europe/Madrid:20261020T090000\nPRIORITY:3\nRRULE:FREQ=WEEKLY;BYDAY=MO,TH\nCATEGORIES:project-4,work\nCATEGORIES:home\nEND:VTODO\nEND:VCALENDAR\n"
+	todo, err := ParseTodo([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2026, time.October, 20, 7, 0, 0, 0, time.UTC)
+	if todo.Due == nil || !todo.Due.Equal(want) || todo.Priority != 3 || todo.Recurrence != "FREQ=WEEKLY;BYDAY=MO,TH" {
+		t.Errorf("VTODO incorrecto: %+v", todo)
+	}
+	if strings.Join(todo.Tags, ",") != "work,home" {
+		t.Errorf("Etiquetas incorrectas: %v", todo.Tags)
+	}
+	if err := ValidateRecurrence(todo.Recurrence); err != nil {
+		t.Errorf("Recurrencia válida rechazada: %v", err)
+	}
+
+	for _, rule := range []string{"FREQ=HOURLY", "FREQ=DAILY;COUNT=0", "FREQ=WEEKLY;BYDAY=XX", "FREQ=DAILY;COUNT=2;UNTIL=20261231", "INTERVAL=2"} {
+		if err := ValidateRecurrence(rule); err == nil {
+			t.Errorf("Se esperaba rechazar %s", rule)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package ical
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// projectCategory reconoce la categoría con la que se exporta el proyecto
+var projectCategory = regexp.MustCompile(`^project-[0-9]+$`)
+
+// ErrNoTodo indica que el documento no contiene ningún VTODO
+var ErrNoTodo = errors.New("el calendario no contiene un VTODO")
+
+// Todo son los datos de un VTODO que se guardan en la tarea
+type Todo struct {
+	UID         string
+	Summary     string
+	Description string
+	Completed   bool
+	Due         *time.Time
+	Priority    int
+	Recurrence  string
+	Tags        []string
+}
+
+// ParseTodo lee el primer VTODO de un documento iCalendar. Las propiedades
+// que las tareas no guardan y los componentes anidados como VALARM se
+// ignoran. La tarea está completada si STATUS es COMPLETED o, sin STATUS, si
+// tiene la propiedad COMPLETED.
+func ParseTodo(data []byte) (Todo, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	// Desplegar las líneas: una continuación empieza con espacio o tabulador
+	text = strings.NewReplacer("\n ", "", "\n\t", "").Replace(text)
+
+	var todo Todo
+	var stack []string
+	var found, done bool
+	status, completed := "", false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		name, params, value, ok := splitLine(line)
+		if !ok {
+			return todo, errors.New("línea iCalendar inválida: " + line)
+		}
+
+		switch name {
+		case "BEGIN":
+			component := strings.ToUpper(value)
+			if len(stack) == 0 && component != "VCALENDAR" {
+				return todo, errors.New("el documento debe empezar con BEGIN:VCALENDAR")
+			}
+			stack = append(stack, component)
+			found = found || component == "VTODO"
+			continue
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1] != strings.ToUpper(value) {
+				return todo, errors.New("END:" + value + " no cierra ningún componente")
+			}
+			if stack[len(stack)-1] == "VTODO" {
+				done = true
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		// Solo interesan las propiedades del primer VTODO, no las de sus
+		// componentes anidados
+		if done || len(stack) == 0 || stack[len(stack)-1] != "VTODO" {
+			continue
+		}
+		switch name {
+		case "UID":
+			todo.UID = value
+		case "SUMMARY":
+			todo.Summary = unescapeText(value)
+		case "DESCRIPTION":
+			todo.Description = unescapeText(value)
+		case "STATUS":
+			status = strings.ToUpper(value)
+		case "COMPLETED":
+			completed = true
+		case "DUE":
+			due, err := parseDue(params, value)
+			if err != nil {
+				return todo, err
+			}
+			todo.Due = &due
+		case "PRIORITY":
+			priority, err := strconv.Atoi(value)
+			if err != nil || priority < 0 || priority > 9 {
+				return todo, errors.New("PRIORITY debe estar entre 0 y 9")
+			}
+			todo.Priority = priority
+		case "RRULE":
+			todo.Recurrence = value
+		case "CATEGORIES":
+			todo.Tags = append(todo.Tags, parseCategories(value)...)
+		}
+	}
+
+	if len(stack) != 0 {
+		return todo, errors.New("falta END:" + stack[len(stack)-1])
+	}
+	if !found {
+		return todo, ErrNoTodo
+	}
+	if status != "" {
+		todo.Completed = status == "COMPLETED"
+	} else {
+		todo.Completed = completed
+	}
+	return todo, nil
+}
+
+// parseDue lee el valor de DUE respetando el parámetro TZID; una zona
+// desconocida se trata como UTC
+func parseDue(params map[string]string, value string) (time.Time, error) {
+	var loc *time.Location
+	if tzid := params["TZID"]; tzid != "" {
+		loc, _ = time.LoadLocation(tzid)
+	}
+	due, err := parseDateTime(value, loc)
+	if err != nil {
+		return due, errors.New("DUE inválido: " + value)
+	}
+	return due.UTC(), nil
+}
+
+// parseCategories separa los valores de CATEGORIES por las comas sin escapar.
+// Las categorías project-N las genera la API para el proyecto y no son
+// etiquetas.
+func parseCategories(value string) []string {
+	var tags, parts []string
+	var current strings.Builder
+	escaped := false
+	for _, c := range value {
+		switch {
+		case escaped:
+			current.WriteRune('\\')
+			current.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == ',':
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(c)
+		}
+	}
+	parts = append(parts, current.String())
+
+	for _, part := range parts {
+		category := strings.TrimSpace(unescapeText(part))
+		if category != "" && !projectCategory.MatchString(category) {
+			tags = append(tags, category)
+		}
+	}
+	return tags
+}
+
+// splitLine separa el nombre, los parámetros y el valor de una línea de
+// contenido. Los valores de los parámetros entre comillas pueden contener
+// ":" y ";".
+func splitLine(line string) (string, map[string]string, string, bool) {
+	nameEnd := -1
+	quoted := false
+	for i, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == ';' && nameEnd < 0:
+			nameEnd = i
+		case c == ':':
+			if nameEnd < 0 {
+				nameEnd = i
+			}
+			if nameEnd == 0 {
+				return "", nil, "", false
+			}
+			return strings.ToUpper(line[:nameEnd]), parseParams(line[nameEnd:i]), line[i+1:], true
+		}
+	}
+	return "", nil, "", false
+}
+
+// parseParams lee los parámetros ";NOMBRE=valor" de una línea de contenido
+func parseParams(text string) map[string]string {
+	params := map[string]string{}
+	for text != "" {
+		text = strings.TrimPrefix(text, ";")
+		end, quoted := len(text), false
+		for i, c := range text {
+			if c == '"' {
+				quoted = !quoted
+			} else if c == ';' && !quoted {
+				end = i
+				break
+			}
+		}
+		if name, value, ok := strings.Cut(text[:end], "="); ok {
+			params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+		}
+		text = text[end:]
+	}
+	return params
+}
@@ -0,0 +1,87 @@
+package ical
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// frequencies son los valores de FREQ admitidos en las recurrencias
+var frequencies = map[string]bool{"DAILY": true, "WEEKLY": true, "MONTHLY": true, "YEARLY": true}
+
+// weekdays son los días de BYDAY
+var weekdays = map[string]bool{"MO": true, "TU": true, "WE": true, "TH": true, "FR": true, "SA": true, "SU": true}
+
+// ValidateRecurrence comprueba una regla RRULE. Se admite el subconjunto que
+// usan las apps de tareas: FREQ diaria, semanal, mensual o anual, con
+// INTERVAL, COUNT, UNTIL, BYDAY y BYMONTHDAY opcionales.
+func ValidateRecurrence(rule string) error {
+	parts := map[string]string{}
+	for _, part := range strings.Split(rule, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return errors.New("recurrencia inválida: se esperaba NOMBRE=VALOR en " + part)
+		}
+		name = strings.ToUpper(name)
+		if _, repeated := parts[name]; repeated {
+			return errors.New("recurrencia inválida: " + name + " está repetido")
+		}
+		parts[name] = strings.ToUpper(value)
+	}
+
+	if !frequencies[parts["FREQ"]] {
+		return errors.New("recurrencia inválida: FREQ debe ser DAILY, WEEKLY, MONTHLY o YEARLY")
+	}
+	if _, ok := parts["COUNT"]; ok {
+		if _, ok := parts["UNTIL"]; ok {
+			return errors.New("recurrencia inválida: COUNT y UNTIL no pueden usarse juntos")
+		}
+	}
+	for name, value := range parts {
+		switch name {
+		case "FREQ":
+		case "INTERVAL", "COUNT":
+			if n, err := strconv.Atoi(value); err != nil || n < 1 {
+				return errors.New("recurrencia inválida: " + name + " debe ser un entero positivo")
+			}
+		case "UNTIL":
+			if _, err := parseDateTime(value, nil); err != nil {
+				return errors.New("recurrencia inválida: UNTIL debe ser una fecha iCalendar")
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				// Se admite un ordinal delante del día, como 1MO o -1FR
+				day = strings.TrimLeft(day, "+-0123456789")
+				if !weekdays[day] {
+					return errors.New("recurrencia inválida: día desconocido en BYDAY")
+				}
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				if n, err := strconv.Atoi(day); err != nil || n == 0 || n < -31 || n > 31 {
+					return errors.New("recurrencia inválida: BYMONTHDAY debe estar entre -31 y 31")
+				}
+			}
+		default:
+			return errors.New("recurrencia inválida: " + name + " no está admitido")
+		}
+	}
+	return nil
+}
+
+// parseDateTime lee un valor DATE o DATE-TIME. Las horas con Z son UTC, las
+// que no la llevan se interpretan en loc o, sin loc, en UTC; las fechas sin
+// hora son el inicio del día en esa zona.
+func parseDateTime(value string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+	if strings.Contains(value, "T") {
+		return time.ParseInLocation("20060102T150405", value, loc)
+	}
+	return time.ParseInLocation("20060102", value, loc)
+}
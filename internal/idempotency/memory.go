@@ -0,0 +1,83 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// MemoryStore guarda las claves de idempotencia en memoria
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]*Record
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore crea un almacén de claves de idempotencia en memoria
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records:   make(map[string]*Record),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Reserve registra la clave si no existe o si ya expiró
+func (s *MemoryStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if record, ok := s.records[key]; ok && now.Before(record.ExpiresAt) {
+		return *record, false, nil
+	}
+
+	record := &Record{
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(ttl),
+	}
+	s.records[key] = record
+	return *record, true, nil
+}
+
+// Complete guarda la respuesta de la clave
+func (s *MemoryStore) Complete(ctx context.Context, key string, statusCode int, header http.Header, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.records[key]; ok {
+		record.Completed = true
+		record.StatusCode = statusCode
+		record.Header = header
+		record.Body = body
+	}
+	return nil
+}
+
+// Release elimina la clave
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// sweep elimina una vez por minuto las claves expiradas
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, record := range s.records {
+		if !now.Before(record.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// PostgresStore guarda las claves de idempotencia en la tabla idempotency_keys
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore crea un almacén de claves respaldado por PostgreSQL
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Reserve inserta la clave, o la reemplaza si ya expiró, en una sola sentencia
+// para que dos solicitudes concurrentes no puedan reservarla a la vez
+func (s *PostgresStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (Record, bool, error) {
+	record := Record{Key: key, Fingerprint: fingerprint}
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO idempotency_keys (key, fingerprint, completed, created_at, expires_at)
+		 VALUES ($1, $2, FALSE, NOW(), NOW() + $3 * INTERVAL '1 second')
+		 ON CONFLICT (key) DO UPDATE SET fingerprint = EXCLUDED.fingerprint, completed = FALSE,
+		   status_code = NULL, headers = NULL, body = NULL,
+		   created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+		 WHERE idempotency_keys.expires_at <= NOW()
+		 RETURNING expires_at`,
+		key, fingerprint, ttl.Seconds(),
+	).Scan(&record.ExpiresAt)
+	if err == nil {
+		return record, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return Record{}, false, err
+	}
+
+	// La clave existe y no expiró: devolver lo guardado
+	var statusCode sql.NullInt64
+	var headers []byte
+	err = s.db.QueryRowContext(ctx,
+		`SELECT fingerprint, completed, status_code, headers, body, expires_at
+		 FROM idempotency_keys WHERE key = $1`, key,
+	).Scan(&record.Fingerprint, &record.Completed, &statusCode, &headers, &record.Body, &record.ExpiresAt)
+	if err != nil {
+		return Record{}, false, err
+	}
+	record.StatusCode = int(statusCode.Int64)
+	if len(headers) > 0 {
+		if err := json.Unmarshal(headers, &record.Header); err != nil {
+			return Record{}, false, err
+		}
+	}
+	return record, false, nil
+}
+
+// Complete guarda la respuesta de la clave
+func (s *PostgresStore) Complete(ctx context.Context, key string, statusCode int, header http.Header, body []byte) error {
+	headers, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`UPDATE idempotency_keys SET completed = TRUE, status_code = $2, headers = $3, body = $4
+		 WHERE key = $1`,
+		key, statusCode, headers, body)
+	return err
+}
+
+// Release elimina la clave
+func (s *PostgresStore) Release(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = $1`, key)
+	return err
+}
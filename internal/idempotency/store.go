@@ -0,0 +1,30 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Record es la respuesta guardada para una clave de idempotencia. Mientras
+// la solicitud original se procesa Completed es false.
+type Record struct {
+	Key         string
+	Fingerprint string
+	Completed   bool
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// Store guarda las claves de idempotencia y sus respuestas
+type Store interface {
+	// Reserve registra la clave como en curso si no existe o expiró y
+	// devuelve true. Si la clave ya existe devuelve el registro guardado y false.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (Record, bool, error)
+	// Complete guarda la respuesta de una clave reservada
+	Complete(ctx context.Context, key string, statusCode int, header http.Header, body []byte) error
+	// Release elimina una clave reservada para que la solicitud pueda reintentarse
+	Release(ctx context.Context, key string) error
+}
@@ -3,29 +3,97 @@ package middleware
 import (
 	"net/http"
 	"strings"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/logger"
 )
 
-// AuthMiddleware verifica que las solicitudes tengan un token válido
-func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Obtener el token del encabezado Authorization
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Se requiere autorización", http.StatusUnauthorized)
-			return
-		}
+// AuthMiddleware verifica que las solicitudes tengan un token de acceso válido
+// y agrega la identidad del usuario al contexto de la solicitud
+func AuthMiddleware(sessions auth.SessionStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Obtener el token del encabezado Authorization
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				http.Error(w, "Se requiere autorización", http.StatusUnauthorized)
+				return
+			}
 
-		// Verificar el formato del token (Bearer token)
-		tokenParts := strings.Split(authHeader, " ")
-		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			http.Error(w, "Formato de autorización inválido", http.StatusUnauthorized)
-			return
-		}
+			authenticate(sessions, next, w, r, authHeader)
+		})
+	}
+}
 
-		// Aquí implementarías la verificación real del token
-		// Por ahora, simplemente permitimos cualquier token
-		
-		// Continuar con la siguiente función en la cadena
+// OptionalAuthMiddleware agrega la identidad al contexto cuando la solicitud
+// incluye un token, pero permite continuar a las solicitudes anónimas.
+// Un token inválido se rechaza igual que en AuthMiddleware.
+func OptionalAuthMiddleware(sessions auth.SessionStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			authenticate(sessions, next, w, r, authHeader)
+		})
+	}
+}
+
+// TokenFromQuery copia el parámetro access_token al encabezado Authorization.
+// Solo debe usarse en rutas donde el cliente no puede enviar encabezados,
+// como WebSocket desde el navegador.
+func TokenFromQuery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("access_token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
 		next.ServeHTTP(w, r)
 	})
-}
\ No newline at end of file
+}
+
+// authenticate valida el encabezado Authorization y continúa con la identidad en el contexto
+func authenticate(sessions auth.SessionStore, next http.Handler, w http.ResponseWriter, r *http.Request, authHeader string) {
+	// Verificar el formato del token (Bearer token)
+	tokenParts := strings.Split(authHeader, " ")
+	if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+		http.Error(w, "Formato de autorización inválido", http.StatusUnauthorized)
+		return
+	}
+
+	// Verificar el token contra las sesiones activas
+	identity, err := sessions.Authenticate(r.Context(), tokenParts[1])
+	if err != nil && err != auth.ErrInvalidToken {
+		logger.ErrorLogger.Printf("Error al validar el token de acceso: %v", err)
+		http.Error(w, "Error al validar el token de acceso", http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		http.Error(w, "Token inválido o expirado", http.StatusUnauthorized)
+		return
+	}
+
+	// Continuar con la siguiente función en la cadena
+	next.ServeHTTP(w, r.WithContext(auth.WithIdentity(r.Context(), identity)))
+}
+
+// BasicAuthMiddleware autentica con usuario y clave (HTTP Basic) contra los
+// usuarios configurados. Lo usan los clientes CalDAV, que no pueden obtener
+// tokens de acceso; la identidad no tiene sesión asociada.
+func BasicAuthMiddleware(users *auth.UserStore, realm string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			username, password, ok := r.BasicAuth()
+			if !ok || !users.Verify(username, password) {
+				w.Header().Set("WWW-Authenticate", `Basic realm="`+realm+`", charset="UTF-8"`)
+				http.Error(w, "Se requiere autorización", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithIdentity(r.Context(), auth.Identity{UserID: username})))
+		})
+	}
+}
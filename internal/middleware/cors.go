@@ -2,9 +2,57 @@ package middleware
 
 import (
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
 	"github.com/claudio/todo-api/internal/logger"
 )
 
+// allowedOrigins devuelve los orígenes de CORS_ALLOWED_ORIGINS, separados
+// por comas. Sin configurar, CORS admite cualquier origen.
+func allowedOrigins() []string {
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// CheckOrigin indica si se acepta una conexión WebSocket según su Origin. Los
+// navegadores no aplican CORS a WebSocket, así que sin CORS_ALLOWED_ORIGINS
+// solo se acepta el mismo origen. Los clientes que no son navegadores no
+// envían Origin y siempre se aceptan.
+func CheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range allowedOrigins() {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// AllowOrigin devuelve el valor de Access-Control-Allow-Origin para la solicitud
+func AllowOrigin(r *http.Request) string {
+	origins := allowedOrigins()
+	if len(origins) == 0 {
+		return "*"
+	}
+	origin := r.Header.Get("Origin")
+	for _, allowed := range origins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return allowed
+		}
+	}
+	return ""
+}
+
 // CORSMiddleware maneja los encabezados CORS para todas las solicitudes
 func CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -12,12 +60,16 @@ func CORSMiddleware(next http.Handler) http.Handler {
 		logger.InfoLogger.Printf("CORS Middleware: Received %s request to %s", r.Method, r.URL.Path)
 		
 		// Set CORS headers
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+		if origin := AllowOrigin(r); origin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		w.Header().Add("Vary", "Origin")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PROPFIND, REPORT")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, Idempotency-Key, Last-Event-ID")
 		
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
+		// Handle preflight requests; other OPTIONS requests, such as CalDAV
+		// discovery, reach the router
+		if r.Method == "OPTIONS" && r.Header.Get("Origin") != "" && r.Header.Get("Access-Control-Request-Method") != "" {
 			logger.InfoLogger.Printf("Handling OPTIONS preflight request for %s", r.URL.Path)
 			w.Header().Set("Access-Control-Max-Age", "3600")
 			w.WriteHeader(http.StatusOK)
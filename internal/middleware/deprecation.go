@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Deprecated marca como obsoletas las rutas con el prefijo indicado.
+// Agrega los encabezados Deprecation (RFC 9745) con la fecha desde la que
+// son obsoletas, Sunset (RFC 8594) con la fecha en que dejarán de existir y
+// Link con la ruta equivalente bajo successor.
+func Deprecated(prefix, successor string, since, sunset time.Time) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "@"+strconv.FormatInt(since.Unix(), 10))
+			w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				w.Header().Set("Link", "<"+successor+strings.TrimPrefix(r.URL.Path, prefix)+`>; rel="successor-version"`)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCUnaryAuth es el equivalente gRPC de OptionalAuthMiddleware: agrega la
+// identidad al contexto cuando los metadatos incluyen "authorization" y
+// rechaza los tokens inválidos, pero permite las llamadas anónimas
+func GRPCUnaryAuth(sessions auth.SessionStore) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := grpcIdentity(ctx, sessions)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// GRPCStreamAuth aplica la misma autenticación a las llamadas con streaming
+func GRPCStreamAuth(sessions auth.SessionStore) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := grpcIdentity(stream.Context(), sessions)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &identityStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// identityStream sustituye el contexto del stream por uno con la identidad
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
+
+// grpcIdentity valida el token de los metadatos, si lo hay
+func grpcIdentity(ctx context.Context, sessions auth.SessionStore) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ctx, nil
+	}
+
+	tokenParts := strings.Split(values[0], " ")
+	if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+		return nil, status.Error(codes.Unauthenticated, "Formato de autorización inválido")
+	}
+	identity, err := sessions.Authenticate(ctx, tokenParts[1])
+	if err != nil && err != auth.ErrInvalidToken {
+		return nil, status.Error(codes.Internal, "Error al validar el token de acceso")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "Token inválido o expirado")
+	}
+	return auth.WithIdentity(ctx, identity), nil
+}
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/idempotency"
+	"github.com/claudio/todo-api/internal/logger"
+)
+
+const (
+	// maxIdempotencyKeyLength es la longitud máxima aceptada para Idempotency-Key
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBody es el tamaño máximo del cuerpo que se lee para calcular
+	// la huella; coincide con el mayor límite de las rutas, el de /import
+	maxIdempotentBody = 10 << 20
+)
+
+// replayedHeaders son los encabezados de la respuesta original que se
+// guardan para repetirla
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+// IdempotencyMiddleware guarda la respuesta de las solicitudes POST y PATCH
+// que incluyen el encabezado Idempotency-Key. Un reintento con la misma clave
+// y el mismo contenido recibe la respuesta guardada sin volver a ejecutarse;
+// si el contenido es distinto se responde 422. Las claves son propias de
+// cada usuario autenticado; las solicitudes anónimas se procesan siempre,
+// porque no hay forma de saber si el reintento viene del mismo cliente. Las
+// claves expiran después de ttl.
+func IdempotencyMiddleware(store idempotency.Store, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			identity, authenticated := auth.FromContext(r.Context())
+			if key == "" || !authenticated || (r.Method != http.MethodPost && r.Method != http.MethodPatch) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				http.Error(w, "Idempotency-Key es demasiado larga", http.StatusBadRequest)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBody))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "La solicitud es demasiado grande", http.StatusRequestEntityTooLarge)
+				return
+			}
+			if err != nil {
+				http.Error(w, "Error al leer la solicitud", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			// La clave se asocia al usuario para que otro no pueda leer su respuesta
+			storeKey := "user:" + identity.UserID + "|" + key
+			record, reserved, err := store.Reserve(r.Context(), storeKey, requestFingerprint(r, body), ttl)
+			if err != nil {
+				// Si el almacén falla se procesa la solicitud sin protección
+				logger.ErrorLogger.Printf("Error en el almacén de idempotencia: %v", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !reserved {
+				replayIdempotent(w, r, record, body)
+				return
+			}
+
+			// Si el handler entra en pánico se libera la clave para que el
+			// cliente pueda reintentar en lugar de recibir 409 hasta que expire
+			defer func() {
+				if p := recover(); p != nil {
+					if err := store.Release(r.Context(), storeKey); err != nil {
+						logger.ErrorLogger.Printf("Error al liberar la clave de idempotencia: %v", err)
+					}
+					panic(p)
+				}
+			}()
+
+			capture := &captureWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(capture, r)
+
+			// Los errores del servidor no se guardan para que el cliente pueda reintentar
+			if capture.status >= http.StatusInternalServerError {
+				if err := store.Release(r.Context(), storeKey); err != nil {
+					logger.ErrorLogger.Printf("Error al liberar la clave de idempotencia: %v", err)
+				}
+				return
+			}
+
+			header := http.Header{}
+			for _, name := range replayedHeaders {
+				if value := capture.Header().Get(name); value != "" {
+					header.Set(name, value)
+				}
+			}
+			if err := store.Complete(r.Context(), storeKey, capture.status, header, capture.body.Bytes()); err != nil {
+				logger.ErrorLogger.Printf("Error al guardar la respuesta idempotente: %v", err)
+			}
+		})
+	}
+}
+
+// replayIdempotent responde a un reintento con una clave ya utilizada
+func replayIdempotent(w http.ResponseWriter, r *http.Request, record idempotency.Record, body []byte) {
+	if record.Fingerprint != requestFingerprint(r, body) {
+		http.Error(w, "La clave de idempotencia ya se usó con otra solicitud", http.StatusUnprocessableEntity)
+		return
+	}
+	if !record.Completed {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "Hay una solicitud en curso con esta clave de idempotencia", http.StatusConflict)
+		return
+	}
+
+	for name, values := range record.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
+
+// requestFingerprint identifica el contenido de la solicitud: método, ruta y cuerpo
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+" "+r.URL.RequestURI()+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// captureWriter copia la respuesta mientras se envía al cliente
+type captureWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *captureWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
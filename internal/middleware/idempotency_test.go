@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/idempotency"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	calls := 0
+	handler := IdempotencyMiddleware(idempotency.NewMemoryStore(), time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": ` + strconv.Itoa(calls) + `}`))
+	}))
+
+	do := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/tasks", bytes.NewBufferString(body))
+		req.RemoteAddr = "10.0.0.1:1234"
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		req = req.WithContext(auth.WithIdentity(req.Context(), auth.Identity{UserID: "ana"}))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	first := do("abc", `{"title": "Tarea"}`)
+	if first.Code != http.StatusCreated || calls != 1 {
+		t.Fatalf("Primera solicitud incorrecta: %v, %d llamadas", first.Code, calls)
+	}
+
+	// Un reintento con la misma clave repite la respuesta sin ejecutar el handler
+	replay := do("abc", `{"title": "Tarea"}`)
+	if replay.Code != http.StatusCreated || replay.Body.String() != first.Body.String() || calls != 1 {
+		t.Errorf("Reintento incorrecto: %v %q, %d llamadas", replay.Code, replay.Body.String(), calls)
+	}
+	if replay.Header().Get("Idempotent-Replayed") != "true" || replay.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Encabezados incorrectos: %v", replay.Header())
+	}
+
+	// La misma clave con otro contenido se rechaza
+	if rr := do("abc", `{"title": "Otra"}`); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Obtuvo %v, esperaba %v", rr.Code, http.StatusUnprocessableEntity)
+	}
+
+	// Sin clave la solicitud se procesa siempre
+	do("", `{"title": "Tarea"}`)
+	if calls != 2 {
+		t.Errorf("Se esperaban 2 llamadas, obtuvo %d", calls)
+	}
+
+	// Un cuerpo mayor que el límite se rechaza sin ejecutar el handler
+	if rr := do("grande", strings.Repeat("x", maxIdempotentBody+1)); rr.Code != http.StatusRequestEntityTooLarge || calls != 2 {
+		t.Errorf("Obtuvo %v, esperaba %v", rr.Code, http.StatusRequestEntityTooLarge)
+	}
+
+	// Las solicitudes anónimas no se guardan: cada una se procesa
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/api/tasks", bytes.NewBufferString(`{"title": "Tarea"}`))
+		req.Header.Set("Idempotency-Key", "anon")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Header().Get("Idempotent-Replayed") != "" {
+			t.Error("Se repitió la respuesta de una solicitud anónima")
+		}
+	}
+	if calls != 4 {
+		t.Errorf("Se esperaban 4 llamadas, obtuvo %d", calls)
+	}
+}
+
+func TestIdempotencyKeysArePerUserAndReleasedOnPanic(t *testing.T) {
+	calls := 0
+	handler := IdempotencyMiddleware(idempotency.NewMemoryStore(), time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			panic("fallo inesperado")
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	do := func(user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/tasks", bytes.NewBufferString(`{"title": "Tarea"}`))
+		// Ambos usuarios comparten la dirección, como detrás de un NAT
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("Idempotency-Key", "abc")
+		req = req.WithContext(auth.WithIdentity(req.Context(), auth.Identity{UserID: user}))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// El pánico libera la clave: el reintento se ejecuta en lugar de recibir 409
+	func() {
+		defer func() { recover() }()
+		do("ana")
+	}()
+	if rr := do("ana"); rr.Code != http.StatusCreated || calls != 2 {
+		t.Fatalf("Reintento tras pánico incorrecto: %v, %d llamadas", rr.Code, calls)
+	}
+
+	// Otro usuario con la misma clave y la misma IP no recibe la respuesta de ana
+	if rr := do("luis"); rr.Header().Get("Idempotent-Replayed") != "" || calls != 3 {
+		t.Errorf("La clave no está aislada por usuario: %v, %d llamadas", rr.Header(), calls)
+	}
+}
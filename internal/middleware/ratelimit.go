@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/logger"
+)
+
+// RateLimit describe un límite de tipo token bucket: Requests solicitudes
+// cada Per, con ráfagas de hasta Burst solicitudes
+type RateLimit struct {
+	Name     string
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+// rate devuelve la cantidad de tokens que se recuperan por segundo
+func (l RateLimit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// RateLimitResult es el resultado de consumir un token del bucket
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimitStore guarda el estado de los buckets. La implementación en memoria
+// sirve para una sola instancia; un backend compartido (por ejemplo Redis)
+// puede implementar esta interfaz para varias instancias.
+type RateLimitStore interface {
+	Take(key string, limit RateLimit) (RateLimitResult, error)
+}
+
+// bucket guarda los tokens disponibles de un cliente
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryRateLimitStore implementa RateLimitStore en memoria
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryRateLimitStore crea un almacén de buckets en memoria
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Take consume un token del bucket identificado por key
+func (s *MemoryRateLimitStore) Take(key string, limit RateLimit) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	rate := limit.rate()
+	burst := float64(limit.Burst)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+
+	// Recargar los tokens según el tiempo transcurrido
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	result := RateLimitResult{Limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = secondsToDuration((burst - b.tokens) / rate)
+
+	s.sweep(now)
+	return result, nil
+}
+
+// sweep elimina una vez por minuto los buckets que ya se recargaron por completo
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.last) > time.Hour {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// RateLimitMiddleware limita las solicitudes de cada cliente. El cliente se
+// identifica por una clave de API válida, por el usuario autenticado o, si no
+// hay ninguno, por la dirección IP. keys puede ser nil.
+func RateLimitMiddleware(store RateLimitStore, limit RateLimit, keys *auth.APIKeyStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, err := store.Take(limit.Name+":"+rateLimitKey(r, keys), limit)
+			if err != nil {
+				// Si el backend falla se permite la solicitud
+				logger.ErrorLogger.Printf("Error en el limitador de solicitudes: %v", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				http.Error(w, "Demasiadas solicitudes", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey determina la clave del cliente para el limitador. Solo se usan
+// identidades ya verificadas: X-API-Key cuenta únicamente si la clave es
+// válida, porque un valor que el cliente puede cambiar a voluntad
+// permitiría saltarse el límite.
+func rateLimitKey(r *http.Request, keys *auth.APIKeyStore) string {
+	if keys != nil {
+		if client, ok := keys.Lookup(r.Header.Get("X-API-Key")); ok {
+			return "key:" + client
+		}
+	}
+	if identity, ok := auth.FromContext(r.Context()); ok {
+		return "user:" + identity.UserID
+	}
+	return "ip:" + ClientIP(r)
+}
+
+// ClientIP obtiene la dirección IP del cliente a partir de RemoteAddr
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// secondsToDuration convierte segundos fraccionarios en una duración
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// ceilSeconds redondea una duración hacia arriba a segundos enteros
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
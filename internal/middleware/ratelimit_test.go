@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/claudio/todo-api/internal/auth"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	limit := RateLimit{Name: "test", Requests: 1, Per: time.Second, Burst: 2}
+	handler := RateLimitMiddleware(store, limit, auth.NewAPIKeyStoreFrom("script:s3cret"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	apiKey := 0
+	do := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/tasks", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		// Cambiar X-API-Key en cada solicitud no debe saltarse el límite
+		apiKey++
+		req.Header.Set("X-API-Key", strconv.Itoa(apiKey))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// La ráfaga permite dos solicitudes seguidas
+	for i := 0; i < 2; i++ {
+		if rr := do(); rr.Code != http.StatusOK {
+			t.Fatalf("Solicitud %d: obtuvo %v, esperaba %v", i, rr.Code, http.StatusOK)
+		}
+	}
+
+	// La tercera debe ser rechazada con Retry-After
+	rr := do()
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("Obtuvo %v, esperaba %v", rr.Code, http.StatusTooManyRequests)
+	}
+	if rr.Header().Get("Retry-After") != "1" {
+		t.Errorf("Retry-After incorrecto: %q", rr.Header().Get("Retry-After"))
+	}
+	if rr.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("RateLimit-Remaining incorrecto: %q", rr.Header().Get("RateLimit-Remaining"))
+	}
+
+	// Después de un segundo se recupera un token
+	now = now.Add(time.Second)
+	if rr := do(); rr.Code != http.StatusOK {
+		t.Errorf("Obtuvo %v, esperaba %v", rr.Code, http.StatusOK)
+	}
+}
+
+func TestRateLimitKeysOnValidAPIKey(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	limit := RateLimit{Name: "test", Requests: 1, Per: time.Minute, Burst: 1}
+	handler := RateLimitMiddleware(store, limit, auth.NewAPIKeyStoreFrom("script:s3cret"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	do := func(apiKey string) int {
+		req := httptest.NewRequest("GET", "/api/tasks", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	// La IP agota su límite, pero la clave válida tiene su propio bucket
+	if code := do(""); code != http.StatusOK {
+		t.Fatalf("Obtuvo %v, esperaba %v", code, http.StatusOK)
+	}
+	if code := do("otra"); code != http.StatusTooManyRequests {
+		t.Errorf("Una clave inválida debe contar como la IP: obtuvo %v", code)
+	}
+	if code := do("s3cret"); code != http.StatusOK {
+		t.Errorf("Obtuvo %v, esperaba %v", code, http.StatusOK)
+	}
+	if code := do("s3cret"); code != http.StatusTooManyRequests {
+		t.Errorf("Obtuvo %v, esperaba %v", code, http.StatusTooManyRequests)
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// AuditRecord es un registro inmutable de una modificación sobre una tarea
+type AuditRecord struct {
+	ID        int64         `json:"id"`
+	Actor     string        `json:"actor"`
+	Action    string        `json:"action"`
+	TaskID    int           `json:"task_id"`
+	Before    *Task         `json:"before,omitempty"`
+	After     *Task         `json:"after,omitempty"`
+	Changes   []FieldChange `json:"changes"`
+	RequestID string        `json:"request_id"`
+	IP        string        `json:"ip"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// Acciones registradas en la auditoría y en el historial
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	ActionPurge   = "purge"
+)
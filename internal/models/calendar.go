@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// CalendarFeed es la suscripción iCalendar de un usuario. El token solo se
+// muestra al crearla; URL es la dirección secreta que se agrega al cliente
+// de calendario.
+type CalendarFeed struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
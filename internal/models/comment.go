@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Comment es un comentario sobre una tarea
+type Comment struct {
+	ID        int64     `json:"id"`
+	TaskID    int       `json:"task_id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// FieldChange describe el cambio de un campo entre dos versiones de una tarea
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// derivedFields son los campos que el servidor calcula a partir de los
+// demás y no se informan como cambios
+var derivedFields = map[string]bool{"updated_at": true, "seq": true, "completed_at": true}
+
+// DiffTasks compara dos versiones de una tarea campo por campo usando sus
+// nombres JSON. Cualquiera de las dos puede ser nil (creación o eliminación).
+func DiffTasks(before, after *Task) []FieldChange {
+	from := taskFields(before)
+	to := taskFields(after)
+
+	fields := map[string]bool{}
+	for field := range from {
+		fields[field] = true
+	}
+	for field := range to {
+		fields[field] = true
+	}
+
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	changes := []FieldChange{}
+	for _, field := range names {
+		if derivedFields[field] || reflect.DeepEqual(from[field], to[field]) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: field, From: from[field], To: to[field]})
+	}
+	return changes
+}
+
+// taskFields convierte una tarea en un mapa con sus campos JSON
+func taskFields(task *Task) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if task == nil {
+		return fields
+	}
+
+	data, _ := json.Marshal(task)
+	json.Unmarshal(data, &fields)
+	return fields
+}
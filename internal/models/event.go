@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// Tipos de eventos publicados cuando cambia una tarea
+const (
+	EventTaskCreated = "task.created"
+	EventTaskUpdated = "task.updated"
+	EventTaskDeleted = "task.deleted"
+)
+
+// TaskEvent describe un cambio sobre una tarea publicado en el bus de eventos
+type TaskEvent struct {
+	ID         int64     `json:"id"`
+	Type       string    `json:"type"`
+	TaskID     int       `json:"task_id"`
+	Task       *Task     `json:"task,omitempty"`
+	Actor      string    `json:"actor"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// VisibleTo indica si el usuario puede ver el evento: cada usuario ve sus
+// tareas y las tareas sin propietario
+func (e TaskEvent) VisibleTo(userID string) bool {
+	if e.Task == nil {
+		return false
+	}
+	return e.Task.VisibleTo(userID)
+}
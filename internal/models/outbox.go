@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// OutboxMessage es un evento guardado en la misma transacción que el cambio
+// de la tarea, pendiente de ser publicado por el relay
+type OutboxMessage struct {
+	ID          int64      `json:"id"`
+	Event       TaskEvent  `json:"event"`
+	CreatedAt   time.Time  `json:"created_at"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// BurndownPoint es el estado de un proyecto al final de un día. Scope es el
+// total de tareas del proyecto, Remaining las pendientes e Ideal la línea
+// que va de las pendientes del primer día a cero el último día.
+type BurndownPoint struct {
+	Date      string  `json:"date"`
+	Scope     int     `json:"scope"`
+	Remaining int     `json:"remaining"`
+	Done      int     `json:"done"`
+	Ideal     float64 `json:"ideal"`
+}
+
+// FlowPoint cuenta las tareas del proyecto en cada estado al final de un día
+type FlowPoint struct {
+	Date string `json:"date"`
+	Open int    `json:"open"`
+	Done int    `json:"done"`
+}
+
+// ProjectReport es la respuesta de los informes de un proyecto
+type ProjectReport struct {
+	ProjectID int         `json:"project_id"`
+	From      string      `json:"from"`
+	To        string      `json:"to"`
+	Points    interface{} `json:"points"`
+	// GeneratedAt es el instante con el que se calculó el último día
+	GeneratedAt time.Time `json:"generated_at"`
+}
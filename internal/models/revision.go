@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// TaskRevision es una versión guardada de una tarea después de un cambio
+type TaskRevision struct {
+	TaskID    int           `json:"task_id"`
+	Revision  int           `json:"revision"`
+	Action    string        `json:"action"`
+	Task      Task          `json:"task"`
+	Changes   []FieldChange `json:"changes"`
+	Actor     string        `json:"actor"`
+	CreatedAt time.Time     `json:"created_at"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// SearchHit es una tarea encontrada por la búsqueda de texto completo.
+// Highlights contiene, por campo, un fragmento con los términos encontrados
+// marcados entre <mark> y </mark>.
+type SearchHit struct {
+	Task       Task              `json:"task"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+)
+
+// Intervalos de las series de estadísticas
+const (
+	StatsDaily  = "day"
+	StatsWeekly = "week"
+)
+
+// StatsQuery indica el usuario y el rango de las series de estadísticas.
+// Las series cubren [From, To) en intervalos de Bucket, en UTC.
+type StatsQuery struct {
+	UserID string
+	Bucket string
+	From   time.Time
+	To     time.Time
+}
+
+// TaskStats resume las tareas visibles para un usuario, sin las de la papelera
+type TaskStats struct {
+	Total          int     `json:"total"`
+	Completed      int     `json:"completed"`
+	Pending        int     `json:"pending"`
+	CompletionRate float64 `json:"completion_rate"`
+	// Overdue cuenta las tareas pendientes cuya fecha de vencimiento ya pasó
+	Overdue int `json:"overdue"`
+	// AvgLeadTimeHours es el promedio de horas entre la creación y la
+	// finalización de las tareas completadas; nil si no hay ninguna
+	AvgLeadTimeHours *float64       `json:"avg_lead_time_hours"`
+	ByProject        []ProjectStats `json:"by_project"`
+	ByTag            []TagStats     `json:"by_tag"`
+	Bucket           string         `json:"bucket"`
+	Series           []StatsBucket  `json:"series"`
+}
+
+// ProjectStats cuenta las tareas de un proyecto; ProjectID 0 agrupa las
+// tareas sin proyecto
+type ProjectStats struct {
+	ProjectID int `json:"project_id"`
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+}
+
+// TagStats cuenta las tareas con una etiqueta; una tarea con varias
+// etiquetas cuenta en cada una
+type TagStats struct {
+	Tag       string `json:"tag"`
+	Total     int    `json:"total"`
+	Completed int    `json:"completed"`
+}
+
+// StatsBucket es un intervalo de la serie temporal. Created y Completed
+// cuentan las tareas creadas y completadas en el intervalo;
+// CompletionRate es la fracción de las creadas que ya están completadas.
+type StatsBucket struct {
+	Start          time.Time `json:"start"`
+	Created        int       `json:"created"`
+	Completed      int       `json:"completed"`
+	CompletionRate float64   `json:"completion_rate"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+)
+
+// Tipos de mutaciones aceptadas por la sincronización
+const (
+	SyncCreate = "create"
+	SyncUpdate = "update"
+	SyncDelete = "delete"
+)
+
+// Resultados posibles de una mutación sincronizada
+const (
+	SyncApplied  = "applied"
+	SyncConflict = "conflict"
+	SyncNotFound = "not_found"
+	SyncInvalid  = "invalid"
+)
+
+// Tombstone registra una tarea eliminada para que los clientes sin conexión
+// puedan borrar su copia local
+type Tombstone struct {
+	TaskID    int       `json:"id"`
+	Seq       int64     `json:"seq"`
+	DeletedAt time.Time `json:"deleted_at"`
+	OwnerID   string    `json:"-"`
+}
+
+// SyncChanges son los cambios ocurridos desde un token de sincronización
+type SyncChanges struct {
+	Created []Task      `json:"created"`
+	Updated []Task      `json:"updated"`
+	Deleted []Tombstone `json:"deleted"`
+	Token   string      `json:"token"`
+	HasMore bool        `json:"has_more"`
+}
+
+// SyncMutation es un cambio hecho por el cliente mientras estaba sin conexión.
+// BaseSeq es la secuencia de la tarea que conocía el cliente; si la tarea
+// cambió desde entonces la mutación se rechaza como conflicto.
+type SyncMutation struct {
+	ClientID string `json:"client_id"`
+	Op       string `json:"op"`
+	TaskID   int    `json:"task_id,omitempty"`
+	BaseSeq  int64  `json:"base_seq,omitempty"`
+	Task     *Task  `json:"task,omitempty"`
+}
+
+// SyncResult es el resultado de aplicar una mutación. En un conflicto Task
+// contiene la versión actual del servidor.
+type SyncResult struct {
+	ClientID string `json:"client_id"`
+	Status   string `json:"status"`
+	Task     *Task  `json:"task,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
@@ -1,15 +1,58 @@
 package models
 
 import (
+	"strings"
 	"time"
 )
 
 // Task representa una tarea en el sistema
 type Task struct {
-	ID          int       `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Completed   bool      `json:"completed"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-}
\ No newline at end of file
+	ID          int        `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Completed   bool       `json:"completed"`
+	OwnerID     string     `json:"owner_id,omitempty"`
+	ProjectID   int        `json:"project_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	// CompletedAt es el momento en que la tarea se marcó como completada
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	// DueDate es la fecha de vencimiento de la tarea
+	DueDate *time.Time `json:"due_date,omitempty"`
+	// Priority va de 1, la más alta, a 9, la más baja, como PRIORITY de
+	// iCalendar; 0 indica que la tarea no tiene prioridad
+	Priority int `json:"priority,omitempty"`
+	// Recurrence es una regla RRULE de iCalendar, como FREQ=WEEKLY;BYDAY=MO,
+	// que se repite a partir de DueDate
+	Recurrence string `json:"recurrence,omitempty"`
+	// Tags son las etiquetas de la tarea, en minúsculas y sin repetir
+	Tags []string `json:"tags,omitempty"`
+	// Seq es la secuencia del último cambio; aumenta con cada modificación
+	Seq int64 `json:"seq,omitempty"`
+	// CreatedSeq es la secuencia con la que se creó la tarea
+	CreatedSeq int64 `json:"-"`
+}
+
+// VisibleTo indica si el usuario puede ver la tarea: cada usuario ve sus
+// tareas y las tareas sin propietario
+func (t Task) VisibleTo(userID string) bool {
+	return t.OwnerID == "" || t.OwnerID == userID
+}
+
+// NormalizeTags pasa las etiquetas a minúsculas, quita los espacios de los
+// extremos y descarta las vacías y las repetidas, conservando el orden.
+// Devuelve nil si no queda ninguna.
+func NormalizeTags(tags []string) []string {
+	var normalized []string
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
@@ -0,0 +1,35 @@
+package models
+
+// Resultado de cada fila de una importación
+const (
+	// ImportCreated indica que la fila se creó como tarea nueva
+	ImportCreated = "created"
+	// ImportValid indica que la fila se crearía; solo en dry_run
+	ImportValid = "valid"
+	// ImportDuplicate indica que ya existe una tarea igual y la fila se omitió
+	ImportDuplicate = "duplicate"
+	// ImportError indica que la fila no es válida o no se pudo guardar
+	ImportError = "error"
+)
+
+// ImportRow es el resultado de importar una fila del archivo
+type ImportRow struct {
+	Line   int    `json:"line"`
+	Status string `json:"status"`
+	Title  string `json:"title,omitempty"`
+	// TaskID es la tarea creada o, en los duplicados, la tarea existente
+	TaskID int    `json:"task_id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportReport resume una importación fila por fila
+type ImportReport struct {
+	Format     string      `json:"format"`
+	DryRun     bool        `json:"dry_run"`
+	Total      int         `json:"total"`
+	Created    int         `json:"created"`
+	Valid      int         `json:"valid"`
+	Duplicates int         `json:"duplicates"`
+	Failed     int         `json:"failed"`
+	Rows       []ImportRow `json:"rows"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// View es una vista guardada: un filtro con orden y agrupación opcionales.
+// Filter usa el lenguaje de expresiones de ?filter=, Sort es un campo con un
+// "-" delante para orden descendente y GroupBy agrupa por un campo.
+type View struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Filter     string    `json:"filter"`
+	Sort       string    `json:"sort,omitempty"`
+	GroupBy    string    `json:"group_by,omitempty"`
+	OwnerID    string    `json:"owner_id,omitempty"`
+	SharedWith []string  `json:"shared_with"`
+	Builtin    bool      `json:"builtin,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// VisibleTo indica si el usuario puede usar la vista: el propietario, los
+// usuarios con los que se compartió y todos en las vistas predefinidas
+func (v View) VisibleTo(userID string) bool {
+	if v.Builtin || v.OwnerID == userID {
+		return true
+	}
+	for _, shared := range v.SharedWith {
+		if shared == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskGroup es un grupo de tareas con el mismo valor del campo de agrupación
+type TaskGroup struct {
+	Key   interface{} `json:"key"`
+	Tasks []Task      `json:"tasks"`
+}
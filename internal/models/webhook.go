@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook es una suscripción a eventos de tareas que se entregan por HTTP
+type Webhook struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	OwnerID   string    `json:"owner_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Estados de una entrega de webhook
+const (
+	DeliverySucceeded = "succeeded"
+	DeliveryRetrying  = "retrying"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery registra un intento de entrega de un evento a un webhook
+type WebhookDelivery struct {
+	ID          string    `json:"id"`
+	WebhookID   string    `json:"webhook_id"`
+	EventID     int64     `json:"event_id"`
+	EventType   string    `json:"event_type"`
+	Attempt     int       `json:"attempt"`
+	Status      string    `json:"status"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMS  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// DeadLetter es una entrega que agotó sus reintentos, con el contenido que
+// no se pudo entregar
+type DeadLetter struct {
+	DeliveryID string          `json:"delivery_id"`
+	WebhookID  string          `json:"webhook_id"`
+	EventID    int64           `json:"event_id"`
+	EventType  string          `json:"event_type"`
+	Attempts   int             `json:"attempts"`
+	LastError  string          `json:"last_error"`
+	Payload    json.RawMessage `json:"payload"`
+	FailedAt   time.Time       `json:"failed_at"`
+}
+
+// PendingDelivery es una entrega en cola. El siguiente intento se realiza a
+// partir de NextAttemptAt; la cola se guarda en el almacén para que los
+// reintentos pendientes sobrevivan a los reinicios.
+type PendingDelivery struct {
+	DeliveryID    string          `json:"delivery_id"`
+	WebhookID     string          `json:"webhook_id"`
+	EventID       int64           `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	Attempt       int             `json:"attempt"`
+	Payload       json.RawMessage `json:"payload"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+}
@@ -0,0 +1,48 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/claudio/todo-api/internal/logger"
+	"github.com/gorilla/mux"
+)
+
+//go:embed viewer.html
+var viewerHTML []byte
+
+// Handler sirve el documento OpenAPI y el visor. El documento se genera en
+// la primera solicitud, cuando el router ya tiene todas sus rutas.
+type Handler struct {
+	router *mux.Router
+	once   sync.Once
+	spec   []byte
+}
+
+// NewHandler crea el handler del documento de las rutas de r
+func NewHandler(r *mux.Router) *Handler {
+	return &Handler{router: r}
+}
+
+// Spec devuelve el documento OpenAPI en JSON
+func (h *Handler) Spec(w http.ResponseWriter, r *http.Request) {
+	h.once.Do(func() {
+		doc, missing := Build(h.router)
+		if len(missing) > 0 {
+			logger.ErrorLogger.Printf("Rutas sin documentar en OpenAPI: %s", strings.Join(missing, ", "))
+		}
+		h.spec, _ = json.MarshalIndent(doc, "", "  ")
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(h.spec)
+}
+
+// Viewer devuelve una página que muestra el documento con Swagger UI
+func (h *Handler) Viewer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(viewerHTML)
+}
@@ -0,0 +1,141 @@
+// Package openapi genera el documento OpenAPI 3.1 de la API recorriendo las
+// rutas registradas en el router. La descripción de cada operación está en
+// operations.go; los esquemas se obtienen de los tipos de internal/models.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Document es el documento OpenAPI. Paths agrupa las operaciones por ruta y
+// por método en minúsculas.
+type Document struct {
+	OpenAPI    string                       `json:"openapi"`
+	Info       Schema                       `json:"info"`
+	Servers    []Schema                     `json:"servers"`
+	Paths      map[string]map[string]Schema `json:"paths"`
+	Components Schema                       `json:"components"`
+}
+
+// Prefijos de las rutas: las de la versión actual y los alias obsoletos sin versión
+const (
+	versionPrefix = "/api/v1"
+	legacyPrefix  = "/api"
+)
+
+// pathParam reconoce las variables de mux con su patrón opcional: {id:[0-9]+}
+var pathParam = regexp.MustCompile(`\{([^}:]+)(?::([^}]+))?\}`)
+
+// Build recorre el router y describe cada ruta y método registrados bajo
+// /api. Las solicitudes OPTIONS (CORS) no se documentan y las rutas sin
+// versión se marcan como obsoletas. Devuelve también las rutas
+// registradas que no tienen descripción en operations.go, con el formato
+// "GET /api/tasks/{id}".
+func Build(r *mux.Router) (*Document, []string) {
+	registry := &schemaRegistry{components: map[string]Schema{}}
+	doc := &Document{
+		OpenAPI: "3.1.0",
+		Info: Schema{
+			"title":       "Todo API",
+			"version":     "1.0.0",
+			"description": "API REST para gestionar tareas. Los errores se devuelven como texto plano.",
+		},
+		Servers: []Schema{{"url": "/"}},
+		Paths:   map[string]map[string]Schema{},
+	}
+
+	// Los esquemas escritos a mano referencian estos tipos
+	for _, model := range referencedModels {
+		registry.schemaFor(model)
+	}
+
+	missing := []string{}
+	r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		// Solo se documenta la API; CalDAV usa métodos de WebDAV que
+		// OpenAPI no puede describir
+		if template != legacyPrefix && !strings.HasPrefix(template, legacyPrefix+"/") {
+			return nil
+		}
+
+		path, params := normalizePath(template)
+		// Las operaciones se describen por su ruta sin versión
+		legacy := !strings.HasPrefix(path, versionPrefix+"/")
+		key := path
+		if !legacy {
+			key = legacyPrefix + strings.TrimPrefix(path, versionPrefix)
+		}
+		for _, method := range methods {
+			if method == http.MethodOptions {
+				continue
+			}
+			op, ok := operations[method+" "+key]
+			if !ok {
+				missing = append(missing, method+" "+path)
+				continue
+			}
+			if op.id == "" {
+				op.id = handlerName(route.GetHandler())
+			}
+			if legacy {
+				op.id += "Legacy"
+				op.deprecated = true
+			}
+			if doc.Paths[path] == nil {
+				doc.Paths[path] = map[string]Schema{}
+			}
+			doc.Paths[path][strings.ToLower(method)] = op.build(registry, params)
+		}
+		return nil
+	})
+	sort.Strings(missing)
+
+	doc.Components = Schema{
+		"schemas":         registry.components,
+		"responses":       errorResponses,
+		"parameters":      Schema{"IdempotencyKey": idempotencyKeyParam},
+		"securitySchemes": Schema{"bearerAuth": Schema{"type": "http", "scheme": "bearer"}},
+	}
+	return doc, missing
+}
+
+// normalizePath quita los patrones de las variables de la ruta y devuelve
+// los parámetros de ruta en orden
+func normalizePath(template string) (string, []Schema) {
+	params := []Schema{}
+	for _, match := range pathParam.FindAllStringSubmatch(template, -1) {
+		schema := Schema{"type": "string"}
+		if match[2] == "[0-9]+" {
+			schema = Schema{"type": "integer"}
+		}
+		params = append(params, Schema{"name": match[1], "in": "path", "required": true, "schema": schema})
+	}
+	return pathParam.ReplaceAllString(template, "{$1}"), params
+}
+
+// handlerName obtiene el nombre del método que atiende la ruta, por ejemplo
+// GetTasks para (*TaskHandler).GetTasks; se usa como operationId
+func handlerName(handler http.Handler) string {
+	fn, ok := handler.(http.HandlerFunc)
+	if !ok {
+		return ""
+	}
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
+}
@@ -0,0 +1,498 @@
+package openapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/render"
+)
+
+// Autenticación requerida por una operación
+const (
+	// authNone no usa token
+	authNone = iota
+	// authOptional acepta token: sin él solo se ven las tareas sin propietario
+	authOptional
+	// authRequired responde 401 sin un token válido
+	authRequired
+)
+
+// operation describe una ruta. request y response son valores de ejemplo
+// cuyo tipo se convierte en esquema, o un Schema escrito a mano para los
+// cuerpos que no tienen un tipo exportado.
+type operation struct {
+	id       string
+	summary  string
+	tag      string
+	auth     int
+	query    []Schema
+	request  interface{}
+	status   int
+	response interface{}
+	// content es el tipo de la respuesta; por defecto application/json
+	content string
+	// requestContent es el tipo del cuerpo; por defecto application/json
+	requestContent string
+	// limited indica que la ruta tiene límite de solicitudes (429)
+	limited bool
+	// idempotent indica que la ruta acepta Idempotency-Key
+	idempotent bool
+	// deprecated marca los alias sin versión
+	deprecated bool
+	// negotiated indica que el cuerpo y la respuesta admiten todos los
+	// formatos de render según Content-Type y Accept
+	negotiated bool
+}
+
+// build convierte la descripción en un objeto Operation de OpenAPI
+func (op operation) build(registry *schemaRegistry, pathParams []Schema) Schema {
+	result := Schema{"summary": op.summary, "tags": []string{op.tag}}
+	if op.id != "" {
+		result["operationId"] = op.id
+	}
+	if op.deprecated {
+		result["deprecated"] = true
+	}
+
+	params := append([]Schema{}, pathParams...)
+	params = append(params, op.query...)
+	if op.idempotent {
+		params = append(params, Schema{"$ref": "#/components/parameters/IdempotencyKey"})
+	}
+	if len(params) > 0 {
+		result["parameters"] = params
+	}
+
+	if op.request != nil {
+		result["requestBody"] = Schema{
+			"required": true,
+			"content":  op.mediaTypes(op.requestType(), registry.schemaFor(op.request)),
+		}
+	}
+
+	status := op.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Schema{"description": http.StatusText(status)}
+	if op.response != nil {
+		content := op.content
+		if content == "" {
+			content = "application/json"
+		}
+		success["content"] = op.mediaTypes(content, registry.schemaFor(op.response))
+	}
+	responses := Schema{strconv.Itoa(status): success, "default": errorRef("Error")}
+	if op.request != nil || len(op.query) > 0 || len(pathParams) > 0 {
+		responses["400"] = errorRef("BadRequest")
+	}
+	if op.auth != authNone {
+		responses["401"] = errorRef("Unauthorized")
+	}
+	if len(pathParams) > 0 {
+		responses["404"] = errorRef("NotFound")
+	}
+	if op.negotiated {
+		responses["406"] = errorRef("NotAcceptable")
+		if op.request != nil {
+			responses["415"] = errorRef("UnsupportedMediaType")
+		}
+	}
+	if op.limited {
+		responses["429"] = errorRef("TooManyRequests")
+	}
+	result["responses"] = responses
+
+	switch op.auth {
+	case authNone:
+		result["security"] = []Schema{}
+	case authOptional:
+		result["security"] = []Schema{{}, {"bearerAuth": []string{}}}
+	default:
+		result["security"] = []Schema{{"bearerAuth": []string{}}}
+	}
+	return result
+}
+
+// requestType devuelve el tipo de contenido del cuerpo de la solicitud
+func (op operation) requestType() string {
+	if op.requestContent != "" {
+		return op.requestContent
+	}
+	return "application/json"
+}
+
+// mediaTypes asigna el esquema al tipo de contenido de la operación o, si la
+// operación negocia el formato, a todos los formatos de render
+func (op operation) mediaTypes(content string, schema Schema) Schema {
+	if !op.negotiated {
+		return Schema{content: Schema{"schema": schema}}
+	}
+	types := Schema{}
+	for _, format := range []string{render.JSON, render.CSV, render.YAML, render.MsgPack} {
+		types[format] = Schema{"schema": schema}
+	}
+	return types
+}
+
+func errorRef(name string) Schema {
+	return Schema{"$ref": "#/components/responses/" + name}
+}
+
+// errorResponses son las respuestas de error comunes; http.Error las envía
+// como texto plano
+var errorResponses = Schema{
+	"Error":                textError("Error inesperado"),
+	"BadRequest":           textError("Solicitud inválida"),
+	"Unauthorized":         textError("Token ausente, inválido o vencido"),
+	"NotFound":             textError("Recurso no encontrado"),
+	"TooManyRequests":      textError("Se superó el límite de solicitudes; ver Retry-After"),
+	"NotAcceptable":        textError("Ninguno de los formatos de Accept está disponible"),
+	"UnsupportedMediaType": textError("El Content-Type del cuerpo no está admitido"),
+}
+
+func textError(description string) Schema {
+	return Schema{
+		"description": description,
+		"content":     Schema{"text/plain": Schema{"schema": Schema{"type": "string"}}},
+	}
+}
+
+var idempotencyKeyParam = Schema{
+	"name":        "Idempotency-Key",
+	"in":          "header",
+	"description": "Clave para reintentar un POST o PATCH sin repetir su efecto; solo se aplica a solicitudes autenticadas",
+	"schema":      Schema{"type": "string", "maxLength": 255},
+}
+
+// queryParam describe un parámetro de consulta opcional
+func queryParam(name, typ, description string) Schema {
+	return Schema{"name": name, "in": "query", "description": description, "schema": Schema{"type": typ}}
+}
+
+// referencedModels son los tipos que los esquemas escritos a mano usan con $ref
+var referencedModels = []interface{}{
+	models.Task{}, models.SearchHit{}, models.SyncMutation{}, models.SyncResult{}, models.View{}, models.TaskGroup{},
+}
+
+// Esquemas de los cuerpos que se decodifican en tipos no exportados
+var (
+	credentialsSchema = object(Schema{"username": str, "password": str}, "username", "password")
+	refreshSchema     = object(Schema{"refresh_token": str}, "refresh_token")
+	bulkSchema        = object(Schema{
+		"mode": Schema{"type": "string", "enum": []string{"atomic", "best_effort"}},
+		"operations": Schema{"type": "array", "items": object(Schema{
+			"op":   Schema{"type": "string", "enum": []string{"create", "update", "delete", "complete", "uncomplete"}},
+			"id":   integer,
+			"task": Schema{"$ref": "#/components/schemas/Task"},
+		}, "op")},
+		"filter": Schema{"type": "string", "description": "Expresión de filtro, como en GET /api/tasks?filter="},
+		"action": Schema{"type": "string", "enum": []string{"complete", "uncomplete", "delete"}},
+	})
+	bulkResponseSchema = object(Schema{
+		"mode":      str,
+		"succeeded": integer,
+		"failed":    integer,
+		"results": Schema{"type": "array", "items": object(Schema{
+			"index":  integer,
+			"op":     str,
+			"id":     integer,
+			"status": integer,
+			"error":  str,
+			"task":   Schema{"$ref": "#/components/schemas/Task"},
+		}, "index", "op", "status")},
+	}, "mode", "succeeded", "failed", "results")
+	searchResponseSchema = object(Schema{
+		"query":   str,
+		"results": Schema{"type": "array", "items": Schema{"$ref": "#/components/schemas/SearchHit"}},
+	}, "query", "results")
+	syncPushSchema = object(Schema{
+		"mutations": Schema{"type": "array", "maxItems": 500, "items": Schema{"$ref": "#/components/schemas/SyncMutation"}},
+	}, "mutations")
+	syncResultsSchema = object(Schema{
+		"results": Schema{"type": "array", "items": Schema{"$ref": "#/components/schemas/SyncResult"}},
+	}, "results")
+	viewTasksSchema = object(Schema{
+		"view":   Schema{"$ref": "#/components/schemas/View"},
+		"tasks":  Schema{"type": "array", "items": Schema{"$ref": "#/components/schemas/Task"}},
+		"groups": Schema{"type": "array", "items": Schema{"$ref": "#/components/schemas/TaskGroup"}},
+	}, "view")
+	graphqlRequestSchema = object(Schema{
+		"query":         str,
+		"operationName": str,
+		"variables":     Schema{"type": "object"},
+	}, "query")
+	graphqlResponseSchema = object(Schema{
+		"data":   Schema{"type": "object"},
+		"errors": Schema{"type": "array", "items": object(Schema{"message": str}, "message")},
+	})
+	healthSchema = object(Schema{"status": str, "message": str}, "status", "message")
+
+	str     = Schema{"type": "string"}
+	integer = Schema{"type": "integer"}
+)
+
+// reportSchema describe ProjectReport con los puntos del tipo indicado
+func reportSchema(point interface{}) func(*schemaRegistry) Schema {
+	return func(registry *schemaRegistry) Schema {
+		return Schema{"allOf": []Schema{
+			registry.schemaFor(models.ProjectReport{}),
+			object(Schema{"points": Schema{"type": "array", "items": registry.schemaFor(point)}}),
+		}}
+	}
+}
+
+func object(properties Schema, required ...string) Schema {
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// Parámetros de consulta compartidos por varias rutas
+var (
+	fromDate = queryParam("from", "string", "Fecha inicial, 2006-01-02")
+	toDate   = queryParam("to", "string", "Fecha final, 2006-01-02")
+	csvParam = queryParam("format", "string", "csv para descargar el informe como CSV (también con Accept: text/csv)")
+)
+
+// operations describe cada ruta del router por método y ruta sin patrones
+// ni versión; cada entrada documenta la ruta bajo /api/v1 y su alias
+// obsoleto. El test del router falla si una ruta registrada no aparece aquí.
+var operations = map[string]operation{
+	"GET /api/health":       {summary: "Estado de la API", tag: "sistema", response: healthSchema},
+	"GET /api/openapi.json": {id: "GetOpenAPI", summary: "Este documento OpenAPI", tag: "sistema", response: Schema{"type": "object"}},
+	"GET /api/docs":         {id: "GetDocs", summary: "Visor interactivo del documento OpenAPI", tag: "sistema", response: str, content: "text/html"},
+
+	// Tareas
+	"GET /api/tasks": {
+		summary: "Lista las tareas", tag: "tareas", auth: authOptional, limited: true, negotiated: true,
+		query: []Schema{
+			queryParam("filter", "string", "Expresión de filtro, por ejemplo: completed AND project = 3"),
+			queryParam("include_deleted", "boolean", "Incluir las tareas en la papelera"),
+		},
+		response: []models.Task{},
+	},
+	"POST /api/tasks": {
+		summary: "Crea una tarea", tag: "tareas", auth: authOptional, limited: true, idempotent: true, negotiated: true,
+		request: models.Task{}, status: http.StatusCreated, response: models.Task{},
+	},
+	"GET /api/tasks/{id}": {
+		summary: "Obtiene una tarea", tag: "tareas", auth: authOptional, limited: true, negotiated: true,
+		query:    []Schema{queryParam("as_of", "string", "Instante RFC3339 para ver el estado histórico")},
+		response: models.Task{},
+	},
+	"PUT /api/tasks/{id}": {
+		summary: "Reemplaza una tarea", tag: "tareas", auth: authOptional, limited: true, negotiated: true,
+		request: models.Task{}, response: models.Task{},
+	},
+	"DELETE /api/tasks/{id}": {
+		summary: "Mueve una tarea a la papelera", tag: "tareas", auth: authOptional, limited: true,
+		status: http.StatusNoContent,
+	},
+	"GET /api/tasks/search": {
+		summary: "Búsqueda de texto completo", tag: "tareas", auth: authOptional, limited: true,
+		query: []Schema{
+			queryParam("q", "string", "Términos, frases entre comillas y prefijos terminados en *"),
+			queryParam("limit", "integer", "Máximo de resultados, hasta 100"),
+		},
+		response: searchResponseSchema,
+	},
+	"POST /api/tasks/bulk": {
+		summary: "Aplica un lote de operaciones", tag: "tareas", auth: authOptional, limited: true, idempotent: true,
+		request: bulkSchema, response: bulkResponseSchema,
+	},
+	"POST /api/graphql": {
+		summary: "Consultas y mutaciones GraphQL de tareas, comentarios y proyectos", tag: "tareas", auth: authOptional, limited: true, idempotent: true,
+		request: graphqlRequestSchema, response: graphqlResponseSchema,
+	},
+	"GET /api/export": {
+		summary: "Exporta las tareas visibles", tag: "importación", auth: authOptional, limited: true,
+		query: []Schema{
+			queryParam("format", "string", "jsonl (por defecto), csv o todotxt; también se elige con Accept"),
+			queryParam("filter", "string", "Expresión de filtro, como en GET /tasks"),
+		},
+		response: str, content: "application/x-ndjson",
+	},
+	"POST /api/import": {
+		summary: "Importa tareas desde JSON Lines, CSV o todo.txt", tag: "importación", auth: authOptional, limited: true, idempotent: true,
+		query: []Schema{
+			queryParam("format", "string", "jsonl, csv o todotxt; por defecto según Content-Type"),
+			queryParam("map", "string", "Mapeo de columnas a campos, por ejemplo Nombre:title,Hecho:completed"),
+			queryParam("dry_run", "boolean", "Validar sin crear tareas"),
+			queryParam("duplicates", "string", "skip (por defecto) omite las tareas con el mismo título y proyecto; allow las crea"),
+		},
+		request: str, requestContent: "text/csv", response: models.ImportReport{},
+	},
+	"GET /api/trash": {
+		summary: "Lista la papelera", tag: "papelera", auth: authOptional, limited: true,
+		response: []models.Task{},
+	},
+	"POST /api/tasks/{id}/restore": {
+		summary: "Saca una tarea de la papelera", tag: "papelera", auth: authOptional, limited: true, idempotent: true,
+		response: models.Task{},
+	},
+	"GET /api/tasks/{id}/history": {
+		summary: "Historial de revisiones de una tarea", tag: "historial", auth: authOptional, limited: true,
+		response: []models.TaskRevision{},
+	},
+	"POST /api/tasks/{id}/history/{revision}/restore": {
+		summary: "Restaura una revisión anterior", tag: "historial", auth: authOptional, limited: true, idempotent: true,
+		response: models.Task{},
+	},
+
+	// Estadísticas e informes
+	"GET /api/stats": {
+		summary: "Estadísticas de las tareas visibles", tag: "informes", auth: authOptional, limited: true,
+		query: []Schema{
+			queryParam("bucket", "string", "day o week"),
+			fromDate,
+			queryParam("to", "string", "Fecha final exclusiva, 2006-01-02"),
+		},
+		response: models.TaskStats{},
+	},
+	"GET /api/projects/{id}/reports/burndown": {
+		summary: "Burndown diario de un proyecto", tag: "informes", auth: authOptional, limited: true,
+		query:    []Schema{fromDate, toDate, csvParam},
+		response: reportSchema(models.BurndownPoint{}), content: "application/json",
+	},
+	"GET /api/projects/{id}/reports/cumulative-flow": {
+		summary: "Flujo acumulado diario de un proyecto", tag: "informes", auth: authOptional, limited: true,
+		query:    []Schema{fromDate, toDate, csvParam},
+		response: reportSchema(models.FlowPoint{}), content: "application/json",
+	},
+
+	// Autenticación
+	"POST /api/auth/login": {
+		summary: "Inicia sesión", tag: "autenticación", limited: true,
+		request: credentialsSchema, response: models.TokenPair{},
+	},
+	"POST /api/auth/refresh": {
+		summary: "Renueva el par de tokens", tag: "autenticación", limited: true,
+		request: refreshSchema, response: models.TokenPair{},
+	},
+	"POST /api/auth/logout": {
+		summary: "Cierra la sesión actual", tag: "autenticación", auth: authRequired, limited: true,
+		status: http.StatusNoContent,
+	},
+	"GET /api/auth/sessions": {
+		summary: "Sesiones activas del usuario", tag: "autenticación", auth: authRequired, limited: true,
+		response: []models.Session{},
+	},
+	"DELETE /api/auth/sessions/{id}": {
+		summary: "Revoca una sesión", tag: "autenticación", auth: authRequired, limited: true,
+		status: http.StatusNoContent,
+	},
+
+	// Sincronización y tiempo real
+	"GET /api/sync": {
+		summary: "Cambios desde un token de sincronización", tag: "sincronización", auth: authRequired, limited: true,
+		query: []Schema{
+			queryParam("since", "string", "Token devuelto por la llamada anterior"),
+			queryParam("limit", "integer", "Máximo de cambios, hasta 1000"),
+		},
+		response: models.SyncChanges{},
+	},
+	"POST /api/sync": {
+		summary: "Aplica mutaciones hechas sin conexión", tag: "sincronización", auth: authRequired, limited: true, idempotent: true,
+		request: syncPushSchema, response: syncResultsSchema,
+	},
+	"GET /api/events": {
+		id: "StreamEvents", summary: "Eventos de tareas por Server-Sent Events", tag: "tiempo real", auth: authRequired, limited: true,
+		query:    []Schema{queryParam("scope", "string", "mine para recibir solo los eventos propios")},
+		response: str, content: "text/event-stream",
+	},
+	"GET /api/ws": {
+		id: "ServeWebSocket", summary: "Conexión WebSocket; el token puede ir en access_token", tag: "tiempo real", auth: authRequired, limited: true,
+		query:  []Schema{queryParam("access_token", "string", "Token de acceso, para clientes que no envían encabezados")},
+		status: http.StatusSwitchingProtocols,
+	},
+
+	// Webhooks
+	"GET /api/webhooks": {
+		summary: "Webhooks del usuario", tag: "webhooks", auth: authRequired, limited: true,
+		response: []models.Webhook{},
+	},
+	"POST /api/webhooks": {
+		summary: "Registra un webhook", tag: "webhooks", auth: authRequired, limited: true,
+		request: models.Webhook{}, status: http.StatusCreated, response: models.Webhook{},
+	},
+	"DELETE /api/webhooks/{id}": {
+		summary: "Elimina un webhook", tag: "webhooks", auth: authRequired, limited: true,
+		status: http.StatusNoContent,
+	},
+	"GET /api/webhooks/{id}/deliveries": {
+		summary: "Entregas de un webhook", tag: "webhooks", auth: authRequired, limited: true,
+		response: []models.WebhookDelivery{},
+	},
+	"GET /api/webhooks/dead-letters": {
+		summary: "Entregas que agotaron los reintentos", tag: "webhooks", auth: authRequired, limited: true,
+		response: []models.DeadLetter{},
+	},
+	"POST /api/webhooks/dead-letters/{id}/retry": {
+		summary: "Reintenta una entrega fallida", tag: "webhooks", auth: authRequired, limited: true,
+		status: http.StatusAccepted,
+	},
+
+	// Auditoría
+	"GET /api/audit": {
+		summary: "Registro de auditoría de los cambios hechos por el usuario", tag: "auditoría", auth: authRequired, limited: true,
+		query: []Schema{
+			queryParam("actor", "string", "Usuario que hizo el cambio; solo se admite el propio"),
+			queryParam("action", "string", "create, update, delete, restore o purge"),
+			queryParam("task_id", "integer", "Tarea modificada"),
+			queryParam("after_id", "integer", "Paginación: registros posteriores a este ID"),
+			queryParam("from", "string", "Instante inicial RFC3339"),
+			queryParam("to", "string", "Instante final RFC3339"),
+			queryParam("limit", "integer", "Máximo de registros, hasta 1000"),
+		},
+		response: []models.AuditRecord{},
+	},
+	"GET /api/audit/export": {
+		summary: "Exporta en JSON Lines la auditoría de los cambios hechos por el usuario", tag: "auditoría", auth: authRequired, limited: true,
+		query:    []Schema{queryParam("actor", "string", "Usuario que hizo el cambio; solo se admite el propio"), queryParam("task_id", "integer", "Tarea modificada")},
+		response: models.AuditRecord{}, content: "application/x-ndjson",
+	},
+
+	// Suscripción iCalendar
+	"POST /api/calendar/feed": {
+		summary: "Genera la URL secreta del calendario y revoca la anterior", tag: "calendario", auth: authRequired,
+		status: http.StatusCreated, response: models.CalendarFeed{},
+	},
+	"DELETE /api/calendar/feed": {
+		summary: "Revoca la URL secreta del calendario", tag: "calendario", auth: authRequired,
+		status: http.StatusNoContent,
+	},
+	"GET /api/calendar/{token}.ics": {
+		summary: "Tareas como VTODO de iCalendar; admite If-None-Match", tag: "calendario", auth: authNone, limited: true,
+		response: str, content: "text/calendar",
+	},
+
+	// Vistas guardadas
+	"GET /api/views": {
+		summary: "Vistas predefinidas, propias y compartidas", tag: "vistas", auth: authRequired, limited: true,
+		response: []models.View{},
+	},
+	"POST /api/views": {
+		summary: "Guarda una vista", tag: "vistas", auth: authRequired, limited: true, idempotent: true,
+		request: models.View{}, status: http.StatusCreated, response: models.View{},
+	},
+	"GET /api/views/{id}": {
+		summary: "Obtiene una vista", tag: "vistas", auth: authRequired, limited: true,
+		response: models.View{},
+	},
+	"PUT /api/views/{id}": {
+		summary: "Reemplaza una vista propia", tag: "vistas", auth: authRequired, limited: true,
+		request: models.View{}, response: models.View{},
+	},
+	"DELETE /api/views/{id}": {
+		summary: "Elimina una vista propia", tag: "vistas", auth: authRequired, limited: true,
+		status: http.StatusNoContent,
+	},
+	"GET /api/views/{id}/tasks": {
+		summary: "Tareas de una vista, ordenadas y agrupadas", tag: "vistas", auth: authRequired, limited: true,
+		response: viewTasksSchema,
+	},
+}
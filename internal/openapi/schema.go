@@ -0,0 +1,124 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema es un objeto JSON Schema tal como se serializa en el documento
+type Schema map[string]interface{}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+// readOnlyFields son los campos que asigna el servidor. Se marcan readOnly
+// para que no sean obligatorios en los cuerpos de las solicitudes.
+var readOnlyFields = map[string]bool{
+	"id": true, "owner_id": true, "created_at": true, "updated_at": true,
+	"deleted_at": true, "completed_at": true, "seq": true, "builtin": true,
+}
+
+// schemaRegistry convierte tipos de Go en esquemas y guarda los structs con
+// nombre en components.schemas para referenciarlos
+type schemaRegistry struct {
+	components map[string]Schema
+}
+
+// schemaFor devuelve el esquema de un valor. Un Schema se usa tal cual;
+// cualquier otro valor se describe a partir de su tipo y sus etiquetas json.
+func (s *schemaRegistry) schemaFor(value interface{}) Schema {
+	switch v := value.(type) {
+	case Schema:
+		return v
+	case func(*schemaRegistry) Schema:
+		return v(s)
+	}
+	return s.schemaOf(reflect.TypeOf(value))
+}
+
+// schemaOf describe un tipo de Go
+func (s *schemaRegistry) schemaOf(t reflect.Type) Schema {
+	if t == nil || t == rawType {
+		return Schema{}
+	}
+	if t == timeType {
+		return Schema{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return Schema{"anyOf": []Schema{s.schemaOf(t.Elem()), {"type": "null"}}}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return Schema{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return Schema{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": s.schemaOf(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": s.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+		if _, ok := s.components[t.Name()]; !ok {
+			// Se reserva el nombre antes de recorrer los campos por si el
+			// tipo es recursivo
+			s.components[t.Name()] = Schema{}
+			s.components[t.Name()] = s.structSchema(t)
+		}
+		return Schema{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return Schema{}
+}
+
+// structSchema describe los campos exportados de un struct según sus
+// etiquetas json; los campos sin omitempty son obligatorios
+func (s *schemaRegistry) structSchema(t reflect.Type) Schema {
+	properties := Schema{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, options := field.Name, ""
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			parts := strings.SplitN(tag, ",", 2)
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			if len(parts) > 1 {
+				options = parts[1]
+			}
+		}
+
+		schema := s.schemaOf(field.Type)
+		if readOnlyFields[name] {
+			schema["readOnly"] = true
+		}
+		properties[name] = schema
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
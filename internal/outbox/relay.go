@@ -0,0 +1,174 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/claudio/todo-api/internal/logger"
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// Source es el origen de los mensajes pendientes; lo implementan los
+// almacenes de tareas
+type Source interface {
+	PendingOutbox(ctx context.Context, limit int) ([]models.OutboxMessage, error)
+	MarkPublished(ctx context.Context, ids ...int64) error
+}
+
+// waker lo implementan los orígenes que avisan cuando hay mensajes nuevos
+type waker interface {
+	Wake() <-chan struct{}
+}
+
+// pruner lo implementan los orígenes que conservan los mensajes publicados
+// y pueden eliminarlos pasada la retención
+type pruner interface {
+	PruneOutbox(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Relay lee el outbox en orden y publica cada mensaje en todos los sinks.
+// Cada sink tiene su propio cursor: si uno falla se reintenta en la
+// siguiente vuelta desde el mensaje fallido, sin saltarlo para conservar el
+// orden, mientras los demás siguen avanzando. Un mensaje solo se marca como
+// publicado cuando todos los sinks lo aceptaron.
+type Relay struct {
+	source    Source
+	sinks     []Sink
+	cursors   []int64
+	interval  time.Duration
+	batchSize int
+}
+
+// NewRelay crea un relay que consulta el outbox cada segundo
+func NewRelay(source Source, sinks ...Sink) *Relay {
+	return &Relay{
+		source:    source,
+		sinks:     sinks,
+		cursors:   make([]int64, len(sinks)),
+		interval:  time.Second,
+		batchSize: 100,
+	}
+}
+
+// Start ejecuta el relay en segundo plano hasta que se cancele el contexto
+func (r *Relay) Start(ctx context.Context) {
+	var wake <-chan struct{}
+	if w, ok := r.source.(waker); ok {
+		wake = w.Wake()
+	}
+
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			r.Flush(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-wake:
+			}
+		}
+	}()
+}
+
+// Flush publica los mensajes pendientes y devuelve cuántos quedaron
+// publicados en todos los sinks
+func (r *Relay) Flush(ctx context.Context) int {
+	published := 0
+	for {
+		messages, err := r.source.PendingOutbox(ctx, r.batchSize)
+		if err != nil {
+			logger.ErrorLogger.Printf("Error al leer el outbox: %v", err)
+			return published
+		}
+		if len(messages) == 0 {
+			return published
+		}
+
+		for i, sink := range r.sinks {
+			r.cursors[i] = r.publish(ctx, sink, r.cursors[i], messages)
+		}
+
+		// Solo se marcan los mensajes que ya aceptaron todos los sinks
+		ids := []int64{}
+		for _, msg := range messages {
+			if !r.deliveredToAll(msg.ID) {
+				break
+			}
+			ids = append(ids, msg.ID)
+		}
+		if len(ids) == 0 {
+			return published
+		}
+		if err := r.source.MarkPublished(ctx, ids...); err != nil {
+			logger.ErrorLogger.Printf("Error al marcar los mensajes del outbox: %v", err)
+			return published
+		}
+		published += len(ids)
+		if len(ids) < len(messages) {
+			return published
+		}
+	}
+}
+
+// publish entrega al sink en orden los mensajes posteriores a su cursor y
+// devuelve el nuevo cursor. Se detiene en el primer error.
+func (r *Relay) publish(ctx context.Context, sink Sink, cursor int64, messages []models.OutboxMessage) int64 {
+	for _, msg := range messages {
+		if msg.ID <= cursor {
+			continue
+		}
+		if err := sink.Publish(ctx, msg); err != nil {
+			logger.ErrorLogger.Printf("Error al publicar el mensaje %d del outbox: %v", msg.ID, err)
+			return cursor
+		}
+		cursor = msg.ID
+	}
+	return cursor
+}
+
+// deliveredToAll indica si todos los sinks aceptaron el mensaje
+func (r *Relay) deliveredToAll(id int64) bool {
+	for _, cursor := range r.cursors {
+		if cursor < id {
+			return false
+		}
+	}
+	return true
+}
+
+// Prune elimina los mensajes publicados antes de la retención si el origen
+// los conserva. Devuelve cuántos se eliminaron.
+func (r *Relay) Prune(ctx context.Context, retention time.Duration) int64 {
+	p, ok := r.source.(pruner)
+	if !ok {
+		return 0
+	}
+	pruned, err := p.PruneOutbox(ctx, time.Now().Add(-retention))
+	if err != nil {
+		logger.ErrorLogger.Printf("Error al depurar el outbox: %v", err)
+		return 0
+	}
+	return pruned
+}
+
+// StartPruner ejecuta Prune periódicamente hasta que se cancele el contexto
+func (r *Relay) StartPruner(ctx context.Context, retention, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if pruned := r.Prune(ctx, retention); pruned > 0 {
+					logger.InfoLogger.Printf("Outbox: %d mensajes publicados eliminados", pruned)
+				}
+			}
+		}
+	}()
+}
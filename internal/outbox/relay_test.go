@@ -0,0 +1,139 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/claudio/todo-api/internal/events"
+	"github.com/claudio/todo-api/internal/logger"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/store"
+)
+
+// flakySink falla mientras failures sea mayor que cero
+type flakySink struct {
+	failures int
+	received []int64
+}
+
+func (s *flakySink) Publish(ctx context.Context, msg models.OutboxMessage) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink no disponible")
+	}
+	s.received = append(s.received, msg.ID)
+	return nil
+}
+
+func TestRelayPublishesInOrderAndRetries(t *testing.T) {
+	logger.Init()
+	ctx := context.Background()
+	taskStore := store.NewMemoryTaskStore()
+
+	// Cada transacción guarda la tarea y su evento juntos
+	for _, title := range []string{"a", "b", "c"} {
+		err := taskStore.Tx(ctx, func(tx store.Tx) error {
+			task := models.Task{Title: title}
+			if err := tx.Insert(&task); err != nil {
+				return err
+			}
+			return tx.AddOutbox(models.TaskEvent{Type: models.EventTaskCreated, TaskID: task.ID, Task: &task})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Una transacción fallida no deja mensajes en el outbox
+	taskStore.Tx(ctx, func(tx store.Tx) error {
+		tx.AddOutbox(models.TaskEvent{Type: models.EventTaskCreated, TaskID: 99})
+		return errors.New("rollback")
+	})
+
+	sink := &flakySink{failures: 1}
+	relay := NewRelay(taskStore, sink)
+
+	// El primer intento falla y no se publica nada
+	if published := relay.Flush(ctx); published != 0 {
+		t.Fatalf("Se esperaban 0 mensajes publicados, obtuvo %d", published)
+	}
+
+	// El reintento publica los tres mensajes en orden
+	if published := relay.Flush(ctx); published != 3 {
+		t.Fatalf("Se esperaban 3 mensajes publicados, obtuvo %d", published)
+	}
+	for i, id := range sink.received {
+		if id != int64(i+1) {
+			t.Errorf("Orden incorrecto: %v", sink.received)
+			break
+		}
+	}
+
+	// No quedan mensajes pendientes
+	if published := relay.Flush(ctx); published != 0 {
+		t.Errorf("Se esperaban 0 mensajes pendientes, obtuvo %d", published)
+	}
+}
+
+func TestBusSinkIgnoresDuplicates(t *testing.T) {
+	bus := events.NewBus(10)
+	sub, _, _ := bus.Subscribe(0, nil)
+	sink := NewBusSink(bus)
+	msg := models.OutboxMessage{ID: 1, Event: models.TaskEvent{Type: models.EventTaskCreated, TaskID: 1}}
+
+	sink.Publish(context.Background(), msg)
+	sink.Publish(context.Background(), msg)
+
+	if len(sub.C) != 1 {
+		t.Errorf("Se esperaba 1 evento publicado, obtuvo %d", len(sub.C))
+	}
+}
+
+func TestRelayFailingSinkDoesNotBlockOthers(t *testing.T) {
+	logger.Init()
+	ctx := context.Background()
+	taskStore := store.NewMemoryTaskStore()
+
+	for _, title := range []string{"a", "b"} {
+		taskStore.Tx(ctx, func(tx store.Tx) error {
+			task := models.Task{Title: title}
+			if err := tx.Insert(&task); err != nil {
+				return err
+			}
+			return tx.AddOutbox(models.TaskEvent{Type: models.EventTaskCreated, TaskID: task.ID, Task: &task})
+		})
+	}
+
+	healthy := &flakySink{}
+	failing := &flakySink{failures: 1}
+	relay := NewRelay(taskStore, healthy, failing)
+
+	// El sink sano recibe los mensajes aunque el otro falle, y nada se marca
+	if published := relay.Flush(ctx); published != 0 {
+		t.Fatalf("Se esperaban 0 mensajes publicados, obtuvo %d", published)
+	}
+	if len(healthy.received) != 2 || len(failing.received) != 0 {
+		t.Fatalf("Entregas incorrectas: sano=%v fallido=%v", healthy.received, failing.received)
+	}
+
+	// El reintento solo entrega al sink fallido y marca ambos mensajes
+	if published := relay.Flush(ctx); published != 2 {
+		t.Fatalf("Se esperaban 2 mensajes publicados, obtuvo %d", published)
+	}
+	if len(healthy.received) != 2 || len(failing.received) != 2 {
+		t.Errorf("Entregas incorrectas: sano=%v fallido=%v", healthy.received, failing.received)
+	}
+}
+
+func TestBusSinkUsesMessageID(t *testing.T) {
+	bus := events.NewBus(10)
+	sub, _, _ := bus.Subscribe(0, nil)
+	sink := NewBusSink(bus)
+
+	sink.Publish(context.Background(), models.OutboxMessage{ID: 42, Event: models.TaskEvent{Type: models.EventTaskCreated, TaskID: 1}})
+
+	if event := <-sub.C; event.ID != 42 {
+		t.Errorf("Se esperaba el ID 42, obtuvo %d", event.ID)
+	}
+}
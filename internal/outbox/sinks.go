@@ -0,0 +1,110 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/claudio/todo-api/internal/events"
+	"github.com/claudio/todo-api/internal/logger"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/webhooks"
+)
+
+// Sink recibe los mensajes publicados por el relay. La entrega es al menos
+// una vez: un mismo mensaje puede llegar más de una vez y los consumidores
+// deben ignorar los IDs ya procesados.
+type Sink interface {
+	Publish(ctx context.Context, msg models.OutboxMessage) error
+}
+
+// BusSink publica los mensajes en el bus de eventos en proceso con el ID del
+// mensaje como ID del evento, de modo que Last-Event-ID en SSE y event_id en
+// los webhooks sobreviven a los reinicios. Como los mensajes llegan en orden,
+// basta con recordar el último ID publicado para descartar duplicados.
+type BusSink struct {
+	bus    *events.Bus
+	mu     sync.Mutex
+	lastID int64
+}
+
+// NewBusSink crea un sink que publica en el bus de eventos
+func NewBusSink(bus *events.Bus) *BusSink {
+	return &BusSink{bus: bus}
+}
+
+// Publish publica el evento salvo que el mensaje ya se haya publicado
+func (s *BusSink) Publish(ctx context.Context, msg models.OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if msg.ID <= s.lastID {
+		return nil
+	}
+	event := msg.Event
+	event.ID = msg.ID
+	s.bus.Publish(event)
+	s.lastID = msg.ID
+	return nil
+}
+
+// LogSink registra cada mensaje en el log de la aplicación
+type LogSink struct{}
+
+// Publish registra el mensaje
+func (LogSink) Publish(ctx context.Context, msg models.OutboxMessage) error {
+	logger.InfoLogger.Printf("Outbox %d: %s tarea %d por %s", msg.ID, msg.Event.Type, msg.Event.TaskID, msg.Event.Actor)
+	return nil
+}
+
+// HTTPSink envía cada mensaje a una URL fija, firmado como los webhooks.
+// El encabezado X-Outbox-Message-ID permite al receptor descartar duplicados.
+type HTTPSink struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewHTTPSink crea un sink que envía los mensajes por HTTP
+func NewHTTPSink(url, secret string) *HTTPSink {
+	return &HTTPSink{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Publish envía el mensaje; cualquier respuesta fuera de 2xx es un error
+func (s *HTTPSink) Publish(ctx context.Context, msg models.OutboxMessage) error {
+	body, err := json.Marshal(msg.Event)
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Outbox-Message-ID", strconv.FormatInt(msg.ID, 10))
+	req.Header.Set("X-Webhook-Event", msg.Event.Type)
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", webhooks.Sign(s.secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("el receptor respondió %d", resp.StatusCode)
+	}
+	return nil
+}
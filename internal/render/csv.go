@@ -0,0 +1,138 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// encodeCSV escribe un objeto o una lista de objetos como CSV. Las columnas
+// son los campos en el orden en que aparecen en JSON; los campos que solo
+// tienen algunas filas se agregan al final y quedan vacíos en las demás.
+func encodeCSV(value interface{}) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		items = []json.RawMessage{data}
+	}
+
+	rows := make([]orderedObject, len(items))
+	header := []string{}
+	seen := map[string]bool{}
+	for i, item := range items {
+		object, ok := parseObject(item)
+		if !ok {
+			return nil, ErrNotTabular
+		}
+		rows[i] = object
+		for _, key := range object.keys {
+			if !seen[key] {
+				seen[key] = true
+				header = append(header, key)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write(header)
+	for _, row := range rows {
+		record := make([]string, len(header))
+		for i, key := range header {
+			record[i] = csvCell(row.values[key])
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// csvCell convierte un valor JSON en el texto de una celda: las cadenas sin
+// comillas, null vacío y los objetos o listas como JSON
+func csvCell(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	return string(raw)
+}
+
+// decodeCSV interpreta una cabecera y una fila de valores. Cada columna se
+// convierte al tipo JSON del campo de schema con el mismo nombre; las
+// columnas desconocidas se conservan como texto para que el decodificador
+// las ignore o las rechace.
+func decodeCSV(body []byte, schema interface{}) ([]byte, error) {
+	records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) != 2 {
+		return nil, errors.New("el CSV debe tener una fila de cabecera y una fila de valores")
+	}
+
+	kinds := jsonKinds(schema)
+	object := map[string]interface{}{}
+	for i, name := range records[0] {
+		if i >= len(records[1]) || records[1][i] == "" {
+			continue
+		}
+		value := records[1][i]
+		switch kinds[name] {
+		case reflect.Bool:
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("la columna " + name + " debe ser true o false")
+			}
+			object[name] = parsed
+		case reflect.Int, reflect.Int64, reflect.Float64:
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, errors.New("la columna " + name + " debe ser un número")
+			}
+			object[name] = parsed
+		default:
+			object[name] = value
+		}
+	}
+	return json.Marshal(object)
+}
+
+// jsonKinds devuelve el tipo de cada campo de un struct según su nombre en JSON
+func jsonKinds(schema interface{}) map[string]reflect.Kind {
+	kinds := map[string]reflect.Kind{}
+	t := reflect.TypeOf(schema)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return kinds
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		kind := field.Type.Kind()
+		if kind == reflect.Ptr {
+			kind = field.Type.Elem().Kind()
+		}
+		kinds[name] = kind
+	}
+	return kinds
+}
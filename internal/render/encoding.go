@@ -0,0 +1,66 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// encodeYAML convierte el JSON del valor a YAML conservando el orden de los
+// campos. JSON es YAML válido, así que basta con quitar el estilo en línea y
+// las comillas del documento leído.
+func encodeYAML(value interface{}) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	blockStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+// blockStyle quita el estilo en línea de los nodos. Las cadenas sin comillas
+// que YAML interpretaría como otro tipo ("true", "12") conservan las comillas
+// al escribirse.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// decodeYAML convierte un documento YAML a JSON
+func decodeYAML(body []byte) ([]byte, error) {
+	var value interface{}
+	if err := yaml.Unmarshal(body, &value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// encodeMsgPack serializa el valor con los nombres de las etiquetas json
+func encodeMsgPack(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.SetCustomStructTag("json")
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeMsgPack convierte un cuerpo MessagePack a JSON. Las fechas en
+// formato timestamp de MessagePack se escriben como RFC 3339.
+func decodeMsgPack(body []byte) ([]byte, error) {
+	var value interface{}
+	if err := msgpack.Unmarshal(body, &value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
@@ -0,0 +1,207 @@
+// Package render elige el formato de las respuestas según el encabezado
+// Accept e interpreta los cuerpos según Content-Type. Todos los formatos usan
+// los nombres de las etiquetas json, de modo que CSV, YAML y MessagePack
+// tienen los mismos campos que JSON.
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Formatos admitidos
+const (
+	JSON    = "application/json"
+	CSV     = "text/csv"
+	YAML    = "application/yaml"
+	MsgPack = "application/msgpack"
+)
+
+// formats son los formatos en orden de preferencia cuando el cliente acepta
+// cualquiera
+var formats = []string{JSON, CSV, YAML, MsgPack}
+
+// aliases son otros nombres habituales de los mismos formatos
+var aliases = map[string]string{
+	"application/x-yaml":       YAML,
+	"text/yaml":                YAML,
+	"application/x-msgpack":    MsgPack,
+	"application/vnd.msgpack":  MsgPack,
+	"application/csv":          CSV,
+	"text/json":                JSON,
+	"application/problem+json": JSON,
+}
+
+var (
+	// ErrNotAcceptable indica que el cliente no acepta ningún formato admitido
+	ErrNotAcceptable = errors.New("Formato no aceptable: use application/json, text/csv, application/yaml o application/msgpack")
+	// ErrUnsupportedMediaType indica que el cuerpo está en un formato no admitido
+	ErrUnsupportedMediaType = errors.New("Tipo de contenido no admitido: use application/json, text/csv, application/yaml o application/msgpack")
+	// ErrNotTabular indica que el valor no se puede escribir como CSV
+	ErrNotTabular = errors.New("la respuesta no se puede representar como CSV")
+)
+
+// Negotiate devuelve el formato de la respuesta según el encabezado Accept,
+// respetando los valores q. Sin Accept se responde en JSON.
+func Negotiate(r *http.Request) (string, error) {
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return JSON, nil
+	}
+
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		format := match(mediaType)
+		if format != "" && q > bestQ {
+			best, bestQ = format, q
+		}
+	}
+	if best == "" {
+		return "", ErrNotAcceptable
+	}
+	return best, nil
+}
+
+// match devuelve el formato admitido que corresponde al tipo de Accept
+func match(mediaType string) string {
+	switch mediaType {
+	case "*/*", "application/*":
+		return JSON
+	case "text/*":
+		return CSV
+	}
+	if format, ok := aliases[mediaType]; ok {
+		return format
+	}
+	for _, format := range formats {
+		if format == mediaType {
+			return format
+		}
+	}
+	return ""
+}
+
+// Write escribe value en el formato indicado. El valor se serializa primero
+// como JSON para que todos los formatos usen los mismos nombres de campos.
+func Write(w http.ResponseWriter, format string, status int, value interface{}) error {
+	var body []byte
+	var err error
+	switch format {
+	case CSV:
+		body, err = encodeCSV(value)
+	case YAML:
+		body, err = encodeYAML(value)
+	case MsgPack:
+		body, err = encodeMsgPack(value)
+	default:
+		format = JSON
+		body, err = json.Marshal(value)
+		body = append(body, '\n')
+	}
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", contentType(format))
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(status)
+	_, err = w.Write(body)
+	return err
+}
+
+// contentType agrega el juego de caracteres a los formatos de texto
+func contentType(format string) string {
+	switch format {
+	case CSV, YAML:
+		return format + "; charset=utf-8"
+	}
+	return format
+}
+
+// ReadJSON lee el cuerpo de la solicitud y lo convierte a JSON según su
+// Content-Type; sin Content-Type se supone JSON. Los valores de CSV no tienen
+// tipo, así que se convierten con los tipos de los campos de schema, un
+// valor de ejemplo del struct que representa el cuerpo.
+func ReadJSON(r *http.Request, schema interface{}) ([]byte, error) {
+	format := JSON
+	if header := r.Header.Get("Content-Type"); header != "" {
+		mediaType, _, err := mime.ParseMediaType(header)
+		if err != nil {
+			return nil, ErrUnsupportedMediaType
+		}
+		format = match(mediaType)
+		if format == "" || strings.HasSuffix(mediaType, "*") {
+			return nil, ErrUnsupportedMediaType
+		}
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case CSV:
+		return decodeCSV(body, schema)
+	case YAML:
+		return decodeYAML(body)
+	case MsgPack:
+		return decodeMsgPack(body)
+	}
+	return body, nil
+}
+
+// orderedObject es un objeto JSON que conserva el orden de sus campos
+type orderedObject struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+// parseObject interpreta un objeto JSON conservando el orden de los campos
+func parseObject(data []byte) (orderedObject, bool) {
+	object := orderedObject{values: map[string]json.RawMessage{}}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return object, false
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return object, false
+		}
+		key, _ := token.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return object, false
+		}
+		object.keys = append(object.keys, key)
+		object.values[key] = value
+	}
+	return object, true
+}
+
+// sortedKeys devuelve las claves de un mapa ordenadas
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
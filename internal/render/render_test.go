@@ -0,0 +1,103 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		accept string
+		want   string
+		err    error
+	}{
+		{"", JSON, nil},
+		{"*/*", JSON, nil},
+		{"text/csv", CSV, nil},
+		{"application/json;q=0.5, application/x-yaml", YAML, nil},
+		{"text/html, application/msgpack;q=0.1", MsgPack, nil},
+		{"application/xml", "", ErrNotAcceptable},
+		{"text/csv;q=0", "", ErrNotAcceptable},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", c.accept)
+		got, err := Negotiate(req)
+		if got != c.want || err != c.err {
+			t.Errorf("Negotiate(%q) = %q, %v; esperaba %q, %v", c.accept, got, err, c.want, c.err)
+		}
+	}
+}
+
+func TestFormatsRoundTrip(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 10, 0, 0, 0, time.UTC)
+	tasks := []models.Task{
+		{ID: 1, Title: "Comprar, pan", Completed: true, CreatedAt: now, UpdatedAt: now, CompletedAt: &now},
+		{ID: 2, Title: "true", Description: "Texto \"citado\"", ProjectID: 3, CreatedAt: now, UpdatedAt: now},
+	}
+
+	// CSV: una columna por campo en el orden de JSON
+	rr := httptest.NewRecorder()
+	if err := Write(rr, CSV, http.StatusOK, tasks); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	if lines[0] != "id,title,description,completed,created_at,updated_at,completed_at,project_id" {
+		t.Errorf("Cabecera CSV incorrecta: %s", lines[0])
+	}
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], `1,"Comprar, pan",,true,`) {
+		t.Errorf("Filas CSV incorrectas: %q", lines)
+	}
+	if rr.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Errorf("Content-Type incorrecto: %s", rr.Header().Get("Content-Type"))
+	}
+	if err := Write(httptest.NewRecorder(), CSV, http.StatusOK, "texto"); err != ErrNotTabular {
+		t.Errorf("Se esperaba ErrNotTabular, obtuvo %v", err)
+	}
+
+	// YAML y MessagePack se leen de vuelta con los mismos campos
+	for _, format := range []string{YAML, MsgPack} {
+		rr := httptest.NewRecorder()
+		if err := Write(rr, format, http.StatusOK, tasks[1]); err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest("POST", "/", bytes.NewReader(rr.Body.Bytes()))
+		req.Header.Set("Content-Type", format)
+		body, err := ReadJSON(req, models.Task{})
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		var task models.Task
+		if err := json.Unmarshal(body, &task); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if task.Title != "true" || task.Description != tasks[1].Description || task.ProjectID != 3 || !task.CreatedAt.Equal(now) {
+			t.Errorf("%s: tarea incorrecta después de leerla: %+v", format, task)
+		}
+	}
+
+	// CSV de entrada con los tipos de los campos de la tarea
+	req := httptest.NewRequest("POST", "/", strings.NewReader("title,completed,project_id\nDesde CSV,true,4\n"))
+	req.Header.Set("Content-Type", "text/csv; charset=utf-8")
+	body, err := ReadJSON(req, models.Task{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var task models.Task
+	if err := json.Unmarshal(body, &task); err != nil || task.Title != "Desde CSV" || !task.Completed || task.ProjectID != 4 {
+		t.Errorf("Tarea CSV incorrecta: %+v %v", task, err)
+	}
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader("<task/>"))
+	req.Header.Set("Content-Type", "application/xml")
+	if _, err := ReadJSON(req, models.Task{}); err != ErrUnsupportedMediaType {
+		t.Errorf("Se esperaba ErrUnsupportedMediaType, obtuvo %v", err)
+	}
+}
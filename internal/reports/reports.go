@@ -0,0 +1,108 @@
+// Package reports reconstruye a partir del historial de revisiones el estado
+// diario de las tareas de un proyecto para los informes de burndown y de
+// flujo acumulado.
+package reports
+
+import (
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// Estados de una tarea en un día
+const (
+	statusAbsent = iota
+	statusOpen
+	statusDone
+)
+
+// Days devuelve los días de from a to, ambos incluidos, en UTC
+func Days(from, to time.Time) []time.Time {
+	days := []time.Time{}
+	for day := from.UTC(); !day.After(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// counts cuenta las tareas abiertas y terminadas del proyecto al final de
+// cada día. Las revisiones deben estar ordenadas por tarea y revisión.
+func counts(revisions []models.TaskRevision, projectID int, userID string, days []time.Time, now time.Time) (open, done []int) {
+	open = make([]int, len(days))
+	done = make([]int, len(days))
+
+	for start := 0; start < len(revisions); {
+		end := start
+		for end < len(revisions) && revisions[end].TaskID == revisions[start].TaskID {
+			end++
+		}
+		task := revisions[start:end]
+
+		// Los días están en orden, así que la revisión vigente solo avanza
+		current := -1
+		for i, day := range days {
+			cutoff := day.AddDate(0, 0, 1)
+			if cutoff.After(now) {
+				cutoff = now
+			}
+			for current+1 < len(task) && task[current+1].CreatedAt.Before(cutoff) {
+				current++
+			}
+			if current < 0 {
+				continue
+			}
+			switch status(task[current], projectID, userID) {
+			case statusOpen:
+				open[i]++
+			case statusDone:
+				done[i]++
+			}
+		}
+		start = end
+	}
+	return open, done
+}
+
+// status indica el estado de la tarea según una revisión
+func status(revision models.TaskRevision, projectID int, userID string) int {
+	task := revision.Task
+	if revision.Action == models.ActionPurge || task.DeletedAt != nil ||
+		task.ProjectID != projectID || !task.VisibleTo(userID) {
+		return statusAbsent
+	}
+	if task.Completed {
+		return statusDone
+	}
+	return statusOpen
+}
+
+// Burndown calcula las tareas pendientes del proyecto al final de cada día
+// y la línea ideal hasta el último día
+func Burndown(revisions []models.TaskRevision, projectID int, userID string, days []time.Time, now time.Time) []models.BurndownPoint {
+	open, done := counts(revisions, projectID, userID, days, now)
+
+	points := make([]models.BurndownPoint, len(days))
+	for i, day := range days {
+		points[i] = models.BurndownPoint{
+			Date:      day.Format("2006-01-02"),
+			Scope:     open[i] + done[i],
+			Remaining: open[i],
+			Done:      done[i],
+		}
+		if len(days) > 1 {
+			points[i].Ideal = float64(open[0]) * float64(len(days)-1-i) / float64(len(days)-1)
+		}
+	}
+	return points
+}
+
+// CumulativeFlow cuenta las tareas del proyecto en cada estado al final de cada día
+func CumulativeFlow(revisions []models.TaskRevision, projectID int, userID string, days []time.Time, now time.Time) []models.FlowPoint {
+	open, done := counts(revisions, projectID, userID, days, now)
+
+	points := make([]models.FlowPoint, len(days))
+	for i, day := range days {
+		points[i] = models.FlowPoint{Date: day.Format("2006-01-02"), Open: open[i], Done: done[i]}
+	}
+	return points
+}
@@ -0,0 +1,60 @@
+package reports
+
+import (
+	"testing"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+func TestBurndownAndCumulativeFlow(t *testing.T) {
+	day := func(d, hour int) time.Time {
+		return time.Date(2024, 3, d, hour, 0, 0, 0, time.UTC)
+	}
+	rev := func(taskID int, at time.Time, action string, task models.Task) models.TaskRevision {
+		task.ID = taskID
+		return models.TaskRevision{TaskID: taskID, Action: action, Task: task, CreatedAt: at}
+	}
+	deleted := day(3, 9)
+
+	revisions := []models.TaskRevision{
+		// Creada el día 1 y completada el día 2
+		rev(1, day(1, 9), models.ActionCreate, models.Task{ProjectID: 7}),
+		rev(1, day(2, 15), models.ActionUpdate, models.Task{ProjectID: 7, Completed: true}),
+		// Creada el día 1 y movida a otro proyecto el día 3
+		rev(2, day(1, 10), models.ActionCreate, models.Task{ProjectID: 7}),
+		rev(2, day(3, 8), models.ActionUpdate, models.Task{ProjectID: 8}),
+		// Creada el día 2 y eliminada el día 3
+		rev(3, day(2, 10), models.ActionCreate, models.Task{ProjectID: 7}),
+		rev(3, deleted, models.ActionDelete, models.Task{ProjectID: 7, DeletedAt: &deleted}),
+		// De otro usuario: no se cuenta
+		rev(4, day(1, 10), models.ActionCreate, models.Task{ProjectID: 7, OwnerID: "otro"}),
+	}
+
+	days := Days(day(1, 0), day(3, 0))
+	flow := CumulativeFlow(revisions, 7, "ana", days, day(10, 0))
+	expected := []models.FlowPoint{
+		{Date: "2024-03-01", Open: 2, Done: 0},
+		{Date: "2024-03-02", Open: 2, Done: 1},
+		{Date: "2024-03-03", Open: 0, Done: 1},
+	}
+	for i, point := range expected {
+		if flow[i] != point {
+			t.Errorf("Día %d: obtuvo %+v, esperaba %+v", i+1, flow[i], point)
+		}
+	}
+
+	burndown := Burndown(revisions, 7, "ana", days, day(10, 0))
+	if burndown[0].Ideal != 2 || burndown[1].Ideal != 1 || burndown[2].Ideal != 0 {
+		t.Errorf("Línea ideal incorrecta: %+v", burndown)
+	}
+	if burndown[1].Scope != 3 || burndown[1].Remaining != 2 {
+		t.Errorf("Burndown del día 2 incorrecto: %+v", burndown[1])
+	}
+
+	// Los cambios posteriores a now no se cuentan
+	partial := CumulativeFlow(revisions, 7, "ana", days, day(2, 12))
+	if partial[1].Open != 3 || partial[1].Done != 0 {
+		t.Errorf("Día en curso incorrecto: %+v", partial[1])
+	}
+}
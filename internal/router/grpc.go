@@ -0,0 +1,19 @@
+package router
+
+import (
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/middleware"
+	"github.com/claudio/todo-api/internal/taskspb"
+	"google.golang.org/grpc"
+)
+
+// newGRPCServer crea el servidor gRPC con el servicio de tareas. La
+// autenticación es opcional, como en las rutas REST de tareas.
+func newGRPCServer(sessions auth.SessionStore, tasks taskspb.TaskServiceServer) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(middleware.GRPCUnaryAuth(sessions)),
+		grpc.ChainStreamInterceptor(middleware.GRPCStreamAuth(sessions)),
+	)
+	taskspb.RegisterTaskServiceServer(server, tasks)
+	return server
+}
@@ -1,16 +1,47 @@
 package router
 
 import (
+	"context"
 	"database/sql"
+	"net/http"
+	"os"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/claudio/todo-api/internal/apiversion"
+	"github.com/claudio/todo-api/internal/audit"
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/caldav"
+	"github.com/claudio/todo-api/internal/comments"
+	"github.com/claudio/todo-api/internal/config"
+	"github.com/claudio/todo-api/internal/events"
+	"github.com/claudio/todo-api/internal/feeds"
 	"github.com/claudio/todo-api/internal/handlers"
+	"github.com/claudio/todo-api/internal/history"
+	"github.com/claudio/todo-api/internal/idempotency"
 	"github.com/claudio/todo-api/internal/middleware"
+	"github.com/claudio/todo-api/internal/openapi"
+	"github.com/claudio/todo-api/internal/outbox"
+	"github.com/claudio/todo-api/internal/store"
+	"github.com/claudio/todo-api/internal/views"
+	"github.com/claudio/todo-api/internal/webhooks"
 	"github.com/claudio/todo-api/internal/logger"
+	"google.golang.org/grpc"
 )
 
+// legacyDeprecatedSince es la fecha en que se publicó /api/v1 y las rutas
+// sin versión pasaron a ser obsoletas
+var legacyDeprecatedSince = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
 // NewRouter configura y devuelve un nuevo router
 func NewRouter(db *sql.DB) *mux.Router {
+	r, _ := NewServers(db)
+	return r
+}
+
+// NewServers configura el router HTTP y el servidor gRPC, que comparten los
+// almacenes, las sesiones y el bus de eventos
+func NewServers(db *sql.DB) (*mux.Router, *grpc.Server) {
 	logger.InfoLogger.Println("Configurando router...")
 	
 	r := mux.NewRouter()
@@ -20,31 +51,122 @@ func NewRouter(db *sql.DB) *mux.Router {
 	// Aplicar middleware de registro a todas las rutas
 	r.Use(middleware.Logger)
 
+	// Asignar un identificador a cada solicitud
+	r.Use(middleware.RequestID)
+
+	// Usar PostgreSQL para las tareas, la auditoría y el historial si hay
+	// conexión, o memoria en desarrollo
+	var taskStore store.TaskStore = handlers.NewExampleTaskStore()
+	var auditStore audit.Store = audit.NewMemoryStore()
+	var historyStore history.Store = history.NewMemoryStore()
+	var commentStore comments.Store = comments.NewMemoryStore()
+	var idempotencyStore idempotency.Store = idempotency.NewMemoryStore()
+	var viewStore views.Store = views.NewMemoryStore()
+	var feedStore feeds.Store = feeds.NewMemoryStore()
+	var caldavStore caldav.Store = caldav.NewMemoryStore()
+	var webhookStore webhooks.Store = webhooks.NewMemoryStore()
+	var sessions auth.SessionStore = auth.NewMemorySessionStore()
+	if db != nil {
+		taskStore = store.NewPostgresTaskStore(db)
+		auditStore = audit.NewPostgresStore(db)
+		historyStore = history.NewPostgresStore(db)
+		commentStore = comments.NewPostgresStore(db)
+		idempotencyStore = idempotency.NewPostgresStore(db)
+		viewStore = views.NewPostgresStore(db)
+		feedStore = feeds.NewPostgresStore(db)
+		caldavStore = caldav.NewPostgresStore(db)
+		webhookStore = webhooks.NewPostgresStore(db)
+		sessions = auth.NewPostgresSessionStore(db)
+	}
+
+	// Bus de eventos en proceso para notificar cambios de tareas
+	bus := events.NewBus(1000)
+
+	// Webhooks salientes con entregas firmadas. El dispatcher recibe los
+	// eventos del outbox y guarda las entregas pendientes en su almacén.
+	dispatcher := webhooks.NewDispatcher(webhookStore)
+	dispatcher.Start(context.Background())
+
+	// El relay publica los eventos guardados en el outbox tras cada commit
+	sinks := []outbox.Sink{outbox.NewBusSink(bus), outbox.LogSink{}, dispatcher}
+	if url := os.Getenv("OUTBOX_WEBHOOK_URL"); url != "" {
+		sinks = append(sinks, outbox.NewHTTPSink(url, os.Getenv("OUTBOX_WEBHOOK_SECRET")))
+	}
+	relay := outbox.NewRelay(taskStore, sinks...)
+	relay.Start(context.Background())
+	relay.StartPruner(context.Background(), config.Duration("OUTBOX_RETENTION", 7*24*time.Hour), time.Hour)
+
 	// Crear el manejador de tareas
-	taskHandler := handlers.NewTaskHandler()
+	taskHandler := handlers.NewTaskHandler(
+		handlers.WithTaskStore(taskStore),
+		handlers.WithAuditStore(auditStore),
+		handlers.WithHistoryStore(historyStore),
+		handlers.WithCommentStore(commentStore),
+	)
+	users := auth.NewUserStore()
+	// Limitador de solicitudes compartido por los grupos de rutas, por las
+	// dos versiones de cada ruta y por CalDAV
+	limiter := middleware.NewMemoryRateLimitStore()
+	// Los clientes automatizados con una clave de API válida tienen su
+	// propio límite, separado del de su IP
+	apiKeys := auth.NewAPIKeyStore()
 
-	// Endpoint de prueba
-	r.HandleFunc("/api/health", taskHandler.HealthCheck).Methods("GET")
+	// Eliminar periódicamente las sesiones y los tokens expirados
+	auth.StartSweeper(context.Background(), sessions, config.Duration("SESSION_SWEEP_INTERVAL", time.Hour))
 
-	// Definir las rutas
-	r.HandleFunc("/api/tasks", taskHandler.GetTasks).Methods("GET")
-	r.HandleFunc("/api/tasks/{id:[0-9]+}", taskHandler.GetTask).Methods("GET")
-	
-	// Ruta para crear tareas - asegurarse de que esté correctamente configurada
-	logger.InfoLogger.Println("Configurando ruta POST para crear tareas")
-	r.HandleFunc("/api/tasks", taskHandler.CreateTask).Methods("POST")
-	
-	r.HandleFunc("/api/tasks/{id:[0-9]+}", taskHandler.UpdateTask).Methods("PUT")
-	r.HandleFunc("/api/tasks/{id:[0-9]+}", taskHandler.DeleteTask).Methods("DELETE")
-	
-	// Agregar manejo de solicitudes OPTIONS para CORS
-	logger.InfoLogger.Println("Configurando rutas OPTIONS para CORS")
-	r.HandleFunc("/api/tasks", taskHandler.HandlePreflight).Methods("OPTIONS")
-	r.HandleFunc("/api/tasks/{id:[0-9]+}", taskHandler.HandlePreflight).Methods("OPTIONS")
+	// Purgar periódicamente las tareas que superan la retención de la papelera
+	taskHandler.StartTrashPurger(context.Background(), config.Duration("TRASH_RETENTION", 30*24*time.Hour), time.Hour)
+
+	routes := &apiRoutes{
+		sessions: sessions,
+		limiter:  limiter,
+		apiKeys:  apiKeys,
+		// Los reintentos con Idempotency-Key reciben la respuesta original
+		idempotent: middleware.IdempotencyMiddleware(idempotencyStore, config.Duration("IDEMPOTENCY_TTL", 24*time.Hour)),
+		tasks:      taskHandler,
+		reports:    handlers.NewReportHandler(historyStore),
+		auth:       handlers.NewAuthHandler(users, sessions),
+		events:     handlers.NewEventsHandler(bus),
+		ws:         handlers.NewWebSocketHandler(taskHandler, bus),
+		webhooks:   handlers.NewWebhookHandler(webhookStore, dispatcher),
+		audit:      handlers.NewAuditHandler(auditStore),
+		views:      handlers.NewViewHandler(viewStore, taskStore),
+		graphql:    handlers.NewGraphQLHandler(taskHandler),
+		calendar:   handlers.NewCalendarHandler(taskHandler, feedStore),
+		docs:       openapi.NewHandler(r),
+	}
+
+	// Las rutas se publican bajo /api/v1. Las rutas sin versión se mantienen
+	// como alias obsoletos hasta la fecha de API_LEGACY_SUNSET.
+	v1 := r.PathPrefix("/api/v1").Subrouter()
+	v1.Use(apiversion.Middleware(apiversion.V1))
+	routes.register(v1)
+
+	legacy := r.PathPrefix("/api").Subrouter()
+	legacy.Use(middleware.Deprecated("/api", "/api/v1", legacyDeprecatedSince,
+		config.Date("API_LEGACY_SUNSET", legacyDeprecatedSince.AddDate(0, 6, 0))))
+	legacy.Use(apiversion.Middleware(apiversion.V1))
+	routes.register(legacy)
+
+	// CalDAV para las apps de recordatorios, con autenticación básica porque
+	// los clientes no obtienen tokens. Se descubre en /.well-known/caldav.
+	caldavHandler := handlers.NewCalDAVHandler(taskHandler, caldavStore)
+	r.Handle("/.well-known/caldav", http.RedirectHandler(handlers.CalDAVPrefix+"/", http.StatusMovedPermanently))
+	r.PathPrefix(handlers.CalDAVPrefix + "/").HandlerFunc(caldavHandler.Options).Methods("OPTIONS")
+	dav := r.PathPrefix(handlers.CalDAVPrefix).Subrouter()
+	dav.Use(middleware.RateLimitMiddleware(limiter, middleware.RateLimit{
+		Name: "caldav", Requests: 300, Per: time.Minute, Burst: 100,
+	}, apiKeys))
+	dav.Use(middleware.BasicAuthMiddleware(users, "todo-api"))
+	dav.PathPrefix("/").HandlerFunc(caldavHandler.Propfind).Methods("PROPFIND")
+	dav.PathPrefix("/").HandlerFunc(caldavHandler.Report).Methods("REPORT")
+	dav.PathPrefix("/").HandlerFunc(caldavHandler.GetObject).Methods("GET", "HEAD")
+	dav.PathPrefix("/").HandlerFunc(caldavHandler.PutObject).Methods("PUT")
+	dav.PathPrefix("/").HandlerFunc(caldavHandler.DeleteObject).Methods("DELETE")
 
 	// Configurar ruta para manejar todas las solicitudes OPTIONS (para mayor seguridad)
 	r.PathPrefix("/").HandlerFunc(taskHandler.HandlePreflight).Methods("OPTIONS")
 
 	logger.InfoLogger.Println("Router configurado correctamente")
-	return r
+	return r, newGRPCServer(sessions, handlers.NewTaskService(taskHandler, bus))
 }
\ No newline at end of file
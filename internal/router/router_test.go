@@ -0,0 +1,95 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/claudio/todo-api/internal/logger"
+	"github.com/claudio/todo-api/internal/openapi"
+)
+
+func TestEveryRouteIsInOpenAPI(t *testing.T) {
+	logger.Init()
+	r := NewRouter(nil)
+
+	_, missing := openapi.Build(r)
+	for _, route := range missing {
+		t.Errorf("La ruta %s no está documentada en internal/openapi/operations.go", route)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/openapi.json", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusOK)
+	}
+
+	var doc openapi.Document
+	if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("El documento no es JSON válido: %v", err)
+	}
+	// Todas las referencias deben apuntar a un componente definido
+	var raw interface{}
+	json.Unmarshal(rr.Body.Bytes(), &raw)
+	checkRefs(t, raw, raw)
+
+	if doc.OpenAPI != "3.1.0" || doc.Paths["/api/v1/tasks/{id}"]["get"] == nil {
+		t.Errorf("Documento incompleto: openapi=%s, rutas=%d", doc.OpenAPI, len(doc.Paths))
+	}
+	if legacy := doc.Paths["/api/tasks/{id}"]["get"]; legacy == nil || legacy["deprecated"] != true {
+		t.Errorf("El alias sin versión debe figurar como obsoleto: %v", legacy)
+	}
+}
+
+func TestVersionedAndLegacyRoutes(t *testing.T) {
+	logger.Init()
+	r := NewRouter(nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/tasks/1", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || rr.Header().Get("API-Version") != "v1" || rr.Header().Get("Deprecation") != "" {
+		t.Errorf("Respuesta de /api/v1 incorrecta: %v %v", rr.Code, rr.Header())
+	}
+
+	// La ruta sin versión sigue funcionando pero se anuncia como obsoleta
+	req, _ = http.NewRequest("GET", "/api/tasks/1", nil)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("El handler devolvió un código de estado incorrecto: obtuvo %v, esperaba %v", rr.Code, http.StatusOK)
+	}
+	if rr.Header().Get("Deprecation") == "" || rr.Header().Get("Sunset") == "" {
+		t.Errorf("Faltan los encabezados de obsolescencia: %v", rr.Header())
+	}
+	if link := rr.Header().Get("Link"); link != `</api/v1/tasks/1>; rel="successor-version"` {
+		t.Errorf("Link incorrecto: %s", link)
+	}
+}
+
+// checkRefs comprueba que cada $ref del documento se pueda resolver
+func checkRefs(t *testing.T, root, node interface{}) {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := value["$ref"].(string); ok {
+			target := root
+			for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+				object, _ := target.(map[string]interface{})
+				target = object[part]
+			}
+			if target == nil {
+				t.Errorf("Referencia sin definir: %s", ref)
+			}
+		}
+		for _, child := range value {
+			checkRefs(t, root, child)
+		}
+	case []interface{}:
+		for _, child := range value {
+			checkRefs(t, root, child)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package router
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/claudio/todo-api/internal/auth"
+	"github.com/claudio/todo-api/internal/handlers"
+	"github.com/claudio/todo-api/internal/logger"
+	"github.com/claudio/todo-api/internal/middleware"
+	"github.com/claudio/todo-api/internal/openapi"
+	"github.com/gorilla/mux"
+)
+
+// apiRoutes reúne los handlers de la API para registrarlos bajo cada prefijo
+// de versión. Los handlers y los almacenes se comparten entre versiones.
+type apiRoutes struct {
+	sessions   auth.SessionStore
+	limiter    middleware.RateLimitStore
+	apiKeys    *auth.APIKeyStore
+	idempotent func(http.Handler) http.Handler
+
+	tasks    *handlers.TaskHandler
+	reports  *handlers.ReportHandler
+	auth     *handlers.AuthHandler
+	events   *handlers.EventsHandler
+	ws       *handlers.WebSocketHandler
+	webhooks *handlers.WebhookHandler
+	audit    *handlers.AuditHandler
+	views    *handlers.ViewHandler
+	graphql  *handlers.GraphQLHandler
+	calendar *handlers.CalendarHandler
+	docs     *openapi.Handler
+}
+
+// rateLimit crea el limitador de un grupo de rutas con el almacén y las
+// claves de API compartidos
+func (h *apiRoutes) rateLimit(limit middleware.RateLimit) func(http.Handler) http.Handler {
+	return middleware.RateLimitMiddleware(h.limiter, limit, h.apiKeys)
+}
+
+// register define las rutas de la API en api, cuyas rutas son relativas al
+// prefijo de la versión (/api/v1 o /api)
+func (h *apiRoutes) register(api *mux.Router) {
+	// Endpoint de prueba
+	api.HandleFunc("/health", h.tasks.HealthCheck).Methods("GET")
+
+	// Definir las rutas
+	tasks := api.NewRoute().Subrouter()
+	tasks.Use(middleware.OptionalAuthMiddleware(h.sessions))
+	tasks.Use(h.rateLimit(middleware.RateLimit{
+		Name: "tasks", Requests: 120, Per: time.Minute, Burst: 30,
+	}))
+	tasks.Use(h.idempotent)
+	tasks.HandleFunc("/tasks", h.tasks.GetTasks).Methods("GET")
+	tasks.HandleFunc("/tasks/{id:[0-9]+}", h.tasks.GetTask).Methods("GET")
+
+	// Ruta para crear tareas - asegurarse de que esté correctamente configurada
+	logger.InfoLogger.Println("Configurando ruta POST para crear tareas")
+	tasks.HandleFunc("/tasks", h.tasks.CreateTask).Methods("POST")
+
+	tasks.HandleFunc("/tasks/{id:[0-9]+}", h.tasks.UpdateTask).Methods("PUT")
+	tasks.HandleFunc("/tasks/{id:[0-9]+}", h.tasks.DeleteTask).Methods("DELETE")
+
+	// Búsqueda de texto completo
+	tasks.HandleFunc("/tasks/search", h.tasks.SearchTasks).Methods("GET")
+
+	// Estadísticas de las tareas visibles para el usuario
+	tasks.HandleFunc("/stats", h.tasks.GetStats).Methods("GET")
+
+	// Informes de burndown y flujo acumulado a partir del historial
+	tasks.HandleFunc("/projects/{id:[0-9]+}/reports/burndown", h.reports.GetBurndown).Methods("GET")
+	tasks.HandleFunc("/projects/{id:[0-9]+}/reports/cumulative-flow", h.reports.GetCumulativeFlow).Methods("GET")
+
+	// Operaciones masivas sobre tareas
+	tasks.HandleFunc("/tasks/bulk", h.tasks.BulkTasks).Methods("POST")
+
+	// Importación y exportación de tareas en JSON Lines, CSV y todo.txt
+	tasks.HandleFunc("/export", h.tasks.ExportTasks).Methods("GET")
+	tasks.HandleFunc("/import", h.tasks.ImportTasks).Methods("POST")
+
+	// Papelera de tareas eliminadas
+	tasks.HandleFunc("/trash", h.tasks.GetTrash).Methods("GET")
+	tasks.HandleFunc("/tasks/{id:[0-9]+}/restore", h.tasks.RestoreTask).Methods("POST")
+
+	// Historial de revisiones de cada tarea
+	tasks.HandleFunc("/tasks/{id:[0-9]+}/history", h.tasks.GetTaskHistory).Methods("GET")
+	tasks.HandleFunc("/tasks/{id:[0-9]+}/history/{revision:[0-9]+}/restore", h.tasks.RestoreTaskRevision).Methods("POST")
+
+	// Consultas y mutaciones de tareas, comentarios y proyectos con GraphQL
+	tasks.HandleFunc("/graphql", h.graphql.ExecuteGraphQL).Methods("POST")
+
+	// Rutas de autenticación y gestión de sesiones, con un límite más
+	// estricto para dificultar ataques de fuerza bruta
+	login := api.NewRoute().Subrouter()
+	login.Use(h.rateLimit(middleware.RateLimit{
+		Name: "auth", Requests: 10, Per: time.Minute, Burst: 5,
+	}))
+	login.HandleFunc("/auth/login", h.auth.Login).Methods("POST")
+	login.HandleFunc("/auth/refresh", h.auth.Refresh).Methods("POST")
+
+	// Rutas que requieren un token de acceso válido
+	protected := api.PathPrefix("/auth").Subrouter()
+	protected.Use(middleware.AuthMiddleware(h.sessions))
+	protected.Use(h.rateLimit(middleware.RateLimit{
+		Name: "sessions", Requests: 60, Per: time.Minute, Burst: 20,
+	}))
+	protected.HandleFunc("/logout", h.auth.Logout).Methods("POST")
+	protected.HandleFunc("/sessions", h.auth.GetSessions).Methods("GET")
+	protected.HandleFunc("/sessions/{id}", h.auth.RevokeSession).Methods("DELETE")
+
+	// Sincronización incremental para clientes sin conexión
+	syncRoutes := api.PathPrefix("/sync").Subrouter()
+	syncRoutes.Use(middleware.AuthMiddleware(h.sessions))
+	syncRoutes.Use(h.rateLimit(middleware.RateLimit{
+		Name: "sync", Requests: 60, Per: time.Minute, Burst: 20,
+	}))
+	syncRoutes.Use(h.idempotent)
+	syncRoutes.HandleFunc("", h.tasks.GetSync).Methods("GET")
+	syncRoutes.HandleFunc("", h.tasks.PostSync).Methods("POST")
+
+	// Flujo de eventos de tareas (Server-Sent Events). Las conexiones son
+	// largas, así que el límite se aplica a las reconexiones.
+	eventRoutes := api.PathPrefix("/events").Subrouter()
+	eventRoutes.Use(middleware.AuthMiddleware(h.sessions))
+	eventRoutes.Use(h.rateLimit(middleware.RateLimit{
+		Name: "events", Requests: 10, Per: time.Minute, Burst: 5,
+	}))
+	eventRoutes.HandleFunc("", h.events.Stream).Methods("GET")
+
+	// API WebSocket para suscribirse a proyectos y modificar tareas
+	ws := api.PathPrefix("/ws").Subrouter()
+	ws.Use(middleware.TokenFromQuery)
+	ws.Use(middleware.AuthMiddleware(h.sessions))
+	ws.Use(h.rateLimit(middleware.RateLimit{
+		Name: "ws", Requests: 10, Per: time.Minute, Burst: 5,
+	}))
+	ws.HandleFunc("", h.ws.Serve).Methods("GET")
+
+	// Webhooks salientes con entregas firmadas
+	hooks := api.PathPrefix("/webhooks").Subrouter()
+	hooks.Use(middleware.AuthMiddleware(h.sessions))
+	hooks.Use(h.rateLimit(middleware.RateLimit{
+		Name: "webhooks", Requests: 60, Per: time.Minute, Burst: 20,
+	}))
+	hooks.HandleFunc("", h.webhooks.GetWebhooks).Methods("GET")
+	hooks.HandleFunc("", h.webhooks.CreateWebhook).Methods("POST")
+	hooks.HandleFunc("/dead-letters", h.webhooks.GetDeadLetters).Methods("GET")
+	hooks.HandleFunc("/dead-letters/{id}/retry", h.webhooks.RetryDeadLetter).Methods("POST")
+	hooks.HandleFunc("/{id}", h.webhooks.DeleteWebhook).Methods("DELETE")
+	hooks.HandleFunc("/{id}/deliveries", h.webhooks.GetDeliveries).Methods("GET")
+
+	// Registro de auditoría, solo para usuarios autenticados
+	auditRoutes := api.PathPrefix("/audit").Subrouter()
+	auditRoutes.Use(middleware.AuthMiddleware(h.sessions))
+	auditRoutes.Use(h.rateLimit(middleware.RateLimit{
+		Name: "audit", Requests: 30, Per: time.Minute, Burst: 10,
+	}))
+	auditRoutes.HandleFunc("", h.audit.GetAudit).Methods("GET")
+	auditRoutes.HandleFunc("/export", h.audit.ExportAudit).Methods("GET")
+
+	// Vistas guardadas, compartibles con otros usuarios
+	viewRoutes := api.PathPrefix("/views").Subrouter()
+	viewRoutes.Use(middleware.AuthMiddleware(h.sessions))
+	viewRoutes.Use(h.rateLimit(middleware.RateLimit{
+		Name: "views", Requests: 120, Per: time.Minute, Burst: 30,
+	}))
+	viewRoutes.Use(h.idempotent)
+	viewRoutes.HandleFunc("", h.views.GetViews).Methods("GET")
+	viewRoutes.HandleFunc("", h.views.CreateView).Methods("POST")
+	viewRoutes.HandleFunc("/{id}", h.views.GetView).Methods("GET")
+	viewRoutes.HandleFunc("/{id}", h.views.UpdateView).Methods("PUT")
+	viewRoutes.HandleFunc("/{id}", h.views.DeleteView).Methods("DELETE")
+	viewRoutes.HandleFunc("/{id}/tasks", h.views.GetViewTasks).Methods("GET")
+
+	// Suscripción iCalendar: la URL secreta se administra con un token de
+	// acceso y el calendario se sirve sin autenticación, solo con el token
+	feedRoutes := api.PathPrefix("/calendar/feed").Subrouter()
+	feedRoutes.Use(middleware.AuthMiddleware(h.sessions))
+	feedRoutes.HandleFunc("", h.calendar.CreateFeed).Methods("POST")
+	feedRoutes.HandleFunc("", h.calendar.DeleteFeed).Methods("DELETE")
+	calendar := api.PathPrefix("/calendar").Subrouter()
+	calendar.Use(h.rateLimit(middleware.RateLimit{
+		Name: "calendar", Requests: 60, Per: time.Minute, Burst: 10,
+	}))
+	calendar.HandleFunc("/{token:[0-9a-f]+}.ics", h.calendar.GetFeed).Methods("GET")
+
+	// Documento OpenAPI de todas las rutas y visor interactivo
+	api.HandleFunc("/openapi.json", h.docs.Spec).Methods("GET")
+	api.HandleFunc("/docs", h.docs.Viewer).Methods("GET")
+
+	// Agregar manejo de solicitudes OPTIONS para CORS
+	api.HandleFunc("/tasks", h.tasks.HandlePreflight).Methods("OPTIONS")
+	api.HandleFunc("/tasks/{id:[0-9]+}", h.tasks.HandlePreflight).Methods("OPTIONS")
+}
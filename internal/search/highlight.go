@@ -0,0 +1,87 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+// Marcas que rodean los términos encontrados en los fragmentos
+const (
+	markStart = "<mark>"
+	markEnd   = "</mark>"
+)
+
+// snippetContext es la cantidad de términos que se muestran antes de la
+// primera coincidencia
+const snippetContext = 5
+
+// Highlight devuelve un fragmento del texto de hasta maxLen caracteres
+// alrededor de la primera coincidencia, con los términos encontrados entre
+// <mark> y </mark>. El resto del texto se escapa como HTML. Si el texto no
+// contiene ninguna coincidencia devuelve una cadena vacía.
+func Highlight(text string, query Query, maxLen int) string {
+	tokens := Tokenize(text)
+	marked := make([]bool, len(tokens))
+	first := -1
+
+	for _, clause := range query.Clauses {
+		for i := range tokens {
+			if i+len(clause.Terms) > len(tokens) {
+				break
+			}
+			match := true
+			for j := range clause.Terms {
+				if !clause.matchesTerm(j, tokens[i+j].Term) {
+					match = false
+					break
+				}
+			}
+			if !match {
+				continue
+			}
+			for j := range clause.Terms {
+				marked[i+j] = true
+			}
+			if first < 0 || i < first {
+				first = i
+			}
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+
+	// Elegir la ventana de términos que cabe en maxLen
+	start, end := 0, len(tokens)
+	if runeCount(text) > maxLen {
+		if first > snippetContext {
+			start = first - snippetContext
+		}
+		end = start + 1
+		for end < len(tokens) && runeCount(text[tokens[start].Start:tokens[end].End]) <= maxLen {
+			end++
+		}
+	}
+
+	var b strings.Builder
+	from := 0
+	if start > 0 {
+		b.WriteString("…")
+		from = tokens[start].Start
+	}
+	for i := start; i < end; i++ {
+		b.WriteString(html.EscapeString(text[from:tokens[i].Start]))
+		word := html.EscapeString(text[tokens[i].Start:tokens[i].End])
+		if marked[i] {
+			word = markStart + word + markEnd
+		}
+		b.WriteString(word)
+		from = tokens[i].End
+	}
+	if end < len(tokens) {
+		b.WriteString("…")
+	} else {
+		b.WriteString(html.EscapeString(text[from:]))
+	}
+	return b.String()
+}
@@ -0,0 +1,258 @@
+// Package search implementa la búsqueda de texto completo del almacén en
+// memoria. Se indexan el título y la descripción de cada tarea; los
+// comentarios que pide la búsqueda no se indexan porque las tareas todavía
+// no tienen comentarios. Cuando existan se agregarán como un campo más, con
+// su peso, aquí y en la columna search de PostgreSQL.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Campos indexados de cada tarea, en el orden en que se pasan a Add
+const (
+	fieldTitle = iota
+	fieldDescription
+	numFields
+)
+
+// fieldWeights da más peso a las coincidencias en el título
+var fieldWeights = [numFields]float64{2, 1}
+
+// Parámetros de BM25
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// occurrences guarda las posiciones de un término en cada campo de un documento
+type occurrences [numFields][]int
+
+// document guarda lo necesario para puntuar y eliminar un documento
+type document struct {
+	lengths [numFields]int
+	terms   []string
+}
+
+// Hit es un documento encontrado con su puntuación
+type Hit struct {
+	ID    int
+	Score float64
+}
+
+// Index es un índice invertido en memoria con posiciones, para búsquedas
+// por término, prefijo y frase ordenadas por relevancia (BM25)
+type Index struct {
+	mu       sync.RWMutex
+	postings map[string]map[int]*occurrences
+	docs     map[int]*document
+	totalLen [numFields]int
+}
+
+// NewIndex crea un índice vacío
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[int]*occurrences),
+		docs:     make(map[int]*document),
+	}
+}
+
+// Add indexa un documento con su título y descripción,
+// reemplazando la versión anterior si existía
+func (ix *Index) Add(id int, fields ...string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.remove(id)
+
+	doc := &document{}
+	for field := 0; field < numFields && field < len(fields); field++ {
+		tokens := Tokenize(fields[field])
+		doc.lengths[field] = len(tokens)
+		ix.totalLen[field] += len(tokens)
+
+		for _, token := range tokens {
+			docs, ok := ix.postings[token.Term]
+			if !ok {
+				docs = make(map[int]*occurrences)
+				ix.postings[token.Term] = docs
+			}
+			occ, ok := docs[id]
+			if !ok {
+				occ = &occurrences{}
+				docs[id] = occ
+				doc.terms = append(doc.terms, token.Term)
+			}
+			occ[field] = append(occ[field], token.Position)
+		}
+	}
+	ix.docs[id] = doc
+}
+
+// Remove elimina un documento del índice
+func (ix *Index) Remove(id int) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.remove(id)
+}
+
+// remove elimina un documento. Debe llamarse con el mutex tomado.
+func (ix *Index) remove(id int) {
+	doc, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range doc.terms {
+		delete(ix.postings[term], id)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+		}
+	}
+	for field := 0; field < numFields; field++ {
+		ix.totalLen[field] -= doc.lengths[field]
+	}
+	delete(ix.docs, id)
+}
+
+// Search devuelve los documentos que cumplen todas las condiciones, del más
+// relevante al menos relevante
+func (ix *Index) Search(query Query) []Hit {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	if query.Empty() || len(ix.docs) == 0 {
+		return []Hit{}
+	}
+
+	var scores map[int]float64
+	for _, clause := range query.Clauses {
+		clauseScores := ix.scoreClause(clause)
+
+		// Solo se conservan los documentos que cumplen todas las condiciones
+		if scores == nil {
+			scores = clauseScores
+			continue
+		}
+		for id := range scores {
+			if score, ok := clauseScores[id]; ok {
+				scores[id] += score
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
+
+// scoreClause puntúa los documentos que cumplen una condición
+func (ix *Index) scoreClause(clause Clause) map[int]float64 {
+	scores := make(map[int]float64)
+
+	if !clause.Phrase() {
+		for _, term := range ix.expand(clause) {
+			idf := ix.idf(term)
+			for id, occ := range ix.postings[term] {
+				for field := 0; field < numFields; field++ {
+					scores[id] += ix.bm25(len(occ[field]), field, id, idf)
+				}
+			}
+		}
+		return scores
+	}
+
+	// Una frase se puntúa como un término cuya frecuencia es la cantidad de
+	// veces que aparece la secuencia completa
+	idf := 0.0
+	for _, term := range clause.Terms {
+		idf += ix.idf(term)
+	}
+	for id, first := range ix.postings[clause.Terms[0]] {
+		for field := 0; field < numFields; field++ {
+			if tf := ix.phraseFrequency(clause.Terms, id, field, first[field]); tf > 0 {
+				scores[id] += ix.bm25(tf, field, id, idf)
+			}
+		}
+	}
+	return scores
+}
+
+// phraseFrequency cuenta cuántas veces aparece la frase en un campo
+func (ix *Index) phraseFrequency(terms []string, id, field int, starts []int) int {
+	count := 0
+	for _, start := range starts {
+		found := true
+		for offset, term := range terms[1:] {
+			occ, ok := ix.postings[term][id]
+			if !ok || !containsInt(occ[field], start+offset+1) {
+				found = false
+				break
+			}
+		}
+		if found {
+			count++
+		}
+	}
+	return count
+}
+
+// expand devuelve los términos indexados que cumplen una condición de un término
+func (ix *Index) expand(clause Clause) []string {
+	if !clause.Prefix {
+		return clause.Terms
+	}
+
+	expanded := []string{}
+	for term := range ix.postings {
+		if strings.HasPrefix(term, clause.Terms[0]) {
+			expanded = append(expanded, term)
+		}
+	}
+	return expanded
+}
+
+// idf calcula la frecuencia inversa de documentos de un término
+func (ix *Index) idf(term string) float64 {
+	n := float64(len(ix.docs))
+	df := float64(len(ix.postings[term]))
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+// bm25 puntúa tf apariciones en un campo del documento
+func (ix *Index) bm25(tf, field, id int, idf float64) float64 {
+	if tf == 0 {
+		return 0
+	}
+	avgLen := float64(ix.totalLen[field]) / float64(len(ix.docs))
+	if avgLen == 0 {
+		avgLen = 1
+	}
+	length := float64(ix.docs[id].lengths[field])
+	freq := float64(tf)
+	return fieldWeights[field] * idf * freq * (bm25K1 + 1) / (freq + bm25K1*(1-bm25B+bm25B*length/avgLen))
+}
+
+// containsInt indica si la lista contiene el valor
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package search
+
+import (
+	"strings"
+)
+
+// Clause es una condición de la búsqueda: un término, un prefijo (term*) o
+// una frase entre comillas cuyos términos deben aparecer seguidos
+type Clause struct {
+	Terms  []string
+	Prefix bool
+}
+
+// Phrase indica si la condición es una frase
+func (c Clause) Phrase() bool {
+	return len(c.Terms) > 1
+}
+
+// Query es una búsqueda ya interpretada. Una tarea coincide si cumple todas
+// las condiciones.
+type Query struct {
+	Clauses []Clause
+}
+
+// Empty indica si la búsqueda no tiene condiciones
+func (q Query) Empty() bool {
+	return len(q.Clauses) == 0
+}
+
+// Parse interpreta el texto de búsqueda. Las frases se escriben entre
+// comillas dobles y los prefijos terminan en asterisco: `"lista de" compra*`.
+func Parse(text string) Query {
+	var query Query
+	for i, part := range strings.Split(text, `"`) {
+		// Las partes impares están entre comillas
+		if i%2 == 1 {
+			if phrase := terms(part); len(phrase) > 0 {
+				query.Clauses = append(query.Clauses, Clause{Terms: phrase})
+			}
+			continue
+		}
+
+		for _, word := range strings.Fields(part) {
+			prefix := strings.HasSuffix(word, "*")
+			words := terms(strings.TrimRight(word, "*"))
+			switch {
+			case len(words) == 0:
+			case len(words) == 1:
+				query.Clauses = append(query.Clauses, Clause{Terms: words, Prefix: prefix})
+			default:
+				// Palabras compuestas como "e-mail" se buscan como frase
+				query.Clauses = append(query.Clauses, Clause{Terms: words})
+			}
+		}
+	}
+	return query
+}
+
+// matchesTerm indica si un término del texto cumple el término de la condición
+func (c Clause) matchesTerm(index int, term string) bool {
+	if c.Prefix && index == len(c.Terms)-1 {
+		return strings.HasPrefix(term, c.Terms[index])
+	}
+	return term == c.Terms[index]
+}
@@ -0,0 +1,74 @@
+package search
+
+import (
+	"testing"
+)
+
+func TestIndexSearch(t *testing.T) {
+	index := NewIndex()
+	index.Add(1, "Comprar leche", "Ir al supermercado antes de las ocho")
+	index.Add(2, "Lista de compras", "Leche, pan y café para la semana")
+	index.Add(3, "Llamar al médico", "Pedir turno para la revisión anual")
+
+	ids := func(hits []Hit) []int {
+		result := []int{}
+		for _, hit := range hits {
+			result = append(result, hit.ID)
+		}
+		return result
+	}
+
+	// Las coincidencias en el título pesan más que en la descripción
+	if got := ids(index.Search(Parse("leche"))); len(got) != 2 || got[0] != 1 {
+		t.Errorf("Búsqueda de término incorrecta: %v", got)
+	}
+
+	// Los acentos y las mayúsculas se ignoran
+	if got := ids(index.Search(Parse("MEDICO"))); len(got) != 1 || got[0] != 3 {
+		t.Errorf("Búsqueda sin acentos incorrecta: %v", got)
+	}
+
+	// Prefijos
+	if got := ids(index.Search(Parse("compr*"))); len(got) != 2 {
+		t.Errorf("Búsqueda por prefijo incorrecta: %v", got)
+	}
+
+	// Las frases exigen los términos seguidos
+	if got := ids(index.Search(Parse(`"lista de compras"`))); len(got) != 1 || got[0] != 2 {
+		t.Errorf("Búsqueda de frase incorrecta: %v", got)
+	}
+	if got := ids(index.Search(Parse(`"compras de lista"`))); len(got) != 0 {
+		t.Errorf("La frase desordenada no debería coincidir: %v", got)
+	}
+
+	// Todas las condiciones deben cumplirse
+	if got := ids(index.Search(Parse("leche pan"))); len(got) != 1 || got[0] != 2 {
+		t.Errorf("Búsqueda con varias condiciones incorrecta: %v", got)
+	}
+
+	// Al eliminar un documento deja de aparecer
+	index.Remove(2)
+	if got := ids(index.Search(Parse("leche"))); len(got) != 1 || got[0] != 1 {
+		t.Errorf("Búsqueda después de eliminar incorrecta: %v", got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	query := Parse(`compr* "de la"`)
+	got := Highlight("Compras de la <semana>", query, 160)
+	want := "<mark>Compras</mark> <mark>de</mark> <mark>la</mark> &lt;semana&gt;"
+	if got != want {
+		t.Errorf("Resaltado incorrecto:\n obtuvo %q\n esperaba %q", got, want)
+	}
+
+	if got := Highlight("Sin coincidencias", query, 160); got != "" {
+		t.Errorf("Se esperaba un fragmento vacío, obtuvo %q", got)
+	}
+
+	// Los textos largos se recortan alrededor de la coincidencia
+	long := "uno dos tres cuatro cinco seis siete ocho nueve diez once doce compras trece catorce quince"
+	got = Highlight(long, Parse("compras"), 40)
+	if got != "…ocho nueve diez once doce <mark>compras</mark> trece…" {
+		t.Errorf("Fragmento incorrecto: %q", got)
+	}
+}
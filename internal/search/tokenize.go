@@ -0,0 +1,66 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token es un término normalizado con su posición en el texto original
+type Token struct {
+	Term     string
+	Position int
+	Start    int
+	End      int
+}
+
+// accents traduce las letras acentuadas más comunes a su forma sin acento
+var accents = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u",
+	"â", "a", "ê", "e", "î", "i", "ô", "o", "û", "u",
+	"ç", "c",
+)
+
+// Normalize pasa el término a minúsculas y elimina los acentos, de modo que
+// "Canción" y "cancion" coinciden. La ñ se conserva.
+func Normalize(term string) string {
+	return accents.Replace(strings.ToLower(term))
+}
+
+// Tokenize divide el texto en términos formados por letras y dígitos
+func Tokenize(text string) []Token {
+	tokens := []Token{}
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, Token{Term: Normalize(text[start:i]), Position: len(tokens), Start: start, End: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, Token{Term: Normalize(text[start:]), Position: len(tokens), Start: start, End: len(text)})
+	}
+	return tokens
+}
+
+// terms devuelve solo los términos de los tokens
+func terms(text string) []string {
+	tokens := Tokenize(text)
+	result := make([]string, len(tokens))
+	for i, token := range tokens {
+		result[i] = token.Term
+	}
+	return result
+}
+
+// runeCount devuelve la cantidad de caracteres del texto
+func runeCount(text string) int {
+	return utf8.RuneCountInString(text)
+}
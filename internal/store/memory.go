@@ -0,0 +1,318 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/claudio/todo-api/internal/filter"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/search"
+)
+
+// MemoryTaskStore guarda las tareas y el outbox en memoria. Cada transacción
+// trabaja sobre una copia que reemplaza al estado solo si fn termina sin error.
+// Un índice invertido se actualiza con cada commit para la búsqueda.
+type MemoryTaskStore struct {
+	mu           sync.RWMutex
+	tasks        []models.Task
+	index        *search.Index
+	nextID       int
+	seq          int64
+	tombstones   []models.Tombstone
+	outbox       []models.OutboxMessage
+	nextOutboxID int64
+	wake         chan struct{}
+}
+
+// NewMemoryTaskStore crea un almacén de tareas en memoria vacío
+func NewMemoryTaskStore() *MemoryTaskStore {
+	return &MemoryTaskStore{
+		tasks:        []models.Task{},
+		index:        search.NewIndex(),
+		nextID:       1,
+		tombstones:   []models.Tombstone{},
+		outbox:       []models.OutboxMessage{},
+		nextOutboxID: 1,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// List devuelve todas las tareas ordenadas por ID
+func (s *MemoryTaskStore) List(ctx context.Context) ([]models.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tasks := make([]models.Task, len(s.tasks))
+	copy(tasks, s.tasks)
+	return tasks, nil
+}
+
+// Find evalúa la expresión sobre cada tarea
+func (s *MemoryTaskStore) Find(ctx context.Context, expr filter.Expr) ([]models.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tasks := []models.Task{}
+	for _, task := range s.tasks {
+		if filter.Match(expr, task) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+// Get devuelve una tarea por ID
+func (s *MemoryTaskStore) Get(ctx context.Context, id int) (models.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if i := indexOf(s.tasks, id); i >= 0 {
+		return s.tasks[i], nil
+	}
+	return models.Task{}, ErrNotFound
+}
+
+// Changes devuelve los cambios con secuencia mayor que since
+func (s *MemoryTaskStore) Changes(ctx context.Context, since int64, limit int) ([]models.Task, []models.Tombstone, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tasks := []models.Task{}
+	for _, task := range s.tasks {
+		if task.Seq > since {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Seq < tasks[j].Seq
+	})
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+
+	// Las tombstones se agregan en orden de secuencia
+	tombstones := []models.Tombstone{}
+	for _, tombstone := range s.tombstones {
+		if tombstone.Seq > since && len(tombstones) < limit {
+			tombstones = append(tombstones, tombstone)
+		}
+	}
+	return tasks, tombstones, nil
+}
+
+// Search busca en el índice invertido
+func (s *MemoryTaskStore) Search(ctx context.Context, query search.Query, userID string, limit int) ([]models.SearchHit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hits := []models.SearchHit{}
+	for _, hit := range s.index.Search(query) {
+		i := indexOf(s.tasks, hit.ID)
+		if i < 0 || !s.tasks[i].VisibleTo(userID) {
+			continue
+		}
+		hits = append(hits, models.SearchHit{Task: s.tasks[i], Score: hit.Score})
+		if len(hits) == limit {
+			break
+		}
+	}
+	return hits, nil
+}
+
+// Stats recorre todas las tareas con computeStats
+func (s *MemoryTaskStore) Stats(ctx context.Context, query models.StatsQuery) (models.TaskStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return computeStats(s.tasks, query), nil
+}
+
+// Tx ejecuta fn sobre una copia del estado y la confirma si no hay error
+func (s *MemoryTaskStore) Tx(ctx context.Context, fn func(tx Tx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := &memoryTx{
+		tasks:        append([]models.Task(nil), s.tasks...),
+		nextID:       s.nextID,
+		seq:          s.seq,
+		tombstones:   append([]models.Tombstone(nil), s.tombstones...),
+		nextOutboxID: s.nextOutboxID,
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	s.tasks = tx.tasks
+	for _, id := range tx.touched {
+		if i := indexOf(s.tasks, id); i >= 0 && s.tasks[i].DeletedAt == nil {
+			s.index.Add(id, s.tasks[i].Title, s.tasks[i].Description)
+		} else {
+			s.index.Remove(id)
+		}
+	}
+	s.nextID = tx.nextID
+	s.seq = tx.seq
+	s.tombstones = tx.tombstones
+	s.nextOutboxID = tx.nextOutboxID
+	if len(tx.outbox) > 0 {
+		s.outbox = append(s.outbox, tx.outbox...)
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// PendingOutbox devuelve en orden los mensajes aún no publicados
+func (s *MemoryTaskStore) PendingOutbox(ctx context.Context, limit int) ([]models.OutboxMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	messages := []models.OutboxMessage{}
+	for _, msg := range s.outbox {
+		if msg.PublishedAt != nil {
+			continue
+		}
+		messages = append(messages, msg)
+		if len(messages) == limit {
+			break
+		}
+	}
+	return messages, nil
+}
+
+// MarkPublished marca los mensajes como publicados y descarta los ya publicados
+func (s *MemoryTaskStore) MarkPublished(ctx context.Context, ids ...int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	published := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		published[id] = true
+	}
+
+	pending := s.outbox[:0]
+	for _, msg := range s.outbox {
+		if !published[msg.ID] && msg.PublishedAt == nil {
+			pending = append(pending, msg)
+		}
+	}
+	s.outbox = pending
+	return nil
+}
+
+// Wake notifica que hay mensajes nuevos en el outbox
+func (s *MemoryTaskStore) Wake() <-chan struct{} {
+	return s.wake
+}
+
+// memoryTx implementa Tx sobre una copia de las tareas
+type memoryTx struct {
+	tasks        []models.Task
+	touched      []int
+	nextID       int
+	seq          int64
+	tombstones   []models.Tombstone
+	outbox       []models.OutboxMessage
+	nextOutboxID int64
+}
+
+func (tx *memoryTx) Get(id int) (models.Task, error) {
+	if i := indexOf(tx.tasks, id); i >= 0 {
+		return tx.tasks[i], nil
+	}
+	return models.Task{}, ErrNotFound
+}
+
+func (tx *memoryTx) Insert(task *models.Task) error {
+	task.ID = tx.nextID
+	tx.nextID++
+	tx.seq++
+	task.Seq = tx.seq
+	task.CreatedSeq = tx.seq
+	tx.tasks = append(tx.tasks, *task)
+	tx.touched = append(tx.touched, task.ID)
+	return nil
+}
+
+func (tx *memoryTx) Save(task *models.Task) error {
+	tx.seq++
+	task.Seq = tx.seq
+	tx.touched = append(tx.touched, task.ID)
+
+	i := indexOf(tx.tasks, task.ID)
+	if i < 0 {
+		// Restaurar una tarea purgada conserva su ID original y anula su tombstone
+		task.CreatedSeq = tx.seq
+		tx.removeTombstone(task.ID)
+		tx.tasks = append(tx.tasks, *task)
+		sortByID(tx.tasks)
+		if task.ID >= tx.nextID {
+			tx.nextID = task.ID + 1
+		}
+		return nil
+	}
+	task.CreatedSeq = tx.tasks[i].CreatedSeq
+	tx.tasks[i] = *task
+	return nil
+}
+
+func (tx *memoryTx) Delete(id int) error {
+	i := indexOf(tx.tasks, id)
+	if i < 0 {
+		return ErrNotFound
+	}
+
+	tx.seq++
+	tx.touched = append(tx.touched, id)
+	tx.removeTombstone(id)
+	tx.tombstones = append(tx.tombstones, models.Tombstone{
+		TaskID:    id,
+		Seq:       tx.seq,
+		DeletedAt: time.Now(),
+		OwnerID:   tx.tasks[i].OwnerID,
+	})
+	tx.tasks = append(tx.tasks[:i], tx.tasks[i+1:]...)
+	return nil
+}
+
+func (tx *memoryTx) AddOutbox(event models.TaskEvent) error {
+	tx.outbox = append(tx.outbox, models.OutboxMessage{
+		ID:        tx.nextOutboxID,
+		Event:     event,
+		CreatedAt: time.Now(),
+	})
+	tx.nextOutboxID++
+	return nil
+}
+
+// removeTombstone descarta la tombstone de una tarea si existe
+func (tx *memoryTx) removeTombstone(id int) {
+	for i := range tx.tombstones {
+		if tx.tombstones[i].TaskID == id {
+			tx.tombstones = append(tx.tombstones[:i], tx.tombstones[i+1:]...)
+			return
+		}
+	}
+}
+
+// indexOf busca la posición de una tarea por ID
+func indexOf(tasks []models.Task, id int) int {
+	for i := range tasks {
+		if tasks[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// sortByID ordena las tareas por ID
+func sortByID(tasks []models.Task) {
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].ID < tasks[j].ID
+	})
+}
@@ -0,0 +1,522 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/claudio/todo-api/internal/filter"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/search"
+	"github.com/lib/pq"
+)
+
+// outboxLockKey es la clave del advisory lock que evita que dos instancias
+// lean el outbox a la vez. Los duplicados que aún puedan producirse se
+// toleran porque la entrega es al menos una vez.
+const outboxLockKey = 7340021
+
+// seqLockKey es la clave del advisory lock que serializa las escrituras desde
+// que se reserva una secuencia hasta el commit. Así las secuencias se hacen
+// visibles en orden y un cliente que sincroniza no se salta cambios que se
+// confirman tarde.
+const seqLockKey = 7340022
+
+// PostgresTaskStore guarda las tareas y el outbox en PostgreSQL
+type PostgresTaskStore struct {
+	db *sql.DB
+}
+
+// NewPostgresTaskStore crea un almacén de tareas respaldado por PostgreSQL
+func NewPostgresTaskStore(db *sql.DB) *PostgresTaskStore {
+	return &PostgresTaskStore{db: db}
+}
+
+const taskColumns = `id, title, COALESCE(description, ''), completed, COALESCE(owner_id, ''),
+	COALESCE(project_id, 0), created_at, updated_at, deleted_at, completed_at, seq, COALESCE(created_seq, 0),
+	due_date, priority, COALESCE(recurrence, ''), tags`
+
+const selectTask = `SELECT ` + taskColumns + ` FROM tasks`
+
+// queryer es implementado por *sql.DB y *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// List devuelve todas las tareas ordenadas por ID
+func (s *PostgresTaskStore) List(ctx context.Context) ([]models.Task, error) {
+	return s.queryTasks(ctx, selectTask+` ORDER BY id`)
+}
+
+// Find compila la expresión a una condición WHERE parametrizada
+func (s *PostgresTaskStore) Find(ctx context.Context, expr filter.Expr) ([]models.Task, error) {
+	where, args := filter.SQL(expr, 0)
+	return s.queryTasks(ctx, selectTask+` WHERE `+where+` ORDER BY id`, args...)
+}
+
+// queryTasks ejecuta una consulta que devuelve filas de tasks
+func (s *PostgresTaskStore) queryTasks(ctx context.Context, query string, args ...interface{}) ([]models.Task, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []models.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+// Get devuelve una tarea por ID
+func (s *PostgresTaskStore) Get(ctx context.Context, id int) (models.Task, error) {
+	return getTask(ctx, s.db, id, false)
+}
+
+// Changes devuelve los cambios con secuencia mayor que since
+func (s *PostgresTaskStore) Changes(ctx context.Context, since int64, limit int) ([]models.Task, []models.Tombstone, error) {
+	tasks, err := s.queryTasks(ctx, selectTask+` WHERE seq > $1 ORDER BY seq LIMIT $2`, since, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT task_id, seq, deleted_at, COALESCE(owner_id, '') FROM task_tombstones
+		 WHERE seq > $1 ORDER BY seq LIMIT $2`, since, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	tombstones := []models.Tombstone{}
+	for rows.Next() {
+		var tombstone models.Tombstone
+		if err := rows.Scan(&tombstone.TaskID, &tombstone.Seq, &tombstone.DeletedAt, &tombstone.OwnerID); err != nil {
+			return nil, nil, err
+		}
+		tombstones = append(tombstones, tombstone)
+	}
+	return tasks, tombstones, rows.Err()
+}
+
+// Search usa la columna tsvector de tasks y su índice GIN. La configuración
+// spanish_unaccent ignora los acentos como el índice en memoria y además
+// aplica stemming, así que "canción" y "canciones" coinciden con "cancion".
+func (s *PostgresTaskStore) Search(ctx context.Context, query search.Query, userID string, limit int) ([]models.SearchHit, error) {
+	if query.Empty() {
+		return []models.SearchHit{}, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+taskColumns+`, ts_rank_cd(search, query) AS rank
+		 FROM tasks, to_tsquery('spanish_unaccent', $1) query
+		 WHERE search @@ query AND deleted_at IS NULL AND (owner_id IS NULL OR owner_id = $2)
+		 ORDER BY rank DESC, id LIMIT $3`,
+		tsQuery(query), userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := []models.SearchHit{}
+	for rows.Next() {
+		var hit models.SearchHit
+		task, err := scanTask(extraScanner{rows, []interface{}{&hit.Score}})
+		if err != nil {
+			return nil, err
+		}
+		hit.Task = task
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
+
+// tsQuery traduce la búsqueda a la sintaxis de to_tsquery. Los términos solo
+// contienen letras y dígitos, por lo que no hace falta escaparlos.
+func tsQuery(query search.Query) string {
+	clauses := make([]string, 0, len(query.Clauses))
+	for _, clause := range query.Clauses {
+		term := strings.Join(clause.Terms, " <-> ")
+		if clause.Prefix {
+			term += ":*"
+		}
+		clauses = append(clauses, term)
+	}
+	return strings.Join(clauses, " & ")
+}
+
+// visibleTasks restringe las consultas de estadísticas a las tareas activas
+// visibles para el usuario ($1)
+const visibleTasks = `FROM tasks WHERE deleted_at IS NULL AND COALESCE(owner_id, '') IN ('', $1)`
+
+// Stats calcula las estadísticas con agregados de SQL. Las series se
+// agrupan con date_trunc y se completan con ceros en Go.
+func (s *PostgresTaskStore) Stats(ctx context.Context, query models.StatsQuery) (models.TaskStats, error) {
+	stats := models.TaskStats{Bucket: query.Bucket, ByProject: []models.ProjectStats{}, ByTag: []models.TagStats{}}
+
+	var leadSeconds sql.NullFloat64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*), COUNT(*) FILTER (WHERE completed),
+		   COUNT(*) FILTER (WHERE due_date < now() AND NOT completed),
+		   AVG(EXTRACT(EPOCH FROM completed_at - created_at)) FILTER (WHERE completed AND completed_at IS NOT NULL)
+		 `+visibleTasks, query.UserID,
+	).Scan(&stats.Total, &stats.Completed, &stats.Overdue, &leadSeconds)
+	if err != nil {
+		return stats, err
+	}
+	if leadSeconds.Valid {
+		hours := leadSeconds.Float64 / 3600
+		stats.AvgLeadTimeHours = &hours
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT COALESCE(project_id, 0), COUNT(*), COUNT(*) FILTER (WHERE completed)
+		 `+visibleTasks+` GROUP BY 1 ORDER BY 1`, query.UserID)
+	if err != nil {
+		return stats, err
+	}
+	for rows.Next() {
+		var project models.ProjectStats
+		if err := rows.Scan(&project.ProjectID, &project.Total, &project.Completed); err != nil {
+			rows.Close()
+			return stats, err
+		}
+		stats.ByProject = append(stats.ByProject, project)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+
+	// Una fila por etiqueta de cada tarea
+	rows, err = s.db.QueryContext(ctx,
+		`SELECT tag, COUNT(*), COUNT(*) FILTER (WHERE completed)
+		 FROM (SELECT unnest(tags) AS tag, completed `+visibleTasks+`) AS tagged
+		 GROUP BY 1 ORDER BY 1`, query.UserID)
+	if err != nil {
+		return stats, err
+	}
+	for rows.Next() {
+		var tag models.TagStats
+		if err := rows.Scan(&tag.Tag, &tag.Total, &tag.Completed); err != nil {
+			rows.Close()
+			return stats, err
+		}
+		stats.ByTag = append(stats.ByTag, tag)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+
+	series, index := emptySeries(query)
+	stats.Series = series
+	cohortCompleted := make([]int, len(series))
+
+	// Tareas creadas en cada intervalo y cuántas de ellas ya se completaron
+	err = s.scanBuckets(ctx, index, `SELECT date_trunc($2, created_at), COUNT(*), COUNT(*) FILTER (WHERE completed)
+		 `+visibleTasks+` AND created_at >= $3 AND created_at < $4 GROUP BY 1`,
+		query, func(i int, counts []int) {
+			stats.Series[i].Created = counts[0]
+			cohortCompleted[i] = counts[1]
+		})
+	if err != nil {
+		return stats, err
+	}
+
+	// Tareas completadas en cada intervalo
+	err = s.scanBuckets(ctx, index, `SELECT date_trunc($2, completed_at), COUNT(*), 0
+		 `+visibleTasks+` AND completed AND completed_at >= $3 AND completed_at < $4 GROUP BY 1`,
+		query, func(i int, counts []int) {
+			stats.Series[i].Completed = counts[0]
+		})
+	if err != nil {
+		return stats, err
+	}
+
+	finishStats(&stats, cohortCompleted)
+	return stats, nil
+}
+
+// scanBuckets ejecuta una consulta agrupada por intervalo y entrega los dos
+// contadores de cada fila al intervalo correspondiente de la serie
+func (s *PostgresTaskStore) scanBuckets(ctx context.Context, index map[time.Time]int, query string, q models.StatsQuery, fn func(i int, counts []int)) error {
+	rows, err := s.db.QueryContext(ctx, query, q.UserID, q.Bucket, q.From, q.To)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var start time.Time
+		counts := make([]int, 2)
+		if err := rows.Scan(&start, &counts[0], &counts[1]); err != nil {
+			return err
+		}
+		if i, ok := index[BucketStart(start, q.Bucket)]; ok {
+			fn(i, counts)
+		}
+	}
+	return rows.Err()
+}
+
+// Tx ejecuta fn dentro de una transacción SQL
+func (s *PostgresTaskStore) Tx(ctx context.Context, fn func(tx Tx) error) error {
+	sqlTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(&postgresTx{ctx: ctx, tx: sqlTx}); err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+	return sqlTx.Commit()
+}
+
+// PendingOutbox devuelve en orden los mensajes aún no publicados. Si otra
+// instancia está publicando se devuelve una lista vacía.
+func (s *PostgresTaskStore) PendingOutbox(ctx context.Context, limit int) ([]models.OutboxMessage, error) {
+	// El advisory lock pertenece a la sesión: usar la misma conexión hasta liberarlo
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, outboxLockKey).Scan(&locked); err != nil {
+		return nil, err
+	}
+	if !locked {
+		return []models.OutboxMessage{}, nil
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, outboxLockKey)
+
+	rows, err := conn.QueryContext(ctx,
+		`SELECT id, event, created_at FROM outbox WHERE published_at IS NULL ORDER BY id LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []models.OutboxMessage{}
+	for rows.Next() {
+		var msg models.OutboxMessage
+		var event []byte
+		if err := rows.Scan(&msg.ID, &event, &msg.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(event, &msg.Event); err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+// MarkPublished marca los mensajes como publicados
+func (s *PostgresTaskStore) MarkPublished(ctx context.Context, ids ...int64) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE outbox SET published_at = NOW() WHERE id = ANY($1)`, pq.Array(ids))
+	return err
+}
+
+// PruneOutbox elimina los mensajes publicados antes de before
+func (s *PostgresTaskStore) PruneOutbox(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM outbox WHERE published_at IS NOT NULL AND published_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// SQLTx es implementado por las transacciones de PostgresTaskStore. Permite
+// que los almacenes de la misma base de datos, como la auditoría y el
+// historial, escriban dentro de la transacción de la modificación.
+type SQLTx interface {
+	SQL() (context.Context, *sql.Tx)
+}
+
+// postgresTx implementa Tx sobre una transacción SQL
+type postgresTx struct {
+	ctx    context.Context
+	tx     *sql.Tx
+	locked bool
+}
+
+func (t *postgresTx) SQL() (context.Context, *sql.Tx) {
+	return t.ctx, t.tx
+}
+
+func (t *postgresTx) Get(id int) (models.Task, error) {
+	// FOR UPDATE bloquea la fila hasta el commit para evitar escrituras perdidas
+	return getTask(t.ctx, t.tx, id, true)
+}
+
+func (t *postgresTx) Insert(task *models.Task) error {
+	seq, err := t.nextSeq()
+	if err != nil {
+		return err
+	}
+
+	task.Seq = seq
+	task.CreatedSeq = seq
+	return t.tx.QueryRowContext(t.ctx,
+		`INSERT INTO tasks (title, description, completed, owner_id, project_id, created_at, updated_at, deleted_at, completed_at, seq, created_seq,
+		   due_date, priority, recurrence, tags)
+		 VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, 0), $6, $7, $8, $9, $10, $10, $11, $12, NULLIF($13, ''), $14) RETURNING id`,
+		task.Title, task.Description, task.Completed, task.OwnerID, task.ProjectID,
+		task.CreatedAt, task.UpdatedAt, task.DeletedAt, task.CompletedAt, seq,
+		task.DueDate, task.Priority, task.Recurrence, tagsArray(task.Tags),
+	).Scan(&task.ID)
+}
+
+func (t *postgresTx) Save(task *models.Task) error {
+	seq, err := t.nextSeq()
+	if err != nil {
+		return err
+	}
+
+	// created_seq solo se asigna si la tarea no existía (o fue purgada)
+	task.Seq = seq
+	err = t.tx.QueryRowContext(t.ctx,
+		`INSERT INTO tasks (id, title, description, completed, owner_id, project_id, created_at, updated_at, deleted_at, completed_at, seq, created_seq,
+		   due_date, priority, recurrence, tags)
+		 VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, 0), $7, $8, $9, $10, $11, $11, $12, $13, NULLIF($14, ''), $15)
+		 ON CONFLICT (id) DO UPDATE SET title = EXCLUDED.title, description = EXCLUDED.description,
+		   completed = EXCLUDED.completed, owner_id = EXCLUDED.owner_id, project_id = EXCLUDED.project_id,
+		   created_at = EXCLUDED.created_at, updated_at = EXCLUDED.updated_at, deleted_at = EXCLUDED.deleted_at,
+		   completed_at = EXCLUDED.completed_at, seq = EXCLUDED.seq, due_date = EXCLUDED.due_date,
+		   priority = EXCLUDED.priority, recurrence = EXCLUDED.recurrence, tags = EXCLUDED.tags
+		 RETURNING created_seq`,
+		task.ID, task.Title, task.Description, task.Completed, task.OwnerID, task.ProjectID,
+		task.CreatedAt, task.UpdatedAt, task.DeletedAt, task.CompletedAt, seq,
+		task.DueDate, task.Priority, task.Recurrence, tagsArray(task.Tags),
+	).Scan(&task.CreatedSeq)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.tx.ExecContext(t.ctx, `DELETE FROM task_tombstones WHERE task_id = $1`, task.ID)
+	return err
+}
+
+func (t *postgresTx) Delete(id int) error {
+	seq, err := t.nextSeq()
+	if err != nil {
+		return err
+	}
+
+	var ownerID sql.NullString
+	err = t.tx.QueryRowContext(t.ctx, `DELETE FROM tasks WHERE id = $1 RETURNING owner_id`, id).Scan(&ownerID)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = t.tx.ExecContext(t.ctx,
+		`INSERT INTO task_tombstones (task_id, owner_id, seq, deleted_at) VALUES ($1, $2, $3, NOW())
+		 ON CONFLICT (task_id) DO UPDATE SET owner_id = EXCLUDED.owner_id, seq = EXCLUDED.seq,
+		   deleted_at = EXCLUDED.deleted_at`,
+		id, ownerID, seq)
+	return err
+}
+
+// nextSeq reserva la siguiente secuencia de cambios. La primera llamada toma
+// el advisory lock de la transacción, que se libera con el commit.
+func (t *postgresTx) nextSeq() (int64, error) {
+	if !t.locked {
+		if _, err := t.tx.ExecContext(t.ctx, `SELECT pg_advisory_xact_lock($1)`, seqLockKey); err != nil {
+			return 0, err
+		}
+		t.locked = true
+	}
+
+	var seq int64
+	err := t.tx.QueryRowContext(t.ctx, `SELECT nextval('task_change_seq')`).Scan(&seq)
+	return seq, err
+}
+
+func (t *postgresTx) AddOutbox(event models.TaskEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = t.tx.ExecContext(t.ctx,
+		`INSERT INTO outbox (event_type, event, created_at) VALUES ($1, $2, NOW())`, event.Type, data)
+	return err
+}
+
+// getTask lee una tarea, opcionalmente bloqueando la fila
+func getTask(ctx context.Context, q queryer, id int, forUpdate bool) (models.Task, error) {
+	query := selectTask + ` WHERE id = $1`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	task, err := scanTask(q.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return task, ErrNotFound
+	}
+	return task, err
+}
+
+// scanner es implementado por *sql.Row y *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// extraScanner lee columnas adicionales después de las de la tarea
+type extraScanner struct {
+	scanner
+	extra []interface{}
+}
+
+func (s extraScanner) Scan(dest ...interface{}) error {
+	return s.scanner.Scan(append(dest, s.extra...)...)
+}
+
+// tagsArray convierte las etiquetas en un arreglo de PostgreSQL; sin
+// etiquetas se guarda un arreglo vacío, no NULL
+func tagsArray(tags []string) interface{} {
+	if tags == nil {
+		tags = []string{}
+	}
+	return pq.Array(tags)
+}
+
+// scanTask lee una fila de tasks
+func scanTask(row scanner) (models.Task, error) {
+	var task models.Task
+	var deletedAt, completedAt, dueDate sql.NullTime
+	var tags pq.StringArray
+	err := row.Scan(&task.ID, &task.Title, &task.Description, &task.Completed, &task.OwnerID,
+		&task.ProjectID, &task.CreatedAt, &task.UpdatedAt, &deletedAt, &completedAt, &task.Seq, &task.CreatedSeq,
+		&dueDate, &task.Priority, &task.Recurrence, &tags)
+	if len(tags) > 0 {
+		task.Tags = tags
+	}
+	if deletedAt.Valid {
+		task.DeletedAt = &deletedAt.Time
+	}
+	if completedAt.Valid {
+		task.CompletedAt = &completedAt.Time
+	}
+	if dueDate.Valid {
+		task.DueDate = &dueDate.Time
+	}
+	return task, err
+}
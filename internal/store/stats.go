@@ -0,0 +1,137 @@
+package store
+
+import (
+	"sort"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// BucketStart devuelve el inicio del intervalo que contiene t, en UTC. Las
+// semanas empiezan el lunes, como date_trunc('week') en PostgreSQL.
+func BucketStart(t time.Time, bucket string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if bucket == models.StatsWeekly {
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	}
+	return day
+}
+
+// nextBucket devuelve el inicio del intervalo siguiente
+func nextBucket(start time.Time, bucket string) time.Time {
+	if bucket == models.StatsWeekly {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// emptySeries crea los intervalos del rango de la consulta con contadores en cero
+func emptySeries(query models.StatsQuery) ([]models.StatsBucket, map[time.Time]int) {
+	series := []models.StatsBucket{}
+	index := make(map[time.Time]int)
+	for start := BucketStart(query.From, query.Bucket); start.Before(query.To); start = nextBucket(start, query.Bucket) {
+		index[start] = len(series)
+		series = append(series, models.StatsBucket{Start: start})
+	}
+	return series, index
+}
+
+// finishStats calcula los porcentajes a partir de los contadores
+func finishStats(stats *models.TaskStats, cohortCompleted []int) {
+	stats.Pending = stats.Total - stats.Completed
+	if stats.Total > 0 {
+		stats.CompletionRate = float64(stats.Completed) / float64(stats.Total)
+	}
+	for i := range stats.Series {
+		if stats.Series[i].Created > 0 {
+			stats.Series[i].CompletionRate = float64(cohortCompleted[i]) / float64(stats.Series[i].Created)
+		}
+	}
+}
+
+// computeStats calcula en memoria las mismas estadísticas que las consultas
+// de PostgreSQL
+func computeStats(tasks []models.Task, query models.StatsQuery) models.TaskStats {
+	stats := models.TaskStats{Bucket: query.Bucket, ByProject: []models.ProjectStats{}, ByTag: []models.TagStats{}}
+	series, index := emptySeries(query)
+	stats.Series = series
+	cohortCompleted := make([]int, len(series))
+
+	projects := make(map[int]*models.ProjectStats)
+	tags := make(map[string]*models.TagStats)
+	now := time.Now()
+	var leadTime time.Duration
+	var leadCount int
+	for _, task := range tasks {
+		if task.DeletedAt != nil || !task.VisibleTo(query.UserID) {
+			continue
+		}
+
+		stats.Total++
+		project, ok := projects[task.ProjectID]
+		if !ok {
+			project = &models.ProjectStats{ProjectID: task.ProjectID}
+			projects[task.ProjectID] = project
+		}
+		project.Total++
+		if task.Completed {
+			stats.Completed++
+			project.Completed++
+		} else if task.DueDate != nil && task.DueDate.Before(now) {
+			stats.Overdue++
+		}
+		for _, name := range task.Tags {
+			tag, ok := tags[name]
+			if !ok {
+				tag = &models.TagStats{Tag: name}
+				tags[name] = tag
+			}
+			tag.Total++
+			if task.Completed {
+				tag.Completed++
+			}
+		}
+		if task.Completed && task.CompletedAt != nil {
+			leadTime += task.CompletedAt.Sub(task.CreatedAt)
+			leadCount++
+		}
+
+		if i, ok := index[BucketStart(task.CreatedAt, query.Bucket)]; ok && inRange(task.CreatedAt, query) {
+			stats.Series[i].Created++
+			if task.Completed {
+				cohortCompleted[i]++
+			}
+		}
+		if task.Completed && task.CompletedAt != nil {
+			if i, ok := index[BucketStart(*task.CompletedAt, query.Bucket)]; ok && inRange(*task.CompletedAt, query) {
+				stats.Series[i].Completed++
+			}
+		}
+	}
+
+	for _, project := range projects {
+		stats.ByProject = append(stats.ByProject, *project)
+	}
+	sort.Slice(stats.ByProject, func(i, j int) bool {
+		return stats.ByProject[i].ProjectID < stats.ByProject[j].ProjectID
+	})
+	for _, tag := range tags {
+		stats.ByTag = append(stats.ByTag, *tag)
+	}
+	sort.Slice(stats.ByTag, func(i, j int) bool {
+		return stats.ByTag[i].Tag < stats.ByTag[j].Tag
+	})
+	if leadCount > 0 {
+		hours := leadTime.Hours() / float64(leadCount)
+		stats.AvgLeadTimeHours = &hours
+	}
+
+	finishStats(&stats, cohortCompleted)
+	return stats
+}
+
+// inRange indica si t está en [From, To)
+func inRange(t time.Time, query models.StatsQuery) bool {
+	return !t.Before(query.From) && t.Before(query.To)
+}
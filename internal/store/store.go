@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/claudio/todo-api/internal/filter"
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/claudio/todo-api/internal/search"
+)
+
+// ErrNotFound indica que la tarea no existe
+var ErrNotFound = errors.New("tarea no encontrada")
+
+// TaskStore guarda las tareas. Todas las modificaciones se hacen dentro de
+// Tx para que el cambio y su mensaje de outbox se confirmen juntos.
+type TaskStore interface {
+	// List devuelve todas las tareas, incluidas las de la papelera, ordenadas por ID
+	List(ctx context.Context) ([]models.Task, error)
+	// Find devuelve, ordenadas por ID, las tareas que cumplen la expresión,
+	// incluidas las de la papelera
+	Find(ctx context.Context, expr filter.Expr) ([]models.Task, error)
+	// Get devuelve una tarea por ID, esté o no en la papelera
+	Get(ctx context.Context, id int) (models.Task, error)
+	// Changes devuelve, ordenadas por secuencia, hasta limit tareas y hasta
+	// limit tareas eliminadas definitivamente con secuencia mayor que since
+	Changes(ctx context.Context, since int64, limit int) ([]models.Task, []models.Tombstone, error)
+	// Search busca en el título y la descripción de las tareas que no están
+	// en la papelera y son visibles para userID, de la más a la menos relevante
+	Search(ctx context.Context, query search.Query, userID string, limit int) ([]models.SearchHit, error)
+	// Stats resume las tareas visibles para el usuario que no están en la
+	// papelera y calcula las series del rango de la consulta
+	Stats(ctx context.Context, query models.StatsQuery) (models.TaskStats, error)
+	// Tx ejecuta fn en una transacción; si fn devuelve error no se aplica ningún cambio
+	Tx(ctx context.Context, fn func(tx Tx) error) error
+	// PendingOutbox devuelve en orden los mensajes de outbox aún no publicados
+	PendingOutbox(ctx context.Context, limit int) ([]models.OutboxMessage, error)
+	// MarkPublished marca como publicados los mensajes indicados
+	MarkPublished(ctx context.Context, ids ...int64) error
+}
+
+// Tx son las operaciones disponibles dentro de una transacción. Cada
+// escritura asigna a la tarea la siguiente secuencia de cambios.
+type Tx interface {
+	Get(id int) (models.Task, error)
+	// Insert asigna el ID y la secuencia de la tarea y la guarda
+	Insert(task *models.Task) error
+	// Save crea o reemplaza la tarea y le asigna la secuencia
+	Save(task *models.Task) error
+	// Delete elimina la tarea definitivamente y deja una tombstone
+	Delete(id int) error
+	// AddOutbox guarda un evento para que el relay lo publique después del commit
+	AddOutbox(event models.TaskEvent) error
+}
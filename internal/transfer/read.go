@@ -0,0 +1,199 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// ReadAll lee hasta maxRows filas del archivo. Los errores de una fila se
+// devuelven en Row.Err y la lectura continúa; solo un archivo ilegible en su
+// conjunto, como un CSV sin cabecera, devuelve error.
+func ReadAll(r io.Reader, format string, mapping Mapping, maxRows int) ([]Row, error) {
+	switch format {
+	case JSONLines:
+		return readJSONLines(r, mapping, maxRows)
+	case CSV:
+		return readCSV(r, mapping, maxRows)
+	case TodoTxt:
+		return readTodoTxt(r, maxRows)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// errTooManyRows indica que el archivo supera el máximo de filas
+func errTooManyRows(maxRows int) error {
+	return fmt.Errorf("el archivo supera el máximo de %d filas", maxRows)
+}
+
+// readJSONLines lee un objeto JSON por línea; las líneas vacías se ignoran
+func readJSONLines(r io.Reader, mapping Mapping, maxRows int) ([]Row, error) {
+	rows := []Row{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if len(rows) == maxRows {
+			return nil, errTooManyRows(maxRows)
+		}
+
+		row := Row{Line: line}
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &object); err != nil {
+			row.Err = errors.New("JSON inválido")
+		} else {
+			row.Task, row.Err = taskFromValues(object, mapping)
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
+
+// taskFromValues asigna los valores JSON de un objeto a los campos de la tarea
+func taskFromValues(object map[string]interface{}, mapping Mapping) (models.Task, error) {
+	var task models.Task
+	for key, value := range object {
+		if value == nil {
+			continue
+		}
+		field := mapping.field(key)
+		var ok bool
+		switch field {
+		case "title":
+			task.Title, ok = value.(string)
+		case "description":
+			task.Description, ok = value.(string)
+		case "completed":
+			task.Completed, ok = value.(bool)
+		case "project_id":
+			var n float64
+			n, ok = value.(float64)
+			task.ProjectID = int(n)
+			ok = ok && n == float64(task.ProjectID) && n >= 0
+		case "due_date":
+			var text string
+			if text, ok = value.(string); ok {
+				due, err := parseDueDate(text)
+				if err != nil {
+					return task, err
+				}
+				task.DueDate = &due
+			}
+		case "priority":
+			var n float64
+			n, ok = value.(float64)
+			task.Priority = int(n)
+			ok = ok && n == float64(task.Priority) && n >= 0 && n <= 9
+		case "recurrence":
+			task.Recurrence, ok = value.(string)
+		case "tags":
+			var tags []interface{}
+			tags, ok = value.([]interface{})
+			for _, tag := range tags {
+				text, isString := tag.(string)
+				ok = ok && isString
+				task.Tags = append(task.Tags, text)
+			}
+		default:
+			continue
+		}
+		if !ok {
+			return task, fmt.Errorf("valor inválido para %s", field)
+		}
+	}
+	return task, nil
+}
+
+// readCSV lee una cabecera y una tarea por fila
+func readCSV(r io.Reader, mapping Mapping, maxRows int) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("el CSV está vacío")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, len(header))
+	for i, column := range header {
+		fields[i] = mapping.field(strings.TrimPrefix(column, "\ufeff"))
+	}
+
+	rows := []Row{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if len(rows) == maxRows {
+			return nil, errTooManyRows(maxRows)
+		}
+		var row Row
+		if parseErr, ok := err.(*csv.ParseError); ok {
+			row = Row{Line: parseErr.StartLine, Err: errors.New("fila CSV inválida")}
+		} else if err != nil {
+			return nil, err
+		} else {
+			row.Line, _ = reader.FieldPos(0)
+			row.Task, row.Err = taskFromRecord(fields, record)
+		}
+		rows = append(rows, row)
+	}
+}
+
+// taskFromRecord asigna las celdas de una fila CSV a los campos de la tarea
+func taskFromRecord(fields, record []string) (models.Task, error) {
+	var task models.Task
+	for i, value := range record {
+		if i >= len(fields) || value == "" {
+			continue
+		}
+		switch fields[i] {
+		case "title":
+			task.Title = value
+		case "description":
+			task.Description = value
+		case "completed":
+			completed, err := strconv.ParseBool(strings.ToLower(value))
+			if err != nil {
+				return task, errors.New("completed debe ser true o false")
+			}
+			task.Completed = completed
+		case "project_id":
+			projectID, err := strconv.Atoi(value)
+			if err != nil || projectID < 0 {
+				return task, errors.New("project_id debe ser un número entero")
+			}
+			task.ProjectID = projectID
+		case "due_date":
+			due, err := parseDueDate(value)
+			if err != nil {
+				return task, err
+			}
+			task.DueDate = &due
+		case "priority":
+			priority, err := parsePriority(value)
+			if err != nil {
+				return task, err
+			}
+			task.Priority = priority
+		case "recurrence":
+			task.Recurrence = value
+		case "tags":
+			task.Tags = strings.Split(value, ",")
+		}
+	}
+	return task, nil
+}
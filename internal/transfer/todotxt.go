@@ -0,0 +1,166 @@
+package transfer
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// En todo.txt cada tarea es una línea:
+//
+//	x 2026-10-19 2026-10-01 Comprar pan +project-7
+//
+// "x" y la fecha de finalización marcan las tareas completadas, seguidas de
+// la fecha de creación. El proyecto se escribe como +project-<id>. El
+// formato no tiene descripción, así que no se exporta.
+//
+// La prioridad 1 a 9 se escribe como (A) a (I) al comienzo de las tareas
+// pendientes y como pri:A en las completadas, como recomienda todo.txt. La
+// fecha límite se escribe como due:AAAA-MM-DD, con la hora en RFC3339 si no
+// es medianoche UTC, la recurrencia como rrule:<RRULE> y cada etiqueta como
+// tag:<etiqueta>.
+
+const todoDate = "2006-01-02"
+
+var (
+	// priorityPattern reconoce la prioridad (A) de todo.txt
+	priorityPattern = regexp.MustCompile(`^\(([A-Z])\)$`)
+	// projectPattern reconoce +project-7 y +7
+	projectPattern = regexp.MustCompile(`^\+(?:project-)?(\d+)$`)
+)
+
+// readTodoTxt lee una tarea por línea; las líneas vacías se ignoran
+func readTodoTxt(r io.Reader, maxRows int) ([]Row, error) {
+	rows := []Row{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if len(rows) == maxRows {
+			return nil, errTooManyRows(maxRows)
+		}
+		rows = append(rows, Row{Line: line, Task: parseTodoLine(text)})
+	}
+	return rows, scanner.Err()
+}
+
+// parseTodoLine interpreta una línea de todo.txt. Las palabras que no son
+// marcas de todo.txt, incluidos otros +proyectos y @contextos, forman el
+// título.
+func parseTodoLine(text string) models.Task {
+	var task models.Task
+	words := strings.Fields(text)
+	if len(words) > 0 && words[0] == "x" {
+		task.Completed = true
+		words = words[1:]
+	}
+	if len(words) > 0 {
+		if m := priorityPattern.FindStringSubmatch(words[0]); m != nil {
+			task.Priority = priorityFromLetter(m[1])
+			words = words[1:]
+		}
+	}
+	// Fecha de finalización y de creación en las completadas, solo de
+	// creación en las pendientes
+	dates := 1
+	if task.Completed {
+		dates = 2
+	}
+	for ; dates > 0 && len(words) > 0; dates-- {
+		if _, err := time.Parse(todoDate, words[0]); err != nil {
+			break
+		}
+		words = words[1:]
+	}
+
+	title := []string{}
+	for _, word := range words {
+		if m := projectPattern.FindStringSubmatch(word); m != nil && task.ProjectID == 0 {
+			task.ProjectID, _ = strconv.Atoi(m[1])
+			continue
+		}
+		if key, value, ok := strings.Cut(word, ":"); ok && value != "" {
+			switch key {
+			case "due":
+				if due, err := parseDueDate(value); err == nil {
+					task.DueDate = &due
+					continue
+				}
+			case "pri":
+				if len(value) == 1 && value[0] >= 'A' && value[0] <= 'Z' {
+					task.Priority = priorityFromLetter(value)
+					continue
+				}
+			case "rrule":
+				task.Recurrence = value
+				continue
+			case "tag":
+				task.Tags = append(task.Tags, value)
+				continue
+			}
+		}
+		title = append(title, word)
+	}
+	task.Title = strings.Join(title, " ")
+	return task
+}
+
+// formatTodoLine escribe una tarea como línea de todo.txt
+func formatTodoLine(task models.Task) string {
+	parts := []string{}
+	if task.Completed {
+		parts = append(parts, "x")
+		if task.CompletedAt != nil {
+			parts = append(parts, task.CompletedAt.Format(todoDate))
+		} else {
+			parts = append(parts, task.UpdatedAt.Format(todoDate))
+		}
+	} else if task.Priority != 0 {
+		parts = append(parts, "("+priorityLetter(task.Priority)+")")
+	}
+	parts = append(parts, task.CreatedAt.Format(todoDate))
+	parts = append(parts, strings.Fields(task.Title)...)
+	if task.ProjectID != 0 {
+		parts = append(parts, "+project-"+strconv.Itoa(task.ProjectID))
+	}
+	if task.DueDate != nil {
+		due := task.DueDate.UTC()
+		if due.Equal(due.Truncate(24 * time.Hour)) {
+			parts = append(parts, "due:"+due.Format(todoDate))
+		} else {
+			parts = append(parts, "due:"+due.Format(time.RFC3339))
+		}
+	}
+	if task.Completed && task.Priority != 0 {
+		parts = append(parts, "pri:"+priorityLetter(task.Priority))
+	}
+	if task.Recurrence != "" {
+		parts = append(parts, "rrule:"+task.Recurrence)
+	}
+	for _, tag := range task.Tags {
+		parts = append(parts, "tag:"+tag)
+	}
+	return strings.Join(parts, " ")
+}
+
+// priorityLetter convierte la prioridad 1 a 9 en la letra A a I de todo.txt
+func priorityLetter(priority int) string {
+	return string(rune('A' + priority - 1))
+}
+
+// priorityFromLetter convierte la letra de todo.txt en prioridad. Las letras
+// posteriores a I se importan como la prioridad más baja, 9.
+func priorityFromLetter(letter string) int {
+	priority := int(letter[0]-'A') + 1
+	if priority > 9 {
+		priority = 9
+	}
+	return priority
+}
@@ -0,0 +1,162 @@
+// Package transfer lee y escribe tareas en los formatos de importación y
+// exportación: JSON Lines, CSV y todo.txt.
+package transfer
+
+import (
+	"errors"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// Formatos de importación y exportación
+const (
+	JSONLines = "jsonl"
+	CSV       = "csv"
+	TodoTxt   = "todotxt"
+)
+
+// ErrUnknownFormat indica que el formato no es ninguno de los admitidos
+var ErrUnknownFormat = errors.New("formato desconocido: use jsonl, csv o todotxt")
+
+// contentTypes son los tipos de contenido de cada formato
+var contentTypes = map[string]string{
+	JSONLines: "application/x-ndjson",
+	CSV:       "text/csv; charset=utf-8",
+	TodoTxt:   "text/plain; charset=utf-8",
+}
+
+// extensions son las extensiones de archivo de cada formato
+var extensions = map[string]string{
+	JSONLines: ".jsonl",
+	CSV:       ".csv",
+	TodoTxt:   ".txt",
+}
+
+// ContentType devuelve el tipo de contenido del formato
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// Extension devuelve la extensión de archivo del formato
+func Extension(format string) string {
+	return extensions[format]
+}
+
+// ParseFormat interpreta el nombre de un formato; vacío devuelve fallback
+func ParseFormat(name, fallback string) (string, error) {
+	switch strings.ToLower(name) {
+	case "":
+		return fallback, nil
+	case JSONLines, "ndjson", "json":
+		return JSONLines, nil
+	case CSV:
+		return CSV, nil
+	case TodoTxt, "todo.txt", "txt":
+		return TodoTxt, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// FormatFromMediaType devuelve el formato que corresponde a un tipo de
+// contenido, o "" si no corresponde a ninguno
+func FormatFromMediaType(header string) string {
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return ""
+	}
+	switch mediaType {
+	case "application/x-ndjson", "application/jsonl", "application/json":
+		return JSONLines
+	case "text/csv", "application/csv":
+		return CSV
+	case "text/plain":
+		return TodoTxt
+	}
+	return ""
+}
+
+// Fields son los campos de una tarea que se pueden importar. El resto de
+// columnas (id, fechas, propietario) se ignoran porque las asigna el servidor.
+var Fields = []string{"title", "description", "completed", "project_id", "due_date", "priority", "recurrence", "tags"}
+
+// Mapping renombra columnas del archivo a campos de la tarea, por ejemplo
+// Nombre → title
+type Mapping map[string]string
+
+// ParseMapping interpreta una lista "columna:campo,columna:campo"
+func ParseMapping(value string) (Mapping, error) {
+	mapping := Mapping{}
+	if value == "" {
+		return mapping, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, errors.New("el mapeo debe tener la forma columna:campo,columna:campo")
+		}
+		field := strings.TrimSpace(parts[1])
+		if !isField(field) {
+			return nil, errors.New("campo de destino desconocido: " + field + "; use " + strings.Join(Fields, ", "))
+		}
+		mapping[strings.TrimSpace(parts[0])] = field
+	}
+	return mapping, nil
+}
+
+// field devuelve el campo de la tarea al que corresponde una columna
+func (m Mapping) field(column string) string {
+	if field, ok := m[column]; ok {
+		return field
+	}
+	return strings.ToLower(strings.TrimSpace(column))
+}
+
+func isField(name string) bool {
+	for _, field := range Fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDueDate interpreta una fecha límite RFC3339 o, sin hora, AAAA-MM-DD
+// a medianoche UTC
+func parseDueDate(value string) (time.Time, error) {
+	if due, err := time.Parse(time.RFC3339, value); err == nil {
+		return due, nil
+	}
+	due, err := time.Parse(todoDate, value)
+	if err != nil {
+		return due, errors.New("due_date debe tener formato RFC3339 o AAAA-MM-DD")
+	}
+	return due, nil
+}
+
+// parsePriority interpreta una prioridad de 0 a 9
+func parsePriority(value string) (int, error) {
+	priority, err := strconv.Atoi(value)
+	if err != nil || priority < 0 || priority > 9 {
+		return 0, errors.New("priority debe ser un número entre 0 y 9")
+	}
+	return priority, nil
+}
+
+// Row es una fila leída de un archivo de importación. Line es el número de
+// línea del archivo; Err indica que la fila no se pudo interpretar.
+type Row struct {
+	Line int
+	Task models.Task
+	Err  error
+}
+
+// DuplicateKey identifica las tareas que se consideran la misma al importar:
+// el título sin distinguir mayúsculas ni espacios y el proyecto
+func DuplicateKey(task models.Task) string {
+	title := strings.Join(strings.Fields(strings.ToLower(task.Title)), " ")
+	return title + "\x00" + strconv.Itoa(task.ProjectID)
+}
@@ -0,0 +1,101 @@
+package transfer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+func TestTodoTxtRoundTrip(t *testing.T) {
+	created := time.Date(2026, time.October, 1, 9, 0, 0, 0, time.UTC)
+	done := time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+	task := models.Task{Title: "Comprar pan @casa", Completed: true, ProjectID: 7, CreatedAt: created, CompletedAt: &done}
+
+	var buf bytes.Buffer
+	writer, _ := NewWriter(&buf, TodoTxt, nil)
+	writer.Write(task)
+	writer.Flush()
+	if line := strings.TrimSpace(buf.String()); line != "x 2026-10-19 2026-10-01 Comprar pan @casa +project-7" {
+		t.Errorf("Línea todo.txt incorrecta: %q", line)
+	}
+
+	rows, err := ReadAll(strings.NewReader(buf.String()+"\n(A) 2026-10-02 Llamar +3 +otro\n"), TodoTxt, nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].Task.Title != task.Title || !rows[0].Task.Completed || rows[0].Task.ProjectID != 7 {
+		t.Errorf("Primera fila incorrecta: %+v", rows)
+	}
+	if rows[1].Line != 3 || rows[1].Task.Title != "Llamar +otro" || rows[1].Task.Completed || rows[1].Task.ProjectID != 3 {
+		t.Errorf("Segunda fila incorrecta: %+v", rows[1])
+	}
+}
+
+func TestReadWithMapping(t *testing.T) {
+	mapping, err := ParseMapping("Nombre:title,Hecho:completed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseMapping("Nombre:owner_id"); err == nil {
+		t.Error("Se esperaba un error con un campo de destino desconocido")
+	}
+
+	rows, err := ReadAll(strings.NewReader("Nombre,Hecho,project_id\nUna,true,2\nDos,quizás,\n"), CSV, mapping, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows[0].Err != nil || rows[0].Task.Title != "Una" || !rows[0].Task.Completed || rows[0].Task.ProjectID != 2 {
+		t.Errorf("Fila CSV incorrecta: %+v", rows[0])
+	}
+	if rows[1].Err == nil || rows[1].Line != 3 {
+		t.Errorf("Se esperaba un error en la línea 3: %+v", rows[1])
+	}
+
+	rows, err = ReadAll(strings.NewReader(`{"Nombre": "Tres", "id": 9}`+"\n\n"+`{"title": 4}`+"\nno es json\n"), JSONLines, mapping, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0].Task.Title != "Tres" || rows[1].Err == nil || rows[1].Line != 3 || rows[2].Err == nil {
+		t.Errorf("Filas JSON Lines incorrectas: %+v", rows)
+	}
+
+	if _, err := ReadAll(strings.NewReader("a\nb\nc\n"), TodoTxt, nil, 2); err == nil {
+		t.Error("Se esperaba un error al superar el máximo de filas")
+	}
+}
+
+func TestRoundTripKeepsDuePriorityRecurrenceAndTags(t *testing.T) {
+	created := time.Date(2026, time.October, 1, 9, 0, 0, 0, time.UTC)
+	midnight := time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC)
+	morning := time.Date(2026, time.October, 21, 9, 30, 0, 0, time.UTC)
+	tasks := []models.Task{
+		{Title: "Regar", Priority: 2, DueDate: &midnight, Recurrence: "FREQ=WEEKLY;BYDAY=MO", Tags: []string{"work", "home"}, CreatedAt: created},
+		{Title: "Pagar", Completed: true, Priority: 9, DueDate: &morning, CreatedAt: created, CompletedAt: &morning},
+	}
+
+	for _, format := range []string{JSONLines, CSV, TodoTxt} {
+		var buf bytes.Buffer
+		writer, _ := NewWriter(&buf, format, nil)
+		for _, task := range tasks {
+			writer.Write(task)
+		}
+		writer.Flush()
+
+		rows, err := ReadAll(&buf, format, nil, 10)
+		if err != nil || len(rows) != len(tasks) {
+			t.Fatalf("%s: lectura incorrecta: %+v %v", format, rows, err)
+		}
+		for i, row := range rows {
+			want := tasks[i]
+			got := row.Task
+			if row.Err != nil || got.Priority != want.Priority || got.Recurrence != want.Recurrence ||
+				strings.Join(got.Tags, ",") != strings.Join(want.Tags, ",") ||
+				got.DueDate == nil || !got.DueDate.Equal(*want.DueDate) {
+				t.Errorf("%s: fila %d incorrecta: %+v %v", format, i, got, row.Err)
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// Writer escribe tareas de una en una para poder enviarlas sin esperar a
+// tener todo el archivo
+type Writer interface {
+	Write(task models.Task) error
+	// Flush envía lo escrito hasta ahora
+	Flush() error
+}
+
+// NewWriter crea un Writer del formato indicado. En JSON Lines cada tarea
+// se escribe como el valor que devuelve encode, para usar la representación
+// de la versión de la API; sin encode se escribe models.Task. Las columnas
+// de CSV y las marcas de todo.txt son propias del formato.
+func NewWriter(w io.Writer, format string, encode func(models.Task) interface{}) (Writer, error) {
+	switch format {
+	case JSONLines:
+		return &jsonLinesWriter{encoder: json.NewEncoder(w), encode: encode}, nil
+	case CSV:
+		return &csvWriter{writer: csv.NewWriter(w)}, nil
+	case TodoTxt:
+		return &todoTxtWriter{w: w}, nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+type jsonLinesWriter struct {
+	encoder *json.Encoder
+	encode  func(models.Task) interface{}
+}
+
+func (w *jsonLinesWriter) Write(task models.Task) error {
+	if w.encode != nil {
+		return w.encoder.Encode(w.encode(task))
+	}
+	return w.encoder.Encode(task)
+}
+
+func (w *jsonLinesWriter) Flush() error {
+	return nil
+}
+
+// csvColumns son las columnas de la exportación CSV; las que coinciden con
+// Fields se pueden volver a importar sin mapeo
+var csvColumns = []string{"id", "title", "description", "completed", "project_id", "due_date", "priority", "recurrence", "tags", "created_at", "updated_at", "completed_at"}
+
+type csvWriter struct {
+	writer *csv.Writer
+	header bool
+}
+
+func (w *csvWriter) Write(task models.Task) error {
+	if !w.header {
+		w.header = true
+		if err := w.writer.Write(csvColumns); err != nil {
+			return err
+		}
+	}
+
+	projectID, dueDate, priority, completedAt := "", "", "", ""
+	if task.ProjectID != 0 {
+		projectID = strconv.Itoa(task.ProjectID)
+	}
+	if task.DueDate != nil {
+		dueDate = task.DueDate.UTC().Format(time.RFC3339)
+	}
+	if task.Priority != 0 {
+		priority = strconv.Itoa(task.Priority)
+	}
+	if task.CompletedAt != nil {
+		completedAt = task.CompletedAt.UTC().Format(time.RFC3339)
+	}
+	return w.writer.Write([]string{
+		strconv.Itoa(task.ID),
+		task.Title,
+		task.Description,
+		strconv.FormatBool(task.Completed),
+		projectID,
+		dueDate,
+		priority,
+		task.Recurrence,
+		strings.Join(task.Tags, ","),
+		task.CreatedAt.UTC().Format(time.RFC3339),
+		task.UpdatedAt.UTC().Format(time.RFC3339),
+		completedAt,
+	})
+}
+
+// Flush escribe la cabecera aunque no haya tareas
+func (w *csvWriter) Flush() error {
+	if !w.header {
+		w.header = true
+		w.writer.Write(csvColumns)
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type todoTxtWriter struct {
+	w io.Writer
+}
+
+func (w *todoTxtWriter) Write(task models.Task) error {
+	_, err := io.WriteString(w.w, formatTodoLine(task)+"\n")
+	return err
+}
+
+func (w *todoTxtWriter) Flush() error {
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/claudio/todo-api/internal/ical"
 	"github.com/claudio/todo-api/internal/models"
 )
 
@@ -27,5 +28,18 @@ func ValidateTask(r *http.Request) (*models.Task, error) {
 		return nil, errors.New("el título no puede tener más de 100 caracteres")
 	}
 	
+	// Validar la prioridad y la recurrencia
+	if task.Priority < 0 || task.Priority > 9 {
+		return nil, errors.New("la prioridad debe estar entre 0 y 9")
+	}
+	if task.Recurrence != "" {
+		if task.DueDate == nil {
+			return nil, errors.New("la recurrencia necesita una fecha de vencimiento")
+		}
+		if err := ical.ValidateRecurrence(task.Recurrence); err != nil {
+			return nil, err
+		}
+	}
+	
 	return &task, nil
 }
\ No newline at end of file
@@ -0,0 +1,81 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/claudio/todo-api/internal/filter"
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// groupFields son los campos por los que se puede agrupar una vista
+var groupFields = []string{"completed", "project_id", "owner_id"}
+
+// Validate comprueba el nombre, el orden y la agrupación de la vista. El
+// filtro se valida con filter.Parse en el handler.
+func Validate(view models.View) error {
+	if strings.TrimSpace(view.Name) == "" {
+		return errors.New("El nombre es obligatorio")
+	}
+	if view.Sort != "" {
+		if _, ok := filter.Value(strings.TrimPrefix(view.Sort, "-"), models.Task{}); !ok {
+			return fmt.Errorf("Campo de orden desconocido: %s", view.Sort)
+		}
+	}
+	if view.GroupBy != "" && !isGroupField(view.GroupBy) {
+		return fmt.Errorf("Solo se puede agrupar por %s", strings.Join(groupFields, ", "))
+	}
+	return nil
+}
+
+// Sort ordena las tareas según el campo de la vista; con un "-" delante el
+// orden es descendente. Sin campo se ordenan por ID.
+func Sort(tasks []models.Task, spec string) {
+	descending := strings.HasPrefix(spec, "-")
+	name := strings.TrimPrefix(spec, "-")
+	if name == "" {
+		name = "id"
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, _ := filter.Value(name, tasks[i])
+		b, _ := filter.Value(name, tasks[j])
+		cmp := filter.CompareValues(a, b)
+		if cmp == 0 {
+			return tasks[i].ID < tasks[j].ID
+		}
+		if descending {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+}
+
+// Group agrupa las tareas ya ordenadas por el valor del campo, en el orden
+// en que aparece cada valor por primera vez
+func Group(tasks []models.Task, field string) []models.TaskGroup {
+	groups := []models.TaskGroup{}
+	index := make(map[interface{}]int)
+	for _, task := range tasks {
+		key, _ := filter.Value(field, task)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, models.TaskGroup{Key: key, Tasks: []models.Task{}})
+		}
+		groups[i].Tasks = append(groups[i].Tasks, task)
+	}
+	return groups
+}
+
+func isGroupField(name string) bool {
+	for _, field := range groupFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
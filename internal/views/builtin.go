@@ -0,0 +1,54 @@
+package views
+
+import (
+	"strings"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// builtinPrefix distingue los IDs de las vistas predefinidas
+const builtinPrefix = "builtin:"
+
+// Builtins devuelve las vistas predefinidas. Sus filtros dependen de la
+// fecha, por eso se construyen en cada solicitud a partir de now (en UTC).
+func Builtins(now time.Time) []models.View {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	tomorrow := today.AddDate(0, 0, 1)
+	// La semana empieza el lunes
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	date := func(t time.Time) string { return t.Format("2006-01-02") }
+
+	return []models.View{
+		builtin("today", "Hoy", "NOT completed AND due >= "+date(today)+" AND due < "+date(tomorrow), "due_date"),
+		// Las pendientes que vencen en los próximos siete días, sin contar hoy
+		builtin("upcoming", "Próximas", "NOT completed AND due >= "+date(tomorrow)+" AND due < "+date(tomorrow.AddDate(0, 0, 7)), "due_date"),
+		builtin("overdue", "Vencidas", "NOT completed AND due < "+date(today), "due_date"),
+		builtin("completed-this-week", "Completadas esta semana", "completed AND completed_at >= "+date(weekStart), "-completed_at"),
+	}
+}
+
+// FindBuiltin busca una vista predefinida por su ID
+func FindBuiltin(id string, now time.Time) (models.View, bool) {
+	if !strings.HasPrefix(id, builtinPrefix) {
+		return models.View{}, false
+	}
+	for _, view := range Builtins(now) {
+		if view.ID == id {
+			return view, true
+		}
+	}
+	return models.View{}, false
+}
+
+func builtin(id, name, filter, sort string) models.View {
+	return models.View{
+		ID:         builtinPrefix + id,
+		Name:       name,
+		Filter:     filter,
+		Sort:       sort,
+		SharedWith: []string{},
+		Builtin:    true,
+	}
+}
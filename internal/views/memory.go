@@ -0,0 +1,102 @@
+package views
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// MemoryStore guarda las vistas en memoria
+type MemoryStore struct {
+	mu    sync.RWMutex
+	views map[string]models.View
+}
+
+// NewMemoryStore crea un almacén de vistas en memoria
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{views: make(map[string]models.View)}
+}
+
+// Create guarda una nueva vista
+func (s *MemoryStore) Create(ctx context.Context, view *models.View) error {
+	id, err := randomID()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	view.ID = id
+	view.CreatedAt = now
+	view.UpdatedAt = now
+	s.views[id] = copyView(*view)
+	return nil
+}
+
+// Get devuelve una vista por ID
+func (s *MemoryStore) Get(ctx context.Context, id string) (models.View, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	view, ok := s.views[id]
+	if !ok {
+		return models.View{}, ErrNotFound
+	}
+	return copyView(view), nil
+}
+
+// ListFor devuelve las vistas que el usuario puede usar
+func (s *MemoryStore) ListFor(ctx context.Context, userID string) ([]models.View, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	views := []models.View{}
+	for _, view := range s.views {
+		if view.VisibleTo(userID) {
+			views = append(views, copyView(view))
+		}
+	}
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].Name < views[j].Name
+	})
+	return views, nil
+}
+
+// Update reemplaza los campos editables de la vista
+func (s *MemoryStore) Update(ctx context.Context, view *models.View) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.views[view.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	view.OwnerID = current.OwnerID
+	view.CreatedAt = current.CreatedAt
+	view.UpdatedAt = time.Now()
+	s.views[view.ID] = copyView(*view)
+	return nil
+}
+
+// Delete elimina una vista
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.views[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.views, id)
+	return nil
+}
+
+// copyView copia la vista para no compartir la lista de usuarios
+func copyView(view models.View) models.View {
+	view.SharedWith = append([]string{}, view.SharedWith...)
+	return view
+}
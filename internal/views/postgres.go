@@ -0,0 +1,107 @@
+package views
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/lib/pq"
+)
+
+// PostgresStore guarda las vistas en la tabla views
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore crea un almacén de vistas respaldado por PostgreSQL
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+const selectView = `SELECT id, name, filter, sort, group_by, owner_id, shared_with, created_at, updated_at FROM views`
+
+// Create inserta una nueva vista
+func (s *PostgresStore) Create(ctx context.Context, view *models.View) error {
+	id, err := randomID()
+	if err != nil {
+		return err
+	}
+
+	view.ID = id
+	return s.db.QueryRowContext(ctx,
+		`INSERT INTO views (id, name, filter, sort, group_by, owner_id, shared_with, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		 RETURNING created_at, updated_at`,
+		view.ID, view.Name, view.Filter, view.Sort, view.GroupBy, view.OwnerID, pq.Array(view.SharedWith),
+	).Scan(&view.CreatedAt, &view.UpdatedAt)
+}
+
+// Get devuelve una vista por ID
+func (s *PostgresStore) Get(ctx context.Context, id string) (models.View, error) {
+	view, err := scanView(s.db.QueryRowContext(ctx, selectView+` WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return view, ErrNotFound
+	}
+	return view, err
+}
+
+// ListFor devuelve las vistas propias y compartidas con el usuario
+func (s *PostgresStore) ListFor(ctx context.Context, userID string) ([]models.View, error) {
+	rows, err := s.db.QueryContext(ctx,
+		selectView+` WHERE owner_id = $1 OR $1 = ANY(shared_with) ORDER BY name`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := []models.View{}
+	for rows.Next() {
+		view, err := scanView(rows)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+	return views, rows.Err()
+}
+
+// Update reemplaza los campos editables de la vista
+func (s *PostgresStore) Update(ctx context.Context, view *models.View) error {
+	err := s.db.QueryRowContext(ctx,
+		`UPDATE views SET name = $2, filter = $3, sort = $4, group_by = $5, shared_with = $6, updated_at = NOW()
+		 WHERE id = $1
+		 RETURNING owner_id, created_at, updated_at`,
+		view.ID, view.Name, view.Filter, view.Sort, view.GroupBy, pq.Array(view.SharedWith),
+	).Scan(&view.OwnerID, &view.CreatedAt, &view.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Delete elimina una vista
+func (s *PostgresStore) Delete(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM views WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// scanner es implementado por *sql.Row y *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanView lee una fila de views
+func scanView(row scanner) (models.View, error) {
+	var view models.View
+	var shared pq.StringArray
+	err := row.Scan(&view.ID, &view.Name, &view.Filter, &view.Sort, &view.GroupBy, &view.OwnerID,
+		&shared, &view.CreatedAt, &view.UpdatedAt)
+	view.SharedWith = append([]string{}, shared...)
+	return view, err
+}
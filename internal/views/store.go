@@ -0,0 +1,35 @@
+package views
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// ErrNotFound indica que la vista no existe
+var ErrNotFound = errors.New("vista no encontrada")
+
+// Store guarda las vistas creadas por los usuarios. Las vistas predefinidas
+// no se guardan; se construyen con Builtins.
+type Store interface {
+	// Create asigna ID y fechas a la vista y la guarda
+	Create(ctx context.Context, view *models.View) error
+	Get(ctx context.Context, id string) (models.View, error)
+	// ListFor devuelve las vistas propias y compartidas con el usuario, por nombre
+	ListFor(ctx context.Context, userID string) ([]models.View, error)
+	// Update reemplaza nombre, filtro, orden, agrupación y usuarios compartidos
+	Update(ctx context.Context, view *models.View) error
+	Delete(ctx context.Context, id string) error
+}
+
+// randomID genera un identificador aleatorio para una vista
+func randomID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
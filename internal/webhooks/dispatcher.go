@@ -0,0 +1,297 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// Dispatcher entrega los eventos de tareas a los webhooks suscritos, con
+// reintentos con retroceso exponencial. Recibe los eventos del outbox como
+// un sink más y guarda cada entrega en la cola del almacén; los
+// trabajadores toman de la cola las entregas vencidas, de modo que ni los
+// eventos ni los reintentos pendientes se pierden al reiniciar.
+type Dispatcher struct {
+	store        Store
+	client       *http.Client
+	jobs         chan models.PendingDelivery
+	wake         chan struct{}
+	workers      int
+	pollInterval time.Duration
+	lease        time.Duration
+	maxAttempts  int
+	baseDelay    time.Duration
+	maxDelay     time.Duration
+}
+
+// NewDispatcher crea un Dispatcher con la configuración predeterminada:
+// 4 trabajadores que consultan la cola cada segundo y hasta 6 intentos
+// entre 1 segundo y 5 minutos de espera
+func NewDispatcher(store Store) *Dispatcher {
+	return &Dispatcher{
+		store:        store,
+		client:       newClient(),
+		jobs:         make(chan models.PendingDelivery),
+		wake:         make(chan struct{}, 1),
+		workers:      4,
+		pollInterval: time.Second,
+		// La reserva supera el tiempo de espera del cliente HTTP, para que
+		// una entrega en curso no vuelva a tomarse
+		lease:       time.Minute,
+		maxAttempts: 6,
+		baseDelay:   time.Second,
+		maxDelay:    5 * time.Minute,
+	}
+}
+
+// Start lanza el lector de la cola y los trabajadores de entrega
+func (d *Dispatcher) Start(ctx context.Context) {
+	go d.poll(ctx)
+	for i := 0; i < d.workers; i++ {
+		go d.work(ctx)
+	}
+}
+
+// Publish encola una entrega del mensaje del outbox para cada webhook que lo
+// recibe; implementa outbox.Sink. Si falla, el relay vuelve a publicar el
+// mensaje y las entregas que ya estaban en cola se ignoran por su ID.
+func (d *Dispatcher) Publish(ctx context.Context, msg models.OutboxMessage) error {
+	event := msg.Event
+	event.ID = msg.ID
+
+	hooks, err := d.store.Matching(ctx, event)
+	if err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		err := d.store.EnqueueDelivery(ctx, models.PendingDelivery{
+			DeliveryID: deliveryID(hook.ID, event.ID),
+			WebhookID:  hook.ID,
+			EventID:    event.ID,
+			EventType:  event.Type,
+			Payload:    payload,
+			Attempt:    1,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	d.notify()
+	return nil
+}
+
+// Redeliver vuelve a encolar una entrega de la lista de fallidas
+func (d *Dispatcher) Redeliver(ctx context.Context, letter models.DeadLetter) error {
+	err := d.store.EnqueueDelivery(ctx, models.PendingDelivery{
+		DeliveryID: letter.DeliveryID,
+		WebhookID:  letter.WebhookID,
+		EventID:    letter.EventID,
+		EventType:  letter.EventType,
+		Payload:    letter.Payload,
+		Attempt:    1,
+	})
+	if err != nil {
+		return err
+	}
+	d.notify()
+	return nil
+}
+
+// notify despierta al lector de la cola sin esperar al siguiente intervalo
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// poll reserva las entregas vencidas y las reparte entre los trabajadores.
+// Si la cola tenía más entregas que trabajadores, vuelve a consultarla sin
+// esperar.
+func (d *Dispatcher) poll(ctx context.Context) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		due, err := d.store.ClaimDeliveries(ctx, d.workers, d.lease)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Error al leer la cola de entregas: %v", err)
+		}
+		for _, j := range due {
+			select {
+			case d.jobs <- j:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if len(due) == d.workers {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// work procesa entregas hasta que se cancele el contexto
+func (d *Dispatcher) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case j := <-d.jobs:
+			d.deliver(ctx, j)
+		}
+	}
+}
+
+// deliver realiza un intento de entrega y programa el reintento si falla
+func (d *Dispatcher) deliver(ctx context.Context, j models.PendingDelivery) {
+	hook, err := d.store.Get(ctx, j.WebhookID)
+	if err == ErrNotFound {
+		// El webhook fue eliminado mientras la entrega estaba pendiente
+		d.complete(ctx, j)
+		return
+	}
+	if err != nil {
+		log.Printf("Error al obtener el webhook %s: %v", j.WebhookID, err)
+		if j.Attempt < d.maxAttempts {
+			d.retry(ctx, j)
+		} else {
+			d.complete(ctx, j)
+		}
+		return
+	}
+
+	start := time.Now()
+	statusCode, err := d.send(ctx, hook, j)
+	delivery := models.WebhookDelivery{
+		ID:          j.DeliveryID,
+		WebhookID:   j.WebhookID,
+		EventID:     j.EventID,
+		EventType:   j.EventType,
+		Attempt:     j.Attempt,
+		StatusCode:  statusCode,
+		DurationMS:  time.Since(start).Milliseconds(),
+		AttemptedAt: start,
+	}
+	if err == nil && (statusCode < 200 || statusCode > 299) {
+		err = fmt.Errorf("el receptor respondió %d", statusCode)
+	}
+
+	if err == nil {
+		delivery.Status = models.DeliverySucceeded
+		d.record(ctx, delivery)
+		d.complete(ctx, j)
+		return
+	}
+
+	delivery.Error = err.Error()
+	if j.Attempt >= d.maxAttempts {
+		delivery.Status = models.DeliveryFailed
+		d.record(ctx, delivery)
+		err := d.store.AddDeadLetter(ctx, models.DeadLetter{
+			DeliveryID: j.DeliveryID,
+			WebhookID:  j.WebhookID,
+			EventID:    j.EventID,
+			EventType:  j.EventType,
+			Attempts:   j.Attempt,
+			LastError:  delivery.Error,
+			Payload:    j.Payload,
+			FailedAt:   time.Now(),
+		})
+		if err != nil {
+			// La entrega sigue en cola y se reintenta al vencer la reserva
+			log.Printf("Error al guardar la entrega fallida %s: %v", j.DeliveryID, err)
+			return
+		}
+		d.complete(ctx, j)
+		log.Printf("Webhook %s: entrega %s fallida tras %d intentos", j.WebhookID, j.DeliveryID, j.Attempt)
+		return
+	}
+
+	delivery.Status = models.DeliveryRetrying
+	d.record(ctx, delivery)
+	d.retry(ctx, j)
+}
+
+// retry programa en la cola el siguiente intento de la entrega
+func (d *Dispatcher) retry(ctx context.Context, j models.PendingDelivery) {
+	err := d.store.RescheduleDelivery(ctx, j.DeliveryID, j.Attempt+1, d.backoff(j.Attempt))
+	if err != nil && err != ErrNotFound {
+		log.Printf("Error al programar el reintento de la entrega %s: %v", j.DeliveryID, err)
+	}
+}
+
+// complete quita la entrega de la cola
+func (d *Dispatcher) complete(ctx context.Context, j models.PendingDelivery) {
+	if err := d.store.CompleteDelivery(ctx, j.DeliveryID); err != nil {
+		log.Printf("Error al quitar la entrega %s de la cola: %v", j.DeliveryID, err)
+	}
+}
+
+// record guarda un intento en el registro de entregas
+func (d *Dispatcher) record(ctx context.Context, delivery models.WebhookDelivery) {
+	if err := d.store.RecordDelivery(ctx, delivery); err != nil {
+		log.Printf("Error al registrar la entrega %s: %v", delivery.ID, err)
+	}
+}
+
+// send envía la solicitud firmada y devuelve el código de estado
+func (d *Dispatcher) send(ctx context.Context, hook models.Webhook, j models.PendingDelivery) (int, error) {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", hook.URL, bytes.NewReader(j.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-api-webhooks/1.0")
+	req.Header.Set("X-Webhook-ID", hook.ID)
+	req.Header.Set("X-Webhook-Event", j.EventType)
+	req.Header.Set("X-Webhook-Delivery", j.DeliveryID)
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", Sign(hook.Secret, timestamp, j.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	return resp.StatusCode, nil
+}
+
+// backoff calcula la espera antes del siguiente intento: se duplica en cada
+// intento hasta maxDelay, con una variación aleatoria de ±20%
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.baseDelay << uint(attempt-1)
+	if delay > d.maxDelay || delay <= 0 {
+		delay = d.maxDelay
+	}
+	jitter := time.Duration(rand.Int63n(int64(delay)/5*2+1)) - delay/5
+	return delay + jitter
+}
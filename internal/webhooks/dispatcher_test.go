@@ -0,0 +1,197 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// newTestDispatcher crea un Dispatcher con esperas cortas para las pruebas
+// y sin la protección de direcciones internas, porque el receptor escucha
+// en loopback
+func newTestDispatcher(store Store, maxAttempts int) *Dispatcher {
+	d := NewDispatcher(store)
+	d.client = &http.Client{Timeout: time.Second}
+	d.pollInterval = time.Millisecond
+	d.baseDelay = time.Millisecond
+	d.maxDelay = 5 * time.Millisecond
+	d.maxAttempts = maxAttempts
+	return d
+}
+
+// deliveries devuelve el registro de entregas del webhook
+func deliveries(store Store, webhookID string) []models.WebhookDelivery {
+	deliveries, _ := store.Deliveries(context.Background(), webhookID)
+	return deliveries
+}
+
+// deadLetters devuelve las entregas fallidas del usuario
+func deadLetters(store Store, ownerID string) []models.DeadLetter {
+	letters, _ := store.DeadLetters(context.Background(), ownerID)
+	return letters
+}
+
+// waitFor espera hasta que la condición se cumpla o falla la prueba
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Tiempo de espera agotado")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDispatcherSignsAndRetries(t *testing.T) {
+	var calls, verified int32
+	store := NewMemoryStore()
+	hook, _ := store.Create(context.Background(), models.Webhook{OwnerID: "ana", Events: []string{models.EventTaskCreated}})
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get("X-Webhook-Timestamp"), 10, 64)
+		if Verify(hook.Secret, timestamp, body, r.Header.Get("X-Webhook-Signature")) {
+			atomic.AddInt32(&verified, 1)
+		}
+		// Fallar los dos primeros intentos
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	store.mu.Lock()
+	hook.URL = receiver.URL
+	store.hooks[hook.ID] = hook
+	store.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d := newTestDispatcher(store, 5)
+	d.Start(ctx)
+
+	// Este evento no coincide con la suscripción
+	d.Publish(ctx, models.OutboxMessage{ID: 1, Event: models.TaskEvent{Type: models.EventTaskUpdated, Task: &models.Task{ID: 1}}})
+	d.Publish(ctx, models.OutboxMessage{ID: 2, Event: models.TaskEvent{Type: models.EventTaskCreated, Task: &models.Task{ID: 1}}})
+
+	waitFor(t, func() bool {
+		deliveries := deliveries(store, hook.ID)
+		return len(deliveries) > 0 && deliveries[0].Status == models.DeliverySucceeded
+	})
+
+	if atomic.LoadInt32(&calls) != 3 || atomic.LoadInt32(&verified) != 3 {
+		t.Errorf("Se esperaban 3 entregas firmadas, obtuvo %d (%d verificadas)", calls, verified)
+	}
+	deliveries := deliveries(store, hook.ID)
+	if len(deliveries) != 3 || deliveries[0].Attempt != 3 || deliveries[2].Status != models.DeliveryRetrying {
+		t.Errorf("Registro de entregas incorrecto: %+v", deliveries)
+	}
+}
+
+func TestDispatcherDeadLetter(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+
+	store := NewMemoryStore()
+	hook, _ := store.Create(context.Background(), models.Webhook{URL: receiver.URL, OwnerID: "ana"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d := newTestDispatcher(store, 2)
+	d.Start(ctx)
+
+	d.Publish(ctx, models.OutboxMessage{ID: 1, Event: models.TaskEvent{Type: models.EventTaskDeleted, Task: &models.Task{ID: 1}}})
+
+	waitFor(t, func() bool { return len(deadLetters(store, "ana")) == 1 })
+
+	letter := deadLetters(store, "ana")[0]
+	if letter.WebhookID != hook.ID || letter.Attempts != 2 || len(letter.Payload) == 0 {
+		t.Errorf("Entrega fallida incorrecta: %+v", letter)
+	}
+}
+
+func TestDispatcherResumesQueuedRetries(t *testing.T) {
+	var calls int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	store := NewMemoryStore()
+	hook, _ := store.Create(context.Background(), models.Webhook{URL: receiver.URL, OwnerID: "ana"})
+
+	// Un dispatcher que se detiene antes de entregar deja la entrega en cola
+	stopped := newTestDispatcher(store, 5)
+	msg := models.OutboxMessage{ID: 7, Event: models.TaskEvent{Type: models.EventTaskCreated, Task: &models.Task{ID: 1}}}
+	if err := stopped.Publish(context.Background(), msg); err != nil {
+		t.Fatalf("Error al encolar la entrega: %v", err)
+	}
+	// El outbox puede volver a publicar el mismo mensaje
+	stopped.Publish(context.Background(), msg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	newTestDispatcher(store, 5).Start(ctx)
+
+	waitFor(t, func() bool { return len(deliveries(store, hook.ID)) == 1 })
+	time.Sleep(20 * time.Millisecond)
+
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Se esperaba una sola entrega, obtuvo %d", calls)
+	}
+	if delivery := deliveries(store, hook.ID)[0]; delivery.EventID != 7 || delivery.Status != models.DeliverySucceeded {
+		t.Errorf("Entrega incorrecta: %+v", delivery)
+	}
+}
+
+func TestValidateURLRejectsInternalAddresses(t *testing.T) {
+	ctx := context.Background()
+	for _, raw := range []string{
+		"http://127.0.0.1/hook",
+		"http://10.0.0.5/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]:8080/hook",
+		"http://[fe80::1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		if err := ValidateURL(ctx, raw); err != ErrForbiddenTarget {
+			t.Errorf("%s: se esperaba ErrForbiddenTarget, obtuvo %v", raw, err)
+		}
+	}
+	if err := ValidateURL(ctx, "ftp://example.com/"); err != ErrInvalidURL {
+		t.Errorf("Se esperaba ErrInvalidURL, obtuvo %v", err)
+	}
+	if err := ValidateURL(ctx, "https://93.184.216.34/hook"); err != nil {
+		t.Errorf("Se esperaba una dirección pública válida, obtuvo %v", err)
+	}
+}
+
+func TestClientRefusesLoopbackAtDialTime(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	resp, err := newClient().Get(receiver.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("Se esperaba que la conexión a loopback fuera rechazada")
+	}
+	if !errors.Is(err, ErrForbiddenTarget) {
+		t.Errorf("Se esperaba ErrForbiddenTarget, obtuvo %v", err)
+	}
+}
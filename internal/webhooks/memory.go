@@ -0,0 +1,232 @@
+package webhooks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// MemoryStore guarda en memoria los webhooks, la cola de entregas, el
+// registro de entregas y las entregas fallidas definitivamente
+type MemoryStore struct {
+	mu          sync.RWMutex
+	hooks       map[string]models.Webhook
+	queue       map[string]models.PendingDelivery
+	deliveries  []models.WebhookDelivery
+	deadLetters []models.DeadLetter
+}
+
+// NewMemoryStore crea un almacén de webhooks vacío
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		hooks:       make(map[string]models.Webhook),
+		queue:       make(map[string]models.PendingDelivery),
+		deliveries:  []models.WebhookDelivery{},
+		deadLetters: []models.DeadLetter{},
+	}
+}
+
+// Create guarda un webhook nuevo y genera su ID y, si falta, su secreto
+func (s *MemoryStore) Create(ctx context.Context, hook models.Webhook) (models.Webhook, error) {
+	hook, err := newWebhook(hook)
+	if err != nil {
+		return hook, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hook.CreatedAt = time.Now()
+	s.hooks[hook.ID] = hook
+	return hook, nil
+}
+
+// Get devuelve un webhook por ID
+func (s *MemoryStore) Get(ctx context.Context, id string) (models.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hook, ok := s.hooks[id]
+	if !ok {
+		return hook, ErrNotFound
+	}
+	return hook, nil
+}
+
+// List devuelve los webhooks de un usuario ordenados por fecha de creación
+func (s *MemoryStore) List(ctx context.Context, ownerID string) ([]models.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hooks := []models.Webhook{}
+	for _, hook := range s.hooks {
+		if hook.OwnerID == ownerID {
+			hooks = append(hooks, hook)
+		}
+	}
+	sort.Slice(hooks, func(i, j int) bool {
+		return hooks[i].CreatedAt.Before(hooks[j].CreatedAt)
+	})
+	return hooks, nil
+}
+
+// Matching devuelve los webhooks suscritos al tipo de evento
+func (s *MemoryStore) Matching(ctx context.Context, event models.TaskEvent) ([]models.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hooks := []models.Webhook{}
+	for _, hook := range s.hooks {
+		if event.VisibleTo(hook.OwnerID) && subscribed(hook, event.Type) {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+// Delete elimina un webhook junto con sus entregas pendientes
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.hooks[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.hooks, id)
+	for deliveryID, delivery := range s.queue {
+		if delivery.WebhookID == id {
+			delete(s.queue, deliveryID)
+		}
+	}
+	return nil
+}
+
+// EnqueueDelivery agrega una entrega a la cola salvo que ya esté pendiente
+func (s *MemoryStore) EnqueueDelivery(ctx context.Context, delivery models.PendingDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.queue[delivery.DeliveryID]; !ok {
+		delivery.NextAttemptAt = time.Now()
+		s.queue[delivery.DeliveryID] = delivery
+	}
+	return nil
+}
+
+// ClaimDeliveries reserva las entregas vencidas más antiguas y aplaza su
+// siguiente intento mientras se entregan
+func (s *MemoryStore) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.PendingDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	due := []models.PendingDelivery{}
+	for _, delivery := range s.queue {
+		if !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	for _, delivery := range due {
+		claimed := delivery
+		claimed.NextAttemptAt = now.Add(lease)
+		s.queue[delivery.DeliveryID] = claimed
+	}
+	return due, nil
+}
+
+// RescheduleDelivery programa el siguiente intento de una entrega
+func (s *MemoryStore) RescheduleDelivery(ctx context.Context, deliveryID string, attempt int, delay time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delivery, ok := s.queue[deliveryID]
+	if !ok {
+		return ErrNotFound
+	}
+	delivery.Attempt = attempt
+	delivery.NextAttemptAt = time.Now().Add(delay)
+	s.queue[deliveryID] = delivery
+	return nil
+}
+
+// CompleteDelivery quita una entrega de la cola
+func (s *MemoryStore) CompleteDelivery(ctx context.Context, deliveryID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.queue, deliveryID)
+	return nil
+}
+
+// RecordDelivery agrega un intento al registro de entregas
+func (s *MemoryStore) RecordDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deliveries = append(s.deliveries, delivery)
+	if len(s.deliveries) > maxDeliveryLog {
+		s.deliveries = s.deliveries[len(s.deliveries)-maxDeliveryLog:]
+	}
+	return nil
+}
+
+// Deliveries devuelve los intentos de entrega de un webhook, el más reciente primero
+func (s *MemoryStore) Deliveries(ctx context.Context, webhookID string) ([]models.WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deliveries := []models.WebhookDelivery{}
+	for i := len(s.deliveries) - 1; i >= 0; i-- {
+		if s.deliveries[i].WebhookID == webhookID {
+			deliveries = append(deliveries, s.deliveries[i])
+		}
+	}
+	return deliveries, nil
+}
+
+// AddDeadLetter guarda una entrega que agotó sus reintentos
+func (s *MemoryStore) AddDeadLetter(ctx context.Context, letter models.DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deadLetters = append(s.deadLetters, letter)
+	return nil
+}
+
+// DeadLetters devuelve las entregas fallidas de los webhooks del usuario
+func (s *MemoryStore) DeadLetters(ctx context.Context, ownerID string) ([]models.DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	letters := []models.DeadLetter{}
+	for _, letter := range s.deadLetters {
+		if hook, ok := s.hooks[letter.WebhookID]; ok && hook.OwnerID == ownerID {
+			letters = append(letters, letter)
+		}
+	}
+	return letters, nil
+}
+
+// TakeDeadLetter quita una entrega de la lista de fallidas para reintentarla
+func (s *MemoryStore) TakeDeadLetter(ctx context.Context, deliveryID string) (models.DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, letter := range s.deadLetters {
+		if letter.DeliveryID == deliveryID {
+			s.deadLetters = append(s.deadLetters[:i], s.deadLetters[i+1:]...)
+			return letter, nil
+		}
+	}
+	return models.DeadLetter{}, ErrNotFound
+}
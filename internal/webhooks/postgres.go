@@ -0,0 +1,278 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+	"github.com/lib/pq"
+)
+
+// PostgresStore guarda los webhooks en la tabla webhooks, la cola de
+// entregas en webhook_queue, los intentos en webhook_deliveries y las
+// entregas fallidas en webhook_dead_letters
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore crea un almacén de webhooks respaldado por PostgreSQL
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+const selectWebhook = `SELECT id, url, secret, events, owner_id, created_at FROM webhooks`
+
+// Create inserta un webhook nuevo
+func (s *PostgresStore) Create(ctx context.Context, hook models.Webhook) (models.Webhook, error) {
+	hook, err := newWebhook(hook)
+	if err != nil {
+		return hook, err
+	}
+	if hook.Events == nil {
+		hook.Events = []string{}
+	}
+
+	err = s.db.QueryRowContext(ctx,
+		`INSERT INTO webhooks (id, url, secret, events, owner_id, created_at)
+		 VALUES ($1, $2, $3, $4, $5, NOW())
+		 RETURNING created_at`,
+		hook.ID, hook.URL, hook.Secret, pq.Array(hook.Events), hook.OwnerID,
+	).Scan(&hook.CreatedAt)
+	return hook, err
+}
+
+// Get devuelve un webhook por ID
+func (s *PostgresStore) Get(ctx context.Context, id string) (models.Webhook, error) {
+	hook, err := scanWebhook(s.db.QueryRowContext(ctx, selectWebhook+` WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return hook, ErrNotFound
+	}
+	return hook, err
+}
+
+// List devuelve los webhooks de un usuario ordenados por fecha de creación
+func (s *PostgresStore) List(ctx context.Context, ownerID string) ([]models.Webhook, error) {
+	return s.query(ctx, selectWebhook+` WHERE owner_id = $1 ORDER BY created_at`, ownerID)
+}
+
+// Matching devuelve los webhooks suscritos al tipo de evento. La
+// visibilidad se comprueba después porque depende de la tarea del evento.
+func (s *PostgresStore) Matching(ctx context.Context, event models.TaskEvent) ([]models.Webhook, error) {
+	candidates, err := s.query(ctx,
+		selectWebhook+` WHERE cardinality(events) = 0 OR '*' = ANY(events) OR $1 = ANY(events)`, event.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	hooks := []models.Webhook{}
+	for _, hook := range candidates {
+		if event.VisibleTo(hook.OwnerID) {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+// Delete elimina un webhook; las entregas y la cola se eliminan en cascada
+func (s *PostgresStore) Delete(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// EnqueueDelivery inserta una entrega en la cola salvo que ya esté pendiente
+func (s *PostgresStore) EnqueueDelivery(ctx context.Context, delivery models.PendingDelivery) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO webhook_queue
+		 (delivery_id, webhook_id, event_id, event_type, attempt, payload, next_attempt_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		 ON CONFLICT (delivery_id) DO NOTHING`,
+		delivery.DeliveryID, delivery.WebhookID, delivery.EventID, delivery.EventType,
+		delivery.Attempt, []byte(delivery.Payload))
+	return err
+}
+
+// ClaimDeliveries reserva las entregas vencidas más antiguas y aplaza su
+// siguiente intento. SKIP LOCKED permite que varias instancias consulten la
+// cola a la vez sin tomar la misma entrega.
+func (s *PostgresStore) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.PendingDelivery, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`WITH due AS (
+		     SELECT delivery_id FROM webhook_queue
+		     WHERE next_attempt_at <= NOW()
+		     ORDER BY next_attempt_at
+		     LIMIT $1
+		     FOR UPDATE SKIP LOCKED
+		 )
+		 UPDATE webhook_queue q SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+		 FROM due WHERE q.delivery_id = due.delivery_id
+		 RETURNING q.delivery_id, q.webhook_id, q.event_id, q.event_type, q.attempt, q.payload, q.next_attempt_at`,
+		limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.PendingDelivery{}
+	for rows.Next() {
+		var d models.PendingDelivery
+		var payload []byte
+		if err := rows.Scan(&d.DeliveryID, &d.WebhookID, &d.EventID, &d.EventType,
+			&d.Attempt, &payload, &d.NextAttemptAt); err != nil {
+			return nil, err
+		}
+		d.Payload = payload
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// RescheduleDelivery programa el siguiente intento de una entrega
+func (s *PostgresStore) RescheduleDelivery(ctx context.Context, deliveryID string, attempt int, delay time.Duration) error {
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE webhook_queue SET attempt = $2, next_attempt_at = NOW() + $3 * INTERVAL '1 second'
+		 WHERE delivery_id = $1`,
+		deliveryID, attempt, delay.Seconds())
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CompleteDelivery quita una entrega de la cola
+func (s *PostgresStore) CompleteDelivery(ctx context.Context, deliveryID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM webhook_queue WHERE delivery_id = $1`, deliveryID)
+	return err
+}
+
+// RecordDelivery inserta un intento en el registro de entregas
+func (s *PostgresStore) RecordDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries
+		 (delivery_id, webhook_id, event_id, event_type, attempt, status, status_code, error, duration_ms, attempted_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		delivery.ID, delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Attempt,
+		delivery.Status, delivery.StatusCode, delivery.Error, delivery.DurationMS, delivery.AttemptedAt)
+	return err
+}
+
+// Deliveries devuelve los últimos intentos de entrega de un webhook, el más
+// reciente primero
+func (s *PostgresStore) Deliveries(ctx context.Context, webhookID string) ([]models.WebhookDelivery, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT delivery_id, webhook_id, event_id, event_type, attempt, status, status_code, error, duration_ms, attempted_at
+		 FROM webhook_deliveries WHERE webhook_id = $1 ORDER BY id DESC LIMIT $2`, webhookID, maxDeliveryLog)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Attempt, &d.Status,
+			&d.StatusCode, &d.Error, &d.DurationMS, &d.AttemptedAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// AddDeadLetter guarda una entrega que agotó sus reintentos
+func (s *PostgresStore) AddDeadLetter(ctx context.Context, letter models.DeadLetter) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO webhook_dead_letters
+		 (delivery_id, webhook_id, event_id, event_type, attempts, last_error, payload, failed_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 ON CONFLICT (delivery_id) DO UPDATE SET attempts = EXCLUDED.attempts,
+		     last_error = EXCLUDED.last_error, failed_at = EXCLUDED.failed_at`,
+		letter.DeliveryID, letter.WebhookID, letter.EventID, letter.EventType, letter.Attempts,
+		letter.LastError, []byte(letter.Payload), letter.FailedAt)
+	return err
+}
+
+// DeadLetters devuelve las entregas fallidas de los webhooks del usuario
+func (s *PostgresStore) DeadLetters(ctx context.Context, ownerID string) ([]models.DeadLetter, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT l.delivery_id, l.webhook_id, l.event_id, l.event_type, l.attempts, l.last_error, l.payload, l.failed_at
+		 FROM webhook_dead_letters l JOIN webhooks w ON w.id = l.webhook_id
+		 WHERE w.owner_id = $1 ORDER BY l.failed_at`, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	letters := []models.DeadLetter{}
+	for rows.Next() {
+		letter, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+	return letters, rows.Err()
+}
+
+// TakeDeadLetter elimina la entrega fallida y la devuelve para reintentarla
+func (s *PostgresStore) TakeDeadLetter(ctx context.Context, deliveryID string) (models.DeadLetter, error) {
+	letter, err := scanDeadLetter(s.db.QueryRowContext(ctx,
+		`DELETE FROM webhook_dead_letters WHERE delivery_id = $1
+		 RETURNING delivery_id, webhook_id, event_id, event_type, attempts, last_error, payload, failed_at`, deliveryID))
+	if err == sql.ErrNoRows {
+		return letter, ErrNotFound
+	}
+	return letter, err
+}
+
+// query ejecuta una consulta de webhooks
+func (s *PostgresStore) query(ctx context.Context, query string, args ...interface{}) ([]models.Webhook, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hooks := []models.Webhook{}
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, rows.Err()
+}
+
+// scanner es implementado por *sql.Row y *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanWebhook lee una fila de webhooks
+func scanWebhook(row scanner) (models.Webhook, error) {
+	var hook models.Webhook
+	var events pq.StringArray
+	err := row.Scan(&hook.ID, &hook.URL, &hook.Secret, &events, &hook.OwnerID, &hook.CreatedAt)
+	hook.Events = append([]string{}, events...)
+	return hook, err
+}
+
+// scanDeadLetter lee una fila de webhook_dead_letters
+func scanDeadLetter(row scanner) (models.DeadLetter, error) {
+	var letter models.DeadLetter
+	var payload []byte
+	err := row.Scan(&letter.DeliveryID, &letter.WebhookID, &letter.EventID, &letter.EventType,
+		&letter.Attempts, &letter.LastError, &payload, &letter.FailedAt)
+	letter.Payload = payload
+	return letter, err
+}
@@ -0,0 +1,105 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/claudio/todo-api/internal/models"
+)
+
+// maxDeliveryLog es la cantidad de entregas que se conservan en el registro
+const maxDeliveryLog = 5000
+
+// ErrNotFound indica que el webhook o la entrega no existe
+var ErrNotFound = errors.New("webhook no encontrado")
+
+// Store guarda los webhooks, la cola de entregas pendientes, el registro de
+// entregas y las entregas fallidas definitivamente
+type Store interface {
+	// Create guarda un webhook nuevo y genera su ID y, si falta, su secreto
+	Create(ctx context.Context, hook models.Webhook) (models.Webhook, error)
+	Get(ctx context.Context, id string) (models.Webhook, error)
+	// List devuelve los webhooks de un usuario ordenados por fecha de creación
+	List(ctx context.Context, ownerID string) ([]models.Webhook, error)
+	// Matching devuelve los webhooks que reciben el evento
+	Matching(ctx context.Context, event models.TaskEvent) ([]models.Webhook, error)
+	// Delete elimina el webhook junto con sus entregas
+	Delete(ctx context.Context, id string) error
+
+	// EnqueueDelivery agrega una entrega a la cola para intentarla de
+	// inmediato; si ya hay una entrega pendiente con el mismo ID no hace nada
+	EnqueueDelivery(ctx context.Context, delivery models.PendingDelivery) error
+	// ClaimDeliveries reserva hasta limit entregas cuyo siguiente intento ya
+	// venció, en orden, y aplaza ese intento lease para que ningún otro
+	// trabajador las tome mientras se entregan. Si el proceso termina a
+	// mitad de la entrega, se reintenta al vencer la reserva.
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.PendingDelivery, error)
+	// RescheduleDelivery programa el siguiente intento de una entrega dentro
+	// de delay
+	RescheduleDelivery(ctx context.Context, deliveryID string, attempt int, delay time.Duration) error
+	// CompleteDelivery quita una entrega de la cola
+	CompleteDelivery(ctx context.Context, deliveryID string) error
+
+	// RecordDelivery agrega un intento al registro de entregas
+	RecordDelivery(ctx context.Context, delivery models.WebhookDelivery) error
+	// Deliveries devuelve los intentos de entrega de un webhook, el más reciente primero
+	Deliveries(ctx context.Context, webhookID string) ([]models.WebhookDelivery, error)
+	// AddDeadLetter guarda una entrega que agotó sus reintentos
+	AddDeadLetter(ctx context.Context, letter models.DeadLetter) error
+	// DeadLetters devuelve las entregas fallidas de los webhooks del usuario
+	DeadLetters(ctx context.Context, ownerID string) ([]models.DeadLetter, error)
+	// TakeDeadLetter quita una entrega de la lista de fallidas para reintentarla
+	TakeDeadLetter(ctx context.Context, deliveryID string) (models.DeadLetter, error)
+}
+
+// subscribed indica si el webhook recibe el tipo de evento. Una lista vacía
+// o "*" suscribe a todos los eventos.
+func subscribed(hook models.Webhook, eventType string) bool {
+	if len(hook.Events) == 0 {
+		return true
+	}
+	for _, e := range hook.Events {
+		if e == "*" || e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// deliveryID deriva el ID de la entrega del webhook y del evento, para que
+// un evento que el outbox publique dos veces no genere dos entregas
+// distintas: mientras esté en cola se ignora y, si ya se entregó, el
+// receptor lo reconoce por X-Webhook-Delivery
+func deliveryID(webhookID string, eventID int64) string {
+	sum := sha256.Sum256([]byte(webhookID + ":" + strconv.FormatInt(eventID, 10)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// newWebhook completa el ID y, si falta, el secreto de un webhook nuevo
+func newWebhook(hook models.Webhook) (models.Webhook, error) {
+	id, err := randomID(8)
+	if err != nil {
+		return hook, err
+	}
+	if hook.Secret == "" {
+		if hook.Secret, err = randomID(32); err != nil {
+			return hook, err
+		}
+	}
+	hook.ID = id
+	return hook, nil
+}
+
+// randomID genera un identificador aleatorio en hexadecimal
+func randomID(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
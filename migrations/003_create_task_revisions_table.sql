@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS task_revisions (
+    task_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    snapshot JSONB NOT NULL,
+    changes JSONB NOT NULL DEFAULT '[]',
+    actor VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (task_id, revision)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_revisions_created_at ON task_revisions (task_id, created_at);
@@ -0,0 +1,5 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS owner_id VARCHAR(255);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS project_id INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_owner_id ON tasks (owner_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_project_id ON tasks (project_id);
@@ -0,0 +1,18 @@
+-- Secuencia global de cambios usada por la sincronización incremental
+CREATE SEQUENCE IF NOT EXISTS task_change_seq;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS seq BIGINT NOT NULL DEFAULT nextval('task_change_seq');
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS created_seq BIGINT;
+UPDATE tasks SET created_seq = seq WHERE created_seq IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_seq ON tasks (seq);
+
+-- Tareas eliminadas definitivamente, para que los clientes borren su copia
+CREATE TABLE IF NOT EXISTS task_tombstones (
+    task_id INTEGER PRIMARY KEY,
+    owner_id VARCHAR(255),
+    seq BIGINT NOT NULL,
+    deleted_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_tombstones_seq ON task_tombstones (seq);
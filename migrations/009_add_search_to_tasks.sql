@@ -0,0 +1,8 @@
+-- Búsqueda de texto completo: el título pesa más que la descripción
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('spanish', COALESCE(title, '')), 'A') ||
+        setweight(to_tsvector('spanish', COALESCE(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_search ON tasks USING GIN (search);
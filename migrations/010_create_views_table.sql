@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS views (
+    id VARCHAR(32) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    filter TEXT NOT NULL DEFAULT '',
+    sort VARCHAR(50) NOT NULL DEFAULT '',
+    group_by VARCHAR(50) NOT NULL DEFAULT '',
+    owner_id VARCHAR(255) NOT NULL,
+    shared_with TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_views_owner_id ON views (owner_id);
+CREATE INDEX IF NOT EXISTS idx_views_shared_with ON views USING GIN (shared_with);
@@ -0,0 +1,6 @@
+-- Momento en que cada tarea se completó, usado por las estadísticas
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP;
+UPDATE tasks SET completed_at = updated_at WHERE completed AND completed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_completed_at ON tasks (completed_at) WHERE completed_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_created_at ON tasks (created_at);
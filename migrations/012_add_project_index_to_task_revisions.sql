@@ -0,0 +1,3 @@
+-- Los informes por proyecto buscan las revisiones por el project_id guardado
+CREATE INDEX IF NOT EXISTS idx_task_revisions_project_id
+    ON task_revisions (((snapshot->>'project_id')::INTEGER), created_at);
@@ -0,0 +1,9 @@
+-- Nombre y UID de los recursos creados por clientes CalDAV; las tareas sin
+-- fila se publican como {id}.ics
+CREATE TABLE IF NOT EXISTS caldav_objects (
+    task_id INTEGER PRIMARY KEY REFERENCES tasks (id) ON DELETE CASCADE,
+    owner_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    uid VARCHAR(255) NOT NULL,
+    UNIQUE (owner_id, name)
+);
@@ -0,0 +1,7 @@
+-- Fecha de vencimiento, prioridad (1 la más alta, 9 la más baja, 0 sin
+-- prioridad) y regla de recurrencia RRULE de cada tarea
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_date TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0 CHECK (priority BETWEEN 0 AND 9);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS recurrence TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_due_date ON tasks (due_date) WHERE due_date IS NOT NULL;
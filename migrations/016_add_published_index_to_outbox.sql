@@ -0,0 +1,2 @@
+-- La depuración del outbox elimina los mensajes publicados más antiguos
+CREATE INDEX IF NOT EXISTS idx_outbox_published ON outbox (published_at) WHERE published_at IS NOT NULL;
@@ -0,0 +1,4 @@
+-- Etiquetas de cada tarea, en minúsculas; el filtro tag:trabajo usa el índice
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_tasks_tags ON tasks USING GIN (tags);
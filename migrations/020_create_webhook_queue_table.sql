@@ -0,0 +1,13 @@
+-- Entregas pendientes: los trabajadores toman las filas cuyo siguiente
+-- intento ya venció, de modo que los reintentos sobreviven a los reinicios
+CREATE TABLE IF NOT EXISTS webhook_queue (
+    delivery_id VARCHAR(32) PRIMARY KEY,
+    webhook_id VARCHAR(32) NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    attempt INTEGER NOT NULL,
+    payload JSONB NOT NULL,
+    next_attempt_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_queue_next_attempt_at ON webhook_queue (next_attempt_at);
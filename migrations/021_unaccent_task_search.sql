@@ -0,0 +1,25 @@
+-- La búsqueda ignora los acentos igual que el índice en memoria. unaccent()
+-- no es IMMUTABLE y no puede usarse en una columna generada, por lo que se
+-- crea una configuración que aplica unaccent antes del stemming en español.
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'spanish_unaccent') THEN
+        CREATE TEXT SEARCH CONFIGURATION spanish_unaccent (COPY = spanish);
+        ALTER TEXT SEARCH CONFIGURATION spanish_unaccent
+            ALTER MAPPING FOR hword, hword_part, word WITH unaccent, spanish_stem;
+    END IF;
+END
+$$;
+
+-- Una columna generada no admite cambiar su expresión: se vuelve a crear
+DROP INDEX IF EXISTS idx_tasks_search;
+ALTER TABLE tasks DROP COLUMN IF EXISTS search;
+ALTER TABLE tasks ADD COLUMN search tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('spanish_unaccent', COALESCE(title, '')), 'A') ||
+        setweight(to_tsvector('spanish_unaccent', COALESCE(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_search ON tasks USING GIN (search);